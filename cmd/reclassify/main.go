@@ -0,0 +1,167 @@
+// reclassify 按当前规则重新计算历史提交的派生字段
+//
+// 用法:
+//
+//	go run ./cmd/reclassify -messages -files -languages -categories -duplicates -reverts
+//	go run ./cmd/reclassify -parents /path/to/clone
+//
+// -messages: 重新解析提交说明（Conventional Commits 类型、scope、破坏性变更、trailer、issue / 工单引用、合并提交标记和 Co-authored-by 共同作者），并重建聚合表
+// -files: 按当前规则重新分类变更文件（生成代码、第三方代码、锁文件），重新计算行数总计并重建聚合表
+// -languages: 按当前语言映射（含 LANGUAGE_MAP_FILE）重新检测变更文件的语言，重新计算语言统计并重建聚合表
+// -categories: 按当前规则（含 FILE_CATEGORY_FILE）重新判断变更文件的类别（测试 / 生产 / 文档 / 配置 / 构建）
+// -duplicates: 按 SHA、cherry-pick 来源和补丁标识重新计算等价提交分组（乱序导入后需要执行），并重建聚合表
+// -parents: 从本地仓库克隆读取父提交 SHA（webhook 负载不包含父提交），更新合并提交标记并重建聚合表
+// -reverts: 按回滚声明（Revert "..." / This reverts commit <sha>）重新关联回滚提交和被回滚提交（乱序导入或 -messages 后需要执行）
+package main
+
+import (
+	"flag"
+	"log"
+
+	"gitlab-webhook-server/internal/config"
+	"gitlab-webhook-server/internal/database"
+	"gitlab-webhook-server/internal/logger"
+	"gitlab-webhook-server/internal/service/commit"
+	"gitlab-webhook-server/internal/service/rollup"
+	"gitlab-webhook-server/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	messages := flag.Bool("messages", false, "重新解析提交说明")
+	files := flag.Bool("files", false, "重新分类变更文件并重建聚合表")
+	languages := flag.Bool("languages", false, "重新检测变更文件语言并重建聚合表")
+	categories := flag.Bool("categories", false, "重新判断变更文件类别")
+	duplicates := flag.Bool("duplicates", false, "重新计算等价提交分组并重建聚合表")
+	reverts := flag.Bool("reverts", false, "重新关联回滚提交")
+	parents := flag.String("parents", "", "从指定的本地仓库读取父提交并重建聚合表")
+	batchSize := flag.Int("batch", 500, "每批处理的提交数")
+	flag.Parse()
+
+	if !*messages && !*files && !*languages && !*categories && !*duplicates && !*reverts && *parents == "" {
+		log.Fatal("请至少指定一项需要重新计算的内容，如 -messages、-files、-languages、-categories、-duplicates、-reverts 或 -parents")
+	}
+
+	// 加载配置
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	// 初始化日志
+	zapLogger, err := logger.New(cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("初始化日志失败: %v", err)
+	}
+	defer func() {
+		_ = zapLogger.Sync()
+	}()
+
+	// 设置统计时区
+	loc, err := cfg.GetLocation()
+	if err != nil {
+		zapLogger.Fatal("加载统计时区失败", zap.Error(err))
+	}
+	utils.SetDefaultLocation(loc)
+
+	// 共同作者行数归属方式（影响聚合表，修改后需重建聚合）
+	if err := utils.SetCreditMode(cfg.CreditMode); err != nil {
+		zapLogger.Fatal("CREDIT_MODE 配置无效", zap.Error(err))
+	}
+
+	// 外部工单号（如 JIRA）匹配规则
+	if err := utils.SetIssueKeyPatterns(cfg.IssueKeyPatterns); err != nil {
+		zapLogger.Fatal("加载工单号匹配规则失败", zap.Error(err))
+	}
+
+	// 语言映射覆盖配置
+	if cfg.LanguageMapFile != "" {
+		mappings, err := utils.LoadLanguageMappings(cfg.LanguageMapFile)
+		if err != nil {
+			zapLogger.Fatal("加载语言映射配置失败", zap.Error(err))
+		}
+		utils.SetLanguageMappings(mappings)
+	}
+
+	// 文件类别规则
+	if cfg.FileCategoryFile != "" {
+		rules, err := utils.LoadFileCategoryRules(cfg.FileCategoryFile)
+		if err != nil {
+			zapLogger.Fatal("加载文件类别规则失败", zap.Error(err))
+		}
+		if err := utils.SetFileCategoryRules(rules); err != nil {
+			zapLogger.Fatal("文件类别规则无效", zap.Error(err))
+		}
+	}
+
+	// 初始化数据库
+	if err := database.Init(cfg, zapLogger); err != nil {
+		zapLogger.Fatal("数据库初始化失败", zap.Error(err))
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+
+	if err := database.Migrate(); err != nil {
+		zapLogger.Fatal("数据库迁移失败", zap.Error(err))
+	}
+
+	commitService := commit.NewCommitServiceV2(database.DB, zapLogger)
+
+	if *messages {
+		count, err := commitService.ReparseMessages(*batchSize)
+		if err != nil {
+			zapLogger.Fatal("重新解析提交说明失败", zap.Error(err))
+		}
+		zapLogger.Info("✅ 提交说明重新解析完成", zap.Int("commits", count))
+	}
+
+	// 在 -messages 之后执行，父提交已知时合并提交标记以父提交数量为准
+	if *parents != "" {
+		count, err := commitService.ImportGitParents(*parents, *batchSize)
+		if err != nil {
+			zapLogger.Fatal("从本地仓库更新父提交失败", zap.Error(err))
+		}
+		zapLogger.Info("✅ 父提交更新完成", zap.Int("updated", count))
+	}
+
+	if *files || *languages || *categories {
+		count, err := commitService.RecomputeFiles(*batchSize, commit.RecomputeFilesOptions{
+			Classification: *files,
+			Languages:      *languages,
+			Categories:     *categories,
+		})
+		if err != nil {
+			zapLogger.Fatal("重新计算变更文件失败", zap.Error(err))
+		}
+		zapLogger.Info("✅ 变更文件重新计算完成", zap.Int("commits", count))
+	}
+
+	// cherry-pick 来源可能已由 -messages 更新，在其之后重新分组
+	if *duplicates {
+		count, err := commitService.RegroupCommits(*batchSize)
+		if err != nil {
+			zapLogger.Fatal("重新计算等价提交分组失败", zap.Error(err))
+		}
+		zapLogger.Info("✅ 等价提交重新分组完成", zap.Int("updated", count))
+	}
+
+	// 回滚声明可能已由 -messages 更新，在其之后重新关联（回滚关系不参与聚合）
+	if *reverts {
+		count, err := commitService.LinkReverts(*batchSize)
+		if err != nil {
+			zapLogger.Fatal("重新关联回滚提交失败", zap.Error(err))
+		}
+		zapLogger.Info("✅ 回滚提交重新关联完成", zap.Int("updated", count))
+	}
+
+	// 合并提交标记、行数总计、语言统计和等价分组已变化，重建聚合表并重新标记为就绪（文件类别不参与聚合）
+	if *messages || *files || *languages || *duplicates || *parents != "" {
+		result, err := rollup.NewEngine(database.DB, zapLogger).RebuildAll()
+		if err != nil {
+			zapLogger.Fatal("重建聚合统计失败", zap.Error(err))
+		}
+		zapLogger.Info("✅ 聚合统计重建完成", zap.Int("commits", result.Commits))
+	}
+}
@@ -0,0 +1,99 @@
+// rollup 聚合数据回填/重建工具
+//
+// 用法:
+//
+//	go run ./cmd/rollup -all
+//	go run ./cmd/rollup -from 2024-01-01 -to 2024-06-30
+//
+// 日期按 DB_TIMEZONE 解释，-to 当天包含在内；区间会扩展到完整的周和月
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"gitlab-webhook-server/internal/config"
+	"gitlab-webhook-server/internal/database"
+	"gitlab-webhook-server/internal/logger"
+	"gitlab-webhook-server/internal/service/rollup"
+	"gitlab-webhook-server/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	fromStr := flag.String("from", "", "起始日期（YYYY-MM-DD）")
+	toStr := flag.String("to", "", "结束日期（YYYY-MM-DD，包含当天）")
+	all := flag.Bool("all", false, "重建全部历史数据")
+	flag.Parse()
+
+	if !*all && (*fromStr == "" || *toStr == "") {
+		log.Fatal("请指定 -all 或同时指定 -from 和 -to")
+	}
+
+	// 加载配置
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	// 初始化日志
+	zapLogger, err := logger.New(cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("初始化日志失败: %v", err)
+	}
+	defer func() {
+		_ = zapLogger.Sync()
+	}()
+
+	// 设置统计时区
+	loc, err := cfg.GetLocation()
+	if err != nil {
+		zapLogger.Fatal("加载统计时区失败", zap.Error(err))
+	}
+	utils.SetDefaultLocation(loc)
+
+	// 共同作者行数归属方式（影响聚合表，修改后需重建聚合）
+	if err := utils.SetCreditMode(cfg.CreditMode); err != nil {
+		zapLogger.Fatal("CREDIT_MODE 配置无效", zap.Error(err))
+	}
+
+	// 初始化数据库
+	if err := database.Init(cfg, zapLogger); err != nil {
+		zapLogger.Fatal("数据库初始化失败", zap.Error(err))
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+
+	if err := database.Migrate(); err != nil {
+		zapLogger.Fatal("数据库迁移失败", zap.Error(err))
+	}
+
+	engine := rollup.NewEngine(database.DB, zapLogger)
+
+	var result *rollup.RebuildResult
+	if *all {
+		result, err = engine.RebuildAll()
+	} else {
+		from, perr := time.ParseInLocation("2006-01-02", *fromStr, loc)
+		if perr != nil {
+			zapLogger.Fatal("解析 -from 失败", zap.Error(perr))
+		}
+		to, perr := time.ParseInLocation("2006-01-02", *toStr, loc)
+		if perr != nil {
+			zapLogger.Fatal("解析 -to 失败", zap.Error(perr))
+		}
+		result, err = engine.Rebuild(from, to.AddDate(0, 0, 1))
+	}
+	if err != nil {
+		zapLogger.Fatal("重建聚合数据失败", zap.Error(err))
+	}
+
+	zapLogger.Info("✅ 聚合数据重建完成",
+		zap.Int("commits", result.Commits),
+		zap.Int("contributions", result.Contributions),
+		zap.Int("language_stats", result.LanguageStats),
+	)
+}
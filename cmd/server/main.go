@@ -14,6 +14,7 @@ import (
 	"gitlab-webhook-server/internal/queue"
 	"gitlab-webhook-server/internal/router"
 	"gitlab-webhook-server/internal/service/commit"
+	"gitlab-webhook-server/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -38,6 +39,43 @@ func main() {
 		}
 	}(zapLogger)
 
+	// 设置统计时区（聚合周期与日期过滤均按此时区计算）
+	loc, err := cfg.GetLocation()
+	if err != nil {
+		zapLogger.Fatal("加载统计时区失败", zap.Error(err))
+	}
+	utils.SetDefaultLocation(loc)
+
+	// 共同作者行数归属方式（影响聚合表，修改后需重建聚合）
+	if err := utils.SetCreditMode(cfg.CreditMode); err != nil {
+		zapLogger.Fatal("CREDIT_MODE 配置无效", zap.Error(err))
+	}
+
+	// 外部工单号（如 JIRA）匹配规则
+	if err := utils.SetIssueKeyPatterns(cfg.IssueKeyPatterns); err != nil {
+		zapLogger.Fatal("加载工单号匹配规则失败", zap.Error(err))
+	}
+
+	// 语言映射覆盖配置
+	if cfg.LanguageMapFile != "" {
+		mappings, err := utils.LoadLanguageMappings(cfg.LanguageMapFile)
+		if err != nil {
+			zapLogger.Fatal("加载语言映射配置失败", zap.Error(err))
+		}
+		utils.SetLanguageMappings(mappings)
+	}
+
+	// 文件类别规则
+	if cfg.FileCategoryFile != "" {
+		rules, err := utils.LoadFileCategoryRules(cfg.FileCategoryFile)
+		if err != nil {
+			zapLogger.Fatal("加载文件类别规则失败", zap.Error(err))
+		}
+		if err := utils.SetFileCategoryRules(rules); err != nil {
+			zapLogger.Fatal("文件类别规则无效", zap.Error(err))
+		}
+	}
+
 	// 初始化数据库
 	if err := database.Init(cfg, zapLogger); err != nil {
 		zapLogger.Fatal("数据库初始化失败", zap.Error(err))
@@ -102,7 +140,12 @@ func main() {
 	// 注册路由
 	webhookHandler := handler.NewWebhookHandler(database.DB, workerPool, cfg.WebhookSecret, zapLogger)
 	statsHandler := handler.NewStatsHandler(database.DB, zapLogger)
-	router.RegisterRoutes(r, webhookHandler, statsHandler, importHandler)
+	teamHandler := handler.NewTeamHandler(database.DB, zapLogger)
+	codeOwnersHandler := handler.NewCodeOwnersHandler(database.DB, gitlabClient, cfg.CodeOwnersMirrorRoot, zapLogger)
+	fileRuleHandler := handler.NewFileRuleHandler(database.DB, zapLogger)
+	commitHandler := handler.NewCommitHandler(database.DB, zapLogger)
+	projectHandler := handler.NewProjectHandler(database.DB, zapLogger)
+	router.RegisterRoutes(r, webhookHandler, statsHandler, teamHandler, codeOwnersHandler, fileRuleHandler, commitHandler, projectHandler, importHandler)
 
 	// 启动服务器
 	addr := ":" + cfg.Port
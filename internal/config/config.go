@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +19,16 @@ type Config struct {
 	WorkerPool    WorkerPoolConfig
 	RateLimit     RateLimitConfig
 	GitLab        GitLabConfig
+	// CodeOwnersMirrorRoot 本地仓库镜像根目录，按 <根目录>/<项目路径> 读取 CODEOWNERS
+	CodeOwnersMirrorRoot string
+	// IssueKeyPatterns 外部工单号（如 JIRA）的匹配正则，为空时不识别外部工单
+	IssueKeyPatterns []string
+	// LanguageMapFile 语言映射配置文件（JSON），用于覆盖或补充内置的扩展名/文件名/解释器映射
+	LanguageMapFile string
+	// FileCategoryFile 文件类别规则配置文件（JSON），优先于内置的测试/文档/配置/构建规则
+	FileCategoryFile string
+	// CreditMode 共同作者（Co-authored-by）的行数归属方式：full 每位作者计全部行数，split 均分
+	CreditMode string
 }
 
 // WorkerPoolConfig 工作池配置
@@ -83,6 +95,11 @@ func Load() (*Config, error) {
 			BaseURL: getEnv("GITLAB_BASE_URL", ""),
 			Token:   getEnv("GITLAB_TOKEN", ""),
 		},
+		CodeOwnersMirrorRoot: getEnv("CODEOWNERS_MIRROR_ROOT", ""),
+		IssueKeyPatterns:     getEnvList("ISSUE_KEY_PATTERNS"),
+		LanguageMapFile:      getEnv("LANGUAGE_MAP_FILE", ""),
+		FileCategoryFile:     getEnv("FILE_CATEGORY_FILE", ""),
+		CreditMode:           getEnv("CREDIT_MODE", "full"),
 	}
 
 	return cfg, nil
@@ -123,6 +140,15 @@ func (c *Config) GetDatabaseType() string {
 	return c.Database.Type
 }
 
+// GetLocation 获取统计使用的时区（与 DB_TIMEZONE 一致）
+func (c *Config) GetLocation() (*time.Location, error) {
+	loc, err := time.LoadLocation(c.Database.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("加载时区 %s 失败: %w", c.Database.TimeZone, err)
+	}
+	return loc, nil
+}
+
 // getEnv 获取环境变量，如果不存在则返回默认值
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -131,6 +157,17 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList 获取以逗号分隔的环境变量列表，忽略空项
+func getEnvList(key string) []string {
+	var result []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 // getEnvInt 获取整数环境变量
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
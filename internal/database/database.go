@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"gitlab-webhook-server/internal/config"
 	"gitlab-webhook-server/internal/model"
@@ -17,6 +18,9 @@ import (
 // DB 全局数据库实例
 var DB *gorm.DB
 
+// timeZone 数据库连接使用的时区（DB_TIMEZONE），也是统计时区
+var timeZone string
+
 // Init 初始化数据库连接
 func Init(cfg *config.Config, zapLogger *zap.Logger) error {
 	dsn := cfg.GetDSN()
@@ -67,6 +71,7 @@ func Init(cfg *config.Config, zapLogger *zap.Logger) error {
 	sqlDB.SetMaxOpenConns(100)
 
 	DB = db
+	timeZone = cfg.Database.TimeZone
 	zapLogger.Info("数据库连接成功",
 		zap.String("type", dbType),
 		zap.String("host", cfg.Database.Host),
@@ -82,21 +87,238 @@ func Migrate() error {
 		return fmt.Errorf("数据库未初始化")
 	}
 
+	if err := prepareRollupTables(); err != nil {
+		return err
+	}
+
+	if err := preparePushes(); err != nil {
+		return err
+	}
+
 	// 自动迁移表结构
 	err := DB.AutoMigrate(
+		&model.Project{},
+		&model.ProjectPathHistory{},
 		&model.Commit{},
 		&model.CommitFile{},
 		&model.CommitLanguage{},
+		&model.CommitReference{},
+		&model.CommitBranch{},
+		&model.CommitAuthor{},
+		&model.Push{},
+		&model.PushCommit{},
 		&model.MemberContribution{},
 		&model.MemberLanguageStat{},
+		&model.RollupState{},
+		&model.Team{},
+		&model.TeamMember{},
+		&model.CodeOwnersFile{},
+		&model.ProjectFileRule{},
+		&model.DataMigration{},
 	)
 	if err != nil {
 		return fmt.Errorf("数据库迁移失败: %w", err)
 	}
 
+	if err := backfillCommitAuthors(); err != nil {
+		return err
+	}
+
+	if err := ensureMessageFullTextIndex(); err != nil {
+		return err
+	}
+
+	if err := normalizeCommitTimestamps(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// backfillCommitAuthors 为还没有作者记录的提交补充提交作者本人一行
+// 按成员统计时通过 commit_authors 归属提交，只执行 AutoMigrate 的部署没有 020 迁移中的回填，
+// 否则共同作者功能上线前的历史提交都不会计入；已有作者记录的提交不受影响，重复执行无副作用
+func backfillCommitAuthors() error {
+	insert, conflict := "INSERT INTO", ""
+	switch DB.Dialector.Name() {
+	case "mysql":
+		insert = "INSERT IGNORE INTO"
+	case "postgres":
+		conflict = " ON CONFLICT DO NOTHING"
+	}
+	err := DB.Exec(insert + " commit_authors (commit_id, email, name, is_primary)" +
+		" SELECT commits.id, commits.author_email, commits.author, ? FROM commits" +
+		" WHERE NOT EXISTS (SELECT 1 FROM commit_authors WHERE commit_authors.commit_id = commits.id)" +
+		conflict, true).Error
+	if err != nil {
+		return fmt.Errorf("回填提交作者失败: %w", err)
+	}
+	return nil
+}
+
+// rollupUniqueIndexes 聚合表及其唯一索引
+var rollupUniqueIndexes = []struct {
+	model interface{}
+	index string
+}{
+	{&model.MemberContribution{}, "idx_member_contributions_unique"},
+	{&model.MemberLanguageStat{}, "idx_member_language_stats_unique"},
+}
+
+// prepareRollupTables 在 AutoMigrate 之前整理聚合表，使唯一索引可以创建：
+// project_id IS NULL 的行不受唯一索引约束，可能已有重复，直接删除（此后没有项目信息的行记为 0）；
+// PostgreSQL 上早期迁移创建的 COALESCE(project_id, 0) 表达式索引无法用于 ON CONFLICT，删除后由 AutoMigrate 按列重建
+// （不含 NULL 时两者约束相同，不会有重复行）；
+// 唯一索引缺失时（只执行过 AutoMigrate 的部署）聚合表中可能已有重复行，直接清空。
+// 聚合表为派生数据，删除的行在执行 cmd/rollup -all 后恢复，此前聚合表处于未就绪状态，统计查询扫描明细表
+func prepareRollupTables() error {
+	migrator := DB.Migrator()
+	for _, t := range rollupUniqueIndexes {
+		if !migrator.HasTable(t.model) {
+			continue
+		}
+		if err := DB.Where("project_id IS NULL").Delete(t.model).Error; err != nil {
+			return fmt.Errorf("清理聚合表 project_id 为空的行失败: %w", err)
+		}
+
+		expression := false
+		if DB.Dialector.Name() == "postgres" {
+			var definition string
+			if err := DB.Raw("SELECT indexdef FROM pg_indexes WHERE indexname = ?", t.index).
+				Scan(&definition).Error; err != nil {
+				return fmt.Errorf("查询聚合表唯一索引失败: %w", err)
+			}
+			if strings.Contains(strings.ToUpper(definition), "COALESCE") {
+				expression = true
+				if err := migrator.DropIndex(t.model, t.index); err != nil {
+					return fmt.Errorf("删除聚合表表达式索引失败: %w", err)
+				}
+			}
+		}
+
+		if !expression && !migrator.HasIndex(t.model, t.index) {
+			if err := DB.Where("1 = 1").Delete(t.model).Error; err != nil {
+				return fmt.Errorf("清空聚合表失败: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// preparePushes 在 AutoMigrate 将 pushes.project_id 改为 NOT NULL DEFAULT 0 之前整理推送记录（与 025 迁移一致）：
+// project_id IS NULL 的推送不受唯一索引约束，webhook 重投可能已重复记录，重复的推送合并到最早的一条后改记为 0
+func preparePushes() error {
+	if !DB.Migrator().HasTable(&model.Push{}) {
+		return nil
+	}
+	var count int64
+	if err := DB.Model(&model.Push{}).Where("project_id IS NULL").Count(&count).Error; err != nil {
+		return fmt.Errorf("查询项目未知的推送记录失败: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	insert, conflict := "INSERT INTO", ""
+	switch DB.Dialector.Name() {
+	case "mysql":
+		insert = "INSERT IGNORE INTO"
+	case "postgres":
+		conflict = " ON CONFLICT DO NOTHING"
+	}
+	// 同一推送中 id 最小的一条保留，其余为重复
+	const sameIdentity = "q.platform = p.platform AND q.instance = p.instance AND q.ref = p.ref" +
+		" AND q.before_sha = p.before_sha AND q.after_sha = p.after_sha AND COALESCE(q.project_id, 0) = 0"
+	const duplicates = "SELECT id FROM (SELECT p.id FROM pushes p WHERE COALESCE(p.project_id, 0) = 0" +
+		" AND EXISTS (SELECT 1 FROM pushes q WHERE " + sameIdentity + " AND q.id < p.id)) d"
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(insert + " push_commits (push_id, commit_id)" +
+			" SELECT (SELECT MIN(q.id) FROM pushes q WHERE " + sameIdentity + "), pc.commit_id" +
+			" FROM push_commits pc JOIN pushes p ON p.id = pc.push_id" +
+			" WHERE p.id IN (" + duplicates + ")" + conflict).Error; err != nil {
+			return fmt.Errorf("合并重复推送的提交关联失败: %w", err)
+		}
+		if err := tx.Exec("DELETE FROM push_commits WHERE push_id IN (" + duplicates + ")").Error; err != nil {
+			return fmt.Errorf("删除重复推送的提交关联失败: %w", err)
+		}
+		if err := tx.Exec("DELETE FROM pushes WHERE id IN (" + duplicates + ")").Error; err != nil {
+			return fmt.Errorf("删除重复推送记录失败: %w", err)
+		}
+		if err := tx.Model(&model.Push{}).Where("project_id IS NULL").
+			UpdateColumn("project_id", 0).Error; err != nil {
+			return fmt.Errorf("更新项目未知的推送记录失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// ensureMessageFullTextIndex 创建提交说明全文索引（与 022 迁移一致），AutoMigrate 无法声明这类索引
+// MySQL 的 MATCH ... AGAINST 没有 FULLTEXT 索引时直接报错；PostgreSQL 没有索引时可以查询但需要全表扫描
+// 首次创建时需要扫描 commits 全表，数据量大时启动会变慢
+func ensureMessageFullTextIndex() error {
+	var err error
+	switch DB.Dialector.Name() {
+	case "mysql":
+		if DB.Migrator().HasIndex(&model.Commit{}, "idx_commits_message_fulltext") {
+			return nil
+		}
+		err = DB.Exec("ALTER TABLE commits ADD FULLTEXT INDEX idx_commits_message_fulltext (message) WITH PARSER ngram").Error
+	case "postgres":
+		err = DB.Exec("CREATE INDEX IF NOT EXISTS idx_commits_message_fts ON commits USING GIN (to_tsvector('simple', message))").Error
+	}
+	if err != nil {
+		return fmt.Errorf("创建提交说明全文索引失败: %w", err)
+	}
 	return nil
 }
 
+// normalizeCommitTimestamps 将 PostgreSQL 中早期入库的提交时间换算为 UTC（只执行一次）
+// 记录作者时区偏移（007 迁移）之前，提交时间以作者本地的墙上时间写入 timestamp 列，之后入库的统一为 UTC；
+// 已存在的提交重新导入时会被跳过，无法通过重新导入修正。历史提交没有记录原始偏移（author_tz_offset 为 NULL），
+// 按 DB_TIMEZONE 换算，author_tz_offset 保持为 NULL。
+// MySQL 驱动写入前会换算到连接参数 loc 的时区，历史数据与之后入库的数据一致，不需要修正
+func normalizeCommitTimestamps() error {
+	const name = "normalize_commit_timestamps"
+	if DB.Dialector.Name() != "postgres" {
+		return nil
+	}
+	zone := timeZone
+	if zone == "" {
+		zone = "UTC"
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var applied int64
+		if err := tx.Model(&model.DataMigration{}).Where("name = ?", name).Count(&applied).Error; err != nil {
+			return fmt.Errorf("查询数据修正记录失败: %w", err)
+		}
+		if applied > 0 {
+			return nil
+		}
+
+		res := tx.Model(&model.Commit{}).
+			Where("author_tz_offset IS NULL").
+			UpdateColumns(map[string]interface{}{
+				"timestamp":      gorm.Expr("timestamp AT TIME ZONE ? AT TIME ZONE 'UTC'", zone),
+				"authored_date":  gorm.Expr("authored_date AT TIME ZONE ? AT TIME ZONE 'UTC'", zone),
+				"committed_date": gorm.Expr("committed_date AT TIME ZONE ? AT TIME ZONE 'UTC'", zone),
+			})
+		if res.Error != nil {
+			return fmt.Errorf("换算历史提交时间失败: %w", res.Error)
+		}
+		// 提交时间变化后聚合周期随之变化，重建前统计查询扫描明细表
+		if res.RowsAffected > 0 {
+			if err := tx.Model(&model.RollupState{}).
+				Where("id = ?", 1).
+				Updates(map[string]interface{}{"ready": false, "reason": "历史提交时间换算为 UTC"}).Error; err != nil {
+				return fmt.Errorf("更新聚合表状态失败: %w", err)
+			}
+		}
+		return tx.Create(&model.DataMigration{Name: name, AppliedAt: time.Now()}).Error
+	})
+}
+
 // Close 关闭数据库连接
 func Close() error {
 	if DB == nil {
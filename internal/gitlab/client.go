@@ -84,6 +84,22 @@ func (c *Client) GetProject(projectID string) (*gitlab.Project, *gitlab.Response
 	return project, resp, nil
 }
 
+// GetRawFile 获取仓库中文件的原始内容，ref 为空时使用默认分支
+// 文件不存在时返回的 Response 状态码为 404
+func (c *Client) GetRawFile(projectID, filePath, ref string) ([]byte, *gitlab.Response, error) {
+	opts := &gitlab.GetRawFileOptions{}
+	if ref != "" {
+		opts.Ref = gitlab.Ptr(ref)
+	}
+
+	content, resp, err := c.client.RepositoryFiles.GetRawFile(projectID, filePath, opts)
+	if err != nil {
+		return nil, resp, fmt.Errorf("获取文件内容失败: %w", err)
+	}
+
+	return content, resp, nil
+}
+
 // CalculateDiffStats 计算 diff 统计信息
 // 从 diff 字符串中解析添加和删除的行数
 func CalculateDiffStats(diffs []*gitlab.Diff) (added, removed int) {
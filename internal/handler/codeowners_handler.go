@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+
+	"gitlab-webhook-server/internal/gitlab"
+	"gitlab-webhook-server/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CodeOwnersHandler CODEOWNERS 处理器
+type CodeOwnersHandler struct {
+	logger            *zap.Logger
+	codeOwnersService *service.CodeOwnersService
+}
+
+// NewCodeOwnersHandler 创建新的 CODEOWNERS 处理器
+// gitlabClient 可以为 nil，此时只支持从本地镜像同步
+func NewCodeOwnersHandler(
+	db *gorm.DB,
+	gitlabClient *gitlab.Client,
+	mirrorRoot string,
+	logger *zap.Logger,
+) *CodeOwnersHandler {
+	return &CodeOwnersHandler{
+		logger:            logger,
+		codeOwnersService: service.NewCodeOwnersService(db, gitlabClient, mirrorRoot, logger),
+	}
+}
+
+// SyncCodeOwners 同步项目的 CODEOWNERS 文件
+// POST /api/codeowners/sync
+// Body: {"project_path": "group/project", "ref": "main", "source": "api"}
+// source: api（通过 GitLab API 拉取）| local（从 CODEOWNERS_MIRROR_ROOT 下的本地镜像读取）
+func (h *CodeOwnersHandler) SyncCodeOwners(c *gin.Context) {
+	var req service.SyncCodeOwnersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("解析请求失败", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.ProjectID == "" && req.ProjectPath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_id 或 project_path 参数必填"})
+		return
+	}
+
+	file, err := h.codeOwnersService.Sync(&req)
+	if err != nil {
+		h.logger.Error("同步 CODEOWNERS 失败",
+			zap.Error(err),
+			zap.String("project_id", req.ProjectID),
+			zap.String("project_path", req.ProjectPath),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, file)
+}
+
+// GetCodeOwners 获取已同步的 CODEOWNERS 及解析后的规则
+// GET /api/codeowners?project_path=group/project
+func (h *CodeOwnersHandler) GetCodeOwners(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.ProjectID == nil && filter.ProjectPath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_id 或 project_path 参数必填"})
+		return
+	}
+
+	file, co, err := h.codeOwnersService.GetCodeOwners(filter.ProjectID, filter.ProjectPath)
+	if err != nil {
+		h.logger.Error("获取 CODEOWNERS 失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取 CODEOWNERS 失败"})
+		return
+	}
+	if file == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "项目尚未同步 CODEOWNERS"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":  file,
+		"rules": co.Rules,
+	})
+}
+
+// GetCodeOwnersStats 获取按 CODEOWNERS 规则划分的负责人/非负责人变更统计
+// GET /api/stats/codeowners?project_path=group/project&start_date=2024-01-01&end_date=2024-03-31
+func (h *CodeOwnersHandler) GetCodeOwnersStats(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.ProjectID == nil && filter.ProjectPath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_id 或 project_path 参数必填"})
+		return
+	}
+
+	report, err := h.codeOwnersService.GetReport(filter)
+	if err != nil {
+		h.logger.Error("获取 CODEOWNERS 统计失败",
+			zap.Error(err),
+			zap.Any("project_id", filter.ProjectID),
+			zap.String("project_path", filter.ProjectPath),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取 CODEOWNERS 统计失败"})
+		return
+	}
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "项目尚未同步 CODEOWNERS"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
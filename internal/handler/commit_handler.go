@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/service/commit"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CommitHandler 提交查询处理器
+type CommitHandler struct {
+	logger        *zap.Logger
+	commitService *commit.CommitServiceV2
+}
+
+// NewCommitHandler 创建新的提交查询处理器
+func NewCommitHandler(db *gorm.DB, logger *zap.Logger) *CommitHandler {
+	return &CommitHandler{
+		logger:        logger,
+		commitService: commit.NewCommitServiceV2(db, logger),
+	}
+}
+
+// SearchCommits 分页搜索提交
+// GET /api/commits?author=user@example.com&project_path=group/project&language=Go&q=登录&sort=lines&order=desc&limit=50
+// GET /api/commits?namespace=group&branch=main&start_date=2024-01-01&end_date=2024-03-31&min_lines=10&max_lines=500
+// GET /api/commits?cursor=<上一页的 next_cursor>（其余参数需与上一页一致）
+// author（或 email）/ team 匹配提交作者及共同作者；其余过滤参数同统计接口（project_id, platform, instance, namespace, on_default_branch 等）
+// q: 提交说明全文检索；sort: timestamp（默认）| lines | added | removed；order: desc（默认）| asc；limit 默认 50，最大 200
+func (h *CommitHandler) SearchCommits(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if author := c.Query("author"); author != "" {
+		filter.AuthorEmail = author
+	}
+
+	search := &repository.CommitSearch{
+		Filter:   *filter,
+		Language: c.Query("language"),
+		Query:    strings.TrimSpace(c.Query("q")),
+		Sort:     c.DefaultQuery("sort", repository.CommitSortTimestamp),
+		Limit:    parseLimit(c, 50, 200),
+	}
+	if !repository.ValidCommitSort(search.Sort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort 参数无效，可选值: timestamp, lines, added, removed"})
+		return
+	}
+	switch c.DefaultQuery("order", "desc") {
+	case "desc":
+	case "asc":
+		search.Asc = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order 参数无效，可选值: asc, desc"})
+		return
+	}
+	if search.MinLines, err = parseOptionalCount(c, "min_lines"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if search.MaxLines, err = parseOptionalCount(c, "max_lines"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if s := c.Query("cursor"); s != "" {
+		cursor, err := repository.DecodeCommitCursor(s)
+		if err != nil || !cursor.Matches(search.Sort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cursor 无效或与 sort 参数不匹配"})
+			return
+		}
+		search.Cursor = cursor
+	}
+
+	page, err := h.commitService.SearchCommits(search)
+	if err != nil {
+		h.logger.Error("搜索提交失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "搜索提交失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"commits":     page.Commits,
+		"count":       len(page.Commits),
+		"next_cursor": page.NextCursor,
+		"has_more":    page.NextCursor != "",
+	})
+}
+
+// parseOptionalCount 解析可选的非负整数查询参数，未指定时返回 nil
+func parseOptionalCount(c *gin.Context, name string) (*int, error) {
+	s := c.Query(name)
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		return nil, fmt.Errorf("%s 必须为非负整数", name)
+	}
+	return &v, nil
+}
+
+// shaPattern 完整或缩写（至少 7 位）的提交 SHA
+var shaPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,64}$`)
+
+// GetCommit 获取单个提交的详情：文件变更、语言统计、推送记录、出现过的分支、关联的合并请求 / issue / 工单、作者和项目
+// GET /api/commits/:sha
+// GET /api/commits/:sha?project_path=group/project
+// GET /api/commits/:sha?platform=gitlab&instance=gitlab.example.com&project_id=123
+// sha 可以是至少 7 位的短 SHA；同一 SHA 出现在多个项目（如 fork）时返回最早入库的一条，其余列在 other_projects 中
+func (h *CommitHandler) GetCommit(c *gin.Context) {
+	sha := c.Param("sha")
+	if !shaPattern.MatchString(sha) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sha 必须为至少 7 位的十六进制提交 SHA"})
+		return
+	}
+	scope, err := parseCommitScope(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	detail, err := h.commitService.GetCommitDetail(strings.ToLower(sha), scope)
+	if err != nil {
+		h.logger.Error("获取提交详情失败", zap.Error(err), zap.String("sha", sha))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取提交详情失败"})
+		return
+	}
+	if detail == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "提交不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// parseCommitScope 解析按 SHA 查询提交时的项目范围：platform, instance, project_id, project_path
+// 均未指定时返回 nil（不限定项目）
+func parseCommitScope(c *gin.Context) (*repository.StatsFilter, error) {
+	scope := &repository.StatsFilter{
+		Platform:    c.Query("platform"),
+		Instance:    c.Query("instance"),
+		ProjectPath: c.Query("project_path"),
+	}
+	if idStr := c.Query("project_id"); idStr != "" {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("project_id 必须为整数")
+		}
+		scope.ProjectID = &id
+	}
+	if scope.Platform == "" && scope.Instance == "" && scope.ProjectPath == "" && scope.ProjectID == nil {
+		return nil, nil
+	}
+	return scope, nil
+}
+
+// GetEquivalentCommits 获取与指定提交属于同一逻辑变更的提交（cherry-pick、rebase、fork 推送等）
+// 统计时每组只计入 canonical_id 对应的提交
+// GET /api/commits/:sha/equivalents
+func (h *CommitHandler) GetEquivalentCommits(c *gin.Context) {
+	sha := c.Param("sha")
+
+	commits, err := h.commitService.GetEquivalentCommits(sha)
+	if err != nil {
+		h.logger.Error("获取等价提交失败", zap.Error(err), zap.String("sha", sha))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取等价提交失败"})
+		return
+	}
+	if len(commits) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "提交不存在"})
+		return
+	}
+
+	var canonical *model.Commit
+	for _, item := range commits {
+		if item.DuplicateOf == nil {
+			canonical = item
+			break
+		}
+	}
+	if canonical == nil {
+		canonical = commits[0]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sha":          sha,
+		"change_key":   canonical.ChangeKey,
+		"canonical_id": canonical.ID,
+		"count":        len(commits),
+		"commits":      commits,
+	})
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+
+	"gitlab-webhook-server/internal/service"
+	"gitlab-webhook-server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// FileRuleHandler 项目文件分类规则处理器
+type FileRuleHandler struct {
+	logger          *zap.Logger
+	fileRuleService *service.FileRuleService
+}
+
+// NewFileRuleHandler 创建新的项目文件分类规则处理器
+func NewFileRuleHandler(db *gorm.DB, logger *zap.Logger) *FileRuleHandler {
+	return &FileRuleHandler{
+		logger:          logger,
+		fileRuleService: service.NewFileRuleService(db, logger),
+	}
+}
+
+// GetFileRules 获取项目的文件分类规则及内置规则
+// GET /api/projects/file-rules?project_path=group/project
+func (h *FileRuleHandler) GetFileRules(c *gin.Context) {
+	projectPath := c.Query("project_path")
+	if projectPath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_path 参数必填"})
+		return
+	}
+
+	rules, err := h.fileRuleService.GetRules(projectPath)
+	if err != nil {
+		h.logger.Error("获取文件分类规则失败", zap.Error(err), zap.String("project_path", projectPath))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文件分类规则失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project_path":  projectPath,
+		"rules":         rules,
+		"default_rules": utils.DefaultFileClassRules(),
+	})
+}
+
+// SaveFileRules 整体替换项目的文件分类规则（后面的规则优先，且优先于内置规则）
+// PUT /api/projects/file-rules
+// Body: {"project_path": "group/project", "rules": [{"pattern": "gen/", "class": "generated"}, {"pattern": "third_party/", "class": ""}], "gitattributes": "*.pb.go linguist-generated"}
+func (h *FileRuleHandler) SaveFileRules(c *gin.Context) {
+	var req service.SaveFileRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("解析请求失败", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	rules, err := h.fileRuleService.SaveRules(&req)
+	if err != nil {
+		h.logger.Error("保存文件分类规则失败", zap.Error(err), zap.String("project_path", req.ProjectPath))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project_path": req.ProjectPath,
+		"rules":        rules,
+	})
+}
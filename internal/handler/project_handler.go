@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+
+	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ProjectHandler 项目处理器
+type ProjectHandler struct {
+	logger         *zap.Logger
+	projectService *service.ProjectService
+}
+
+// NewProjectHandler 创建新的项目处理器
+func NewProjectHandler(db *gorm.DB, logger *zap.Logger) *ProjectHandler {
+	return &ProjectHandler{
+		logger:         logger,
+		projectService: service.NewProjectService(db, logger),
+	}
+}
+
+// ListProjects 获取项目元数据及路径历史
+// GET /api/projects?platform=gitlab&instance=gitlab.example.com&namespace=group&project_path=group/old-name
+// project_path 可以是项目曾用的路径，返回重命名后的项目
+func (h *ProjectHandler) ListProjects(c *gin.Context) {
+	filter := &repository.ProjectFilter{
+		Platform:  c.Query("platform"),
+		Instance:  c.Query("instance"),
+		Namespace: c.Query("namespace"),
+		Path:      c.Query("project_path"),
+	}
+
+	projects, err := h.projectService.ListProjects(filter)
+	if err != nil {
+		h.logger.Error("获取项目列表失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取项目列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(projects),
+		"projects": projects,
+	})
+}
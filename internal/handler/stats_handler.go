@@ -1,10 +1,16 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"gitlab-webhook-server/internal/repository"
 	"gitlab-webhook-server/internal/service/commit"
+	"gitlab-webhook-server/internal/service/stats"
+	"gitlab-webhook-server/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -15,6 +21,7 @@ import (
 type StatsHandler struct {
 	logger        *zap.Logger
 	commitService *commit.CommitServiceV2
+	statsService  *stats.StatsService
 }
 
 // NewStatsHandler 创建新的统计处理器
@@ -22,11 +29,15 @@ func NewStatsHandler(db *gorm.DB, logger *zap.Logger) *StatsHandler {
 	return &StatsHandler{
 		logger:        logger,
 		commitService: commit.NewCommitServiceV2(db, logger),
+		statsService:  stats.NewStatsService(db, logger),
 	}
 }
 
 // GetMemberStats 获取成员统计信息
 // GET /api/stats/member?email=user@example.com&start_date=2024-01-01&end_date=2024-02-01
+// 支持与其他统计接口相同的过滤参数（project_id、branch、include_merges、include_generated、on_default_branch、exclude_reverts 等）
+// 可选 group_by=day|week|month（以及 tz、week_start）返回趋势数据，同时用于文件类别趋势
+// 文件类别分布需要扫描 commit_files，仅在 include=categories 时返回
 func (h *StatsHandler) GetMemberStats(c *gin.Context) {
 	email := c.Query("email")
 	if email == "" {
@@ -34,22 +45,13 @@ func (h *StatsHandler) GetMemberStats(c *gin.Context) {
 		return
 	}
 
-	// 解析时间参数
-	var startDate, endDate *time.Time
-	if startStr := c.Query("start_date"); startStr != "" {
-		if t, err := time.Parse("2006-01-02", startStr); err == nil {
-			startDate = &t
-		}
-	}
-	if endStr := c.Query("end_date"); endStr != "" {
-		if t, err := time.Parse("2006-01-02", endStr); err == nil {
-			// 设置为当天的结束时间
-			t = t.Add(24*time.Hour - time.Second)
-			endDate = &t
-		}
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	stats, err := h.commitService.GetMemberStats(email, startDate, endDate)
+	stats, err := h.commitService.GetMemberStats(filter)
 	if err != nil {
 		h.logger.Error("获取成员统计失败",
 			zap.Error(err),
@@ -59,17 +61,58 @@ func (h *StatsHandler) GetMemberStats(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"email":        email,
 		"commit_count": stats.CommitCount,
 		"total_added":  stats.TotalAdded,
 		"total_removed": stats.TotalRemoved,
 		"total_files":  stats.TotalFiles,
-	})
+	}
+
+	// 指定 group_by 时附带时间序列
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		query, err := parseTimeSeriesQuery(c, groupBy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		series, err := h.statsService.GetTimeSeries(query)
+		if err != nil {
+			h.logger.Error("获取成员时间序列失败",
+				zap.Error(err),
+				zap.String("email", email),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计信息失败"})
+			return
+		}
+		response["series"] = series
+	}
+
+	// 测试 / 生产代码等文件类别分布（指定 group_by 时同时返回趋势）
+	if includes(c, "categories") {
+		categoryQuery, err := parseCategoryQuery(c, "", c.Query("group_by"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		categories, err := h.statsService.GetCategoryReport(categoryQuery)
+		if err != nil {
+			h.logger.Error("获取成员文件类别分布失败",
+				zap.Error(err),
+				zap.String("email", email),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计信息失败"})
+			return
+		}
+		response["categories"] = categories
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // GetLanguageStats 获取语言统计信息
 // GET /api/stats/languages?email=user@example.com&start_date=2024-01-01&end_date=2024-02-01
+// 支持与其他统计接口相同的过滤参数
 func (h *StatsHandler) GetLanguageStats(c *gin.Context) {
 	email := c.Query("email")
 	if email == "" {
@@ -77,21 +120,13 @@ func (h *StatsHandler) GetLanguageStats(c *gin.Context) {
 		return
 	}
 
-	// 解析时间参数
-	var startDate, endDate *time.Time
-	if startStr := c.Query("start_date"); startStr != "" {
-		if t, err := time.Parse("2006-01-02", startStr); err == nil {
-			startDate = &t
-		}
-	}
-	if endStr := c.Query("end_date"); endStr != "" {
-		if t, err := time.Parse("2006-01-02", endStr); err == nil {
-			t = t.Add(24*time.Hour - time.Second)
-			endDate = &t
-		}
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	stats, err := h.commitService.GetLanguageStats(email, startDate, endDate)
+	stats, err := h.commitService.GetLanguageStats(filter)
 	if err != nil {
 		h.logger.Error("获取语言统计失败",
 			zap.Error(err),
@@ -109,6 +144,7 @@ func (h *StatsHandler) GetLanguageStats(c *gin.Context) {
 
 // GetMemberCommits 获取成员提交记录
 // GET /api/stats/commits?email=user@example.com&start_date=2024-01-01&end_date=2024-02-01
+// 返回区间内的全部提交，数据量大时请使用 /api/commits 分页查询
 func (h *StatsHandler) GetMemberCommits(c *gin.Context) {
 	email := c.Query("email")
 	if email == "" {
@@ -117,17 +153,10 @@ func (h *StatsHandler) GetMemberCommits(c *gin.Context) {
 	}
 
 	// 解析时间参数
-	var startDate, endDate *time.Time
-	if startStr := c.Query("start_date"); startStr != "" {
-		if t, err := time.Parse("2006-01-02", startStr); err == nil {
-			startDate = &t
-		}
-	}
-	if endStr := c.Query("end_date"); endStr != "" {
-		if t, err := time.Parse("2006-01-02", endStr); err == nil {
-			t = t.Add(24*time.Hour - time.Second)
-			endDate = &t
-		}
+	startDate, endDate, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	commits, err := h.commitService.GetMemberCommits(email, startDate, endDate)
@@ -147,3 +176,768 @@ func (h *StatsHandler) GetMemberCommits(c *gin.Context) {
 	})
 }
 
+
+// GetProjectStats 获取项目统计信息
+// GET /api/stats/project?project_id=123&start_date=2024-01-01&end_date=2024-02-01&limit=10
+// GET /api/stats/project?project_path=group/project&branch=main
+// 可选 period=day|week|month 返回文件类别（测试 / 生产代码等）趋势
+func (h *StatsHandler) GetProjectStats(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.ProjectID == nil && filter.ProjectPath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_id 或 project_path 参数必填"})
+		return
+	}
+
+	report, err := h.statsService.GetProjectReport(filter, parseLimit(c, 10, 100))
+	if err != nil {
+		h.logger.Error("获取项目统计失败",
+			zap.Error(err),
+			zap.Any("project_id", filter.ProjectID),
+			zap.String("project_path", filter.ProjectPath),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计信息失败"})
+		return
+	}
+
+	// 测试 / 生产代码等文件类别分布，按成员拆分；指定 period 时同时返回趋势
+	categoryQuery, err := parseCategoryQuery(c, "member", c.Query("period"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	categories, err := h.statsService.GetCategoryReport(categoryQuery)
+	if err != nil {
+		h.logger.Error("获取项目文件类别分布失败",
+			zap.Error(err),
+			zap.Any("project_id", filter.ProjectID),
+			zap.String("project_path", filter.ProjectPath),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计信息失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project_id":       filter.ProjectID,
+		"project_path":     filter.ProjectPath,
+		"summary":          report.Summary,
+		"languages":        report.Languages,
+		"top_contributors": report.TopContributors,
+		"branches":         report.Branches,
+		"categories":       categories,
+	})
+}
+
+// GetNamespaceStats 获取命名空间（组）统计信息，包含所有子组下的项目
+// GET /api/stats/namespace?namespace=group/subgroup&start_date=2024-01-01&end_date=2024-02-01&limit=10
+func (h *StatsHandler) GetNamespaceStats(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.Namespace == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "namespace 参数必填"})
+		return
+	}
+
+	report, err := h.statsService.GetProjectReport(filter, parseLimit(c, 10, 100))
+	if err != nil {
+		h.logger.Error("获取命名空间统计失败",
+			zap.Error(err),
+			zap.String("namespace", filter.Namespace),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计信息失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace":        filter.Namespace,
+		"summary":          report.Summary,
+		"languages":        report.Languages,
+		"top_contributors": report.TopContributors,
+		"branches":         report.Branches,
+	})
+}
+
+// GetLeaderboard 获取成员排行榜
+// GET /api/stats/leaderboard?metric=commits&team=backend&start_date=2024-01-01&end_date=2024-01-31&page=1&page_size=20
+// metric: commits | net_lines | files | active_days | language_lines（需指定 language）
+func (h *StatsHandler) GetLeaderboard(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := &stats.LeaderboardQuery{
+		Filter:   *filter,
+		Location: loc,
+		Metric:   c.DefaultQuery("metric", stats.MetricCommits),
+		Language: c.Query("language"),
+		Page:     parsePositiveInt(c.Query("page"), 1),
+		PageSize: parsePositiveInt(c.Query("page_size"), 20),
+	}
+	if query.PageSize > 100 {
+		query.PageSize = 100
+	}
+
+	if !stats.ValidMetric(query.Metric) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metric 参数无效"})
+		return
+	}
+	if query.Metric == stats.MetricLanguageLines && query.Language == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metric=language_lines 时 language 参数必填"})
+		return
+	}
+
+	board, err := h.statsService.GetLeaderboard(query)
+	if err != nil {
+		h.logger.Error("获取排行榜失败",
+			zap.Error(err),
+			zap.String("metric", query.Metric),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取排行榜失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// GetComparison 并排对比多个成员或团队在同一时间段的指标、语言分布和按活跃天数归一化的指标
+// GET /api/stats/compare?emails=a@example.com,b@example.com&start_date=2024-01-01&end_date=2024-03-31
+// GET /api/stats/compare?teams=backend,frontend&project_path=group/project&tz=Asia/Shanghai
+// emails 与 teams 二选一，最多 10 个；其余过滤参数同统计接口（email / team 参数被忽略）
+func (h *StatsHandler) GetComparison(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	loc, err := parseLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	emails, teams := splitList(c.Query("emails")), splitList(c.Query("teams"))
+	query := &stats.CompareQuery{Filter: *filter, Location: loc}
+	switch {
+	case len(emails) > 0 && len(teams) > 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "emails 与 teams 只能指定其一"})
+		return
+	case len(emails) > 0:
+		query.By, query.Subjects = stats.CompareByMember, emails
+	case len(teams) > 0:
+		query.By, query.Subjects = stats.CompareByTeam, teams
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "emails 或 teams 参数必填"})
+		return
+	}
+	if len(query.Subjects) > stats.MaxCompareSubjects {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("最多对比 %d 个成员或团队", stats.MaxCompareSubjects)})
+		return
+	}
+
+	report, err := h.statsService.Compare(query)
+	if err != nil {
+		h.logger.Error("获取对比统计失败",
+			zap.Error(err),
+			zap.String("by", query.By),
+			zap.Strings("subjects", query.Subjects),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取对比统计失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// splitList 解析逗号分隔的列表参数（去除空白、空项和重复项，保持顺序）
+func splitList(s string) []string {
+	var items []string
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		items = append(items, item)
+	}
+	return items
+}
+
+// GetTimeSeries 获取按时间分桶的统计
+// GET /api/stats/timeseries?email=user@example.com&group_by=week&week_start=sunday&tz=Europe/Berlin&start_date=2024-01-01&end_date=2024-03-31
+// 可按 email / team / project_id / project_path / namespace / branch 组合过滤
+func (h *StatsHandler) GetTimeSeries(c *gin.Context) {
+	query, err := parseTimeSeriesQuery(c, c.DefaultQuery("group_by", "day"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series, err := h.statsService.GetTimeSeries(query)
+	if err != nil {
+		h.logger.Error("获取时间序列失败",
+			zap.Error(err),
+			zap.String("group_by", query.GroupBy),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取时间序列失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// GetHeatmap 获取星期 × 小时的活跃热力图
+// GET /api/stats/heatmap?email=user@example.com&start_date=2024-01-01&end_date=2024-03-31
+// GET /api/stats/heatmap?team=backend&tz=Asia/Shanghai
+// 默认按作者提交时的本地时间统计；传入 tz 时改为按查看者时区统计
+func (h *StatsHandler) GetHeatmap(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.AuthorEmail == "" && filter.Team == "" && filter.ProjectID == nil &&
+		filter.ProjectPath == "" && filter.Namespace == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email、team、project_id、project_path、namespace 至少需要指定一个"})
+		return
+	}
+
+	query := &stats.HeatmapQuery{Filter: *filter}
+	if c.Query("tz") != "" {
+		if query.Location, err = parseLocation(c); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	heatmap, err := h.statsService.GetHeatmap(query)
+	if err != nil {
+		h.logger.Error("获取活跃热力图失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取活跃热力图失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+// GetHotspots 获取项目内文件/目录的变更热点
+// GET /api/stats/hotspots?project_id=123&level=file&sort=churn&rework_days=21&limit=50
+// GET /api/stats/hotspots?project_path=group/project&level=directory&depth=2&path=internal
+// sort: changes | churn | authors | recency | rework
+func (h *StatsHandler) GetHotspots(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.ProjectID == nil && filter.ProjectPath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_id 或 project_path 参数必填"})
+		return
+	}
+
+	query := &stats.HotspotQuery{
+		Filter:     *filter,
+		PathPrefix: c.Query("path"),
+		Level:      c.DefaultQuery("level", stats.HotspotLevelFile),
+		Depth:      parsePositiveInt(c.Query("depth"), 0),
+		Sort:       c.DefaultQuery("sort", stats.HotspotSortChanges),
+		ReworkDays: parsePositiveInt(c.Query("rework_days"), stats.DefaultReworkDays),
+		Limit:      parseLimit(c, 50, 500),
+	}
+	if query.Level != stats.HotspotLevelFile && query.Level != stats.HotspotLevelDirectory {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "level 参数无效，可选值: file, directory"})
+		return
+	}
+	if !stats.ValidHotspotSort(query.Sort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort 参数无效，可选值: changes, churn, authors, recency, rework"})
+		return
+	}
+
+	report, err := h.statsService.GetHotspots(query)
+	if err != nil {
+		h.logger.Error("获取变更热点失败",
+			zap.Error(err),
+			zap.Any("project_id", filter.ProjectID),
+			zap.String("project_path", filter.ProjectPath),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取变更热点失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetOwnership 获取项目各目录的代码归属与 bus factor
+// GET /api/stats/ownership?project_id=123&depth=2&half_life_days=180&path=internal
+// bus_factor 为份额合计超过 50% 所需的最少人数
+func (h *StatsHandler) GetOwnership(c *gin.Context) {
+	query, err := parseOwnershipQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dirs, err := h.statsService.GetOwnership(query)
+	if err != nil {
+		h.logger.Error("获取代码归属失败",
+			zap.Error(err),
+			zap.Any("project_id", query.Filter.ProjectID),
+			zap.String("project_path", query.Filter.ProjectPath),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取代码归属失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"depth":          query.Depth,
+		"half_life_days": query.HalfLifeDays,
+		"directories":    dirs,
+	})
+}
+
+// GetInactiveOwnership 列出主要由不活跃成员持有的目录（不活跃成员份额合计超过 50%）
+// GET /api/stats/ownership/inactive?project_id=123&inactive_days=90&depth=2
+func (h *StatsHandler) GetInactiveOwnership(c *gin.Context) {
+	query, err := parseOwnershipQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	inactiveDays := parsePositiveInt(c.Query("inactive_days"), stats.DefaultInactiveDays)
+
+	dirs, err := h.statsService.GetInactiveOwnership(query, inactiveDays)
+	if err != nil {
+		h.logger.Error("获取不活跃成员归属目录失败",
+			zap.Error(err),
+			zap.Any("project_id", query.Filter.ProjectID),
+			zap.String("project_path", query.Filter.ProjectPath),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取不活跃成员归属目录失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"inactive_days": inactiveDays,
+		"directories":   dirs,
+	})
+}
+
+// GetCommitTypes 获取 Conventional Commits 类型分布与规范符合率
+// GET /api/stats/commit-types?project_id=123&group_by=member&start_date=2024-01-01&end_date=2024-03-31
+// GET /api/stats/commit-types?email=user@example.com
+// group_by: member | project（可选）
+func (h *StatsHandler) GetCommitTypes(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	groupBy := c.Query("group_by")
+	if !stats.ValidCommitTypeGroupBy(groupBy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by 参数无效，可选值: member, project"})
+		return
+	}
+
+	report, err := h.statsService.GetCommitTypes(filter, groupBy)
+	if err != nil {
+		h.logger.Error("获取提交类型分布失败",
+			zap.Error(err),
+			zap.String("group_by", groupBy),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取提交类型分布失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetRevertStats 获取回滚率：提交中已被回滚的比例及回滚提交数
+// GET /api/stats/reverts?project_path=group/project&group_by=member&start_date=2024-01-01&end_date=2024-03-31
+// group_by: member | project（可选）
+func (h *StatsHandler) GetRevertStats(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	groupBy := c.Query("group_by")
+	if !stats.ValidRevertGroupBy(groupBy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by 参数无效，可选值: member, project"})
+		return
+	}
+
+	report, err := h.statsService.GetRevertStats(filter, groupBy)
+	if err != nil {
+		h.logger.Error("获取回滚统计失败",
+			zap.Error(err),
+			zap.String("group_by", groupBy),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取回滚统计失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetIssueCommits 获取引用了指定 issue / 合并请求 / 工单的提交及参与者
+// GET /api/issues/ABC-123/commits
+// GET /api/issues/group/project%23123/commits（/ 也可编码为 %2F）
+// GET /api/issues/%23123/commits?project_path=group/project（#123、!45 或纯数字需指定 project_path）
+func (h *StatsHandler) GetIssueCommits(c *gin.Context) {
+	// 通配参数为解码后的 /<工单号>/commits
+	key, ok := strings.CutSuffix(strings.TrimPrefix(c.Param("key"), "/"), "/commits")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "接口不存在"})
+		return
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "工单号不能为空"})
+		return
+	}
+
+	if number, refType, ok := parseLocalReference(key); ok {
+		projectPath := c.Query("project_path")
+		if projectPath == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "未指定项目的引用需要 project_path 参数"})
+			return
+		}
+		key = utils.ReferenceKey(refType, projectPath, number)
+	}
+
+	report, err := h.statsService.GetIssueCommits(key)
+	if err != nil {
+		h.logger.Error("获取工单关联提交失败", zap.Error(err), zap.String("key", key))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取工单关联提交失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetReferenceCoverage 获取没有关联工单的提交占比
+// GET /api/stats/references?project_id=123&group_by=member&start_date=2024-01-01&end_date=2024-03-31
+func (h *StatsHandler) GetReferenceCoverage(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	groupBy := c.Query("group_by")
+	if groupBy != "" && groupBy != "member" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by 参数无效，可选值: member"})
+		return
+	}
+
+	report, err := h.statsService.GetReferenceCoverage(filter, groupBy == "member")
+	if err != nil {
+		h.logger.Error("获取工单引用覆盖率失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取工单引用覆盖率失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetCodeCategories 获取测试 / 生产 / 文档 / 配置 / 构建代码的行数分布和测试代码比
+// GET /api/stats/code-categories?project_path=group/project&group_by=member&period=month
+// GET /api/stats/code-categories?email=user@example.com&period=week&tz=Asia/Shanghai
+// group_by=member|project 返回每个成员或项目的分布；period=day|week|month 返回趋势
+func (h *StatsHandler) GetCodeCategories(c *gin.Context) {
+	query, err := parseCategoryQuery(c, c.Query("group_by"), c.Query("period"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.statsService.GetCategoryReport(query)
+	if err != nil {
+		h.logger.Error("获取文件类别分布失败",
+			zap.Error(err),
+			zap.String("group_by", query.GroupBy),
+			zap.String("period", query.Period),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文件类别分布失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetPusherStats 获取推送者统计：推送频率、每次推送的平均提交数、受保护分支推送、推送他人提交的情况
+// GET /api/stats/pushers?project_path=group/project&branch=main&start_date=2024-01-01&end_date=2024-03-31&limit=20
+// email / team 按推送者过滤，branch 按推送的分支过滤
+func (h *StatsHandler) GetPusherStats(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.statsService.GetPusherStats(filter, parseLimit(c, 50, 500))
+	if err != nil {
+		h.logger.Error("获取推送者统计失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取推送者统计失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseStatsFilter 解析通用的统计过滤参数
+// project_id, platform, instance, project_path, namespace, team, branch, on_default_branch, email, start_date, end_date,
+// include_generated, include_merges, include_duplicates, exclude_reverts
+func parseStatsFilter(c *gin.Context) (*repository.StatsFilter, error) {
+	filter := &repository.StatsFilter{
+		AuthorEmail: c.Query("email"),
+		ProjectPath: c.Query("project_path"),
+		Namespace:   c.Query("namespace"),
+		Team:        c.Query("team"),
+		Branch:      c.Query("branch"),
+		Platform:    c.Query("platform"),
+		Instance:    c.Query("instance"),
+	}
+	if idStr := c.Query("project_id"); idStr != "" {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("project_id 必须为整数")
+		}
+		filter.ProjectID = &id
+	}
+	if s := c.Query("include_generated"); s != "" {
+		includeGenerated, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("include_generated 必须为 true 或 false")
+		}
+		filter.IncludeGenerated = includeGenerated
+	}
+	if s := c.Query("include_merges"); s != "" {
+		includeMerges, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("include_merges 必须为 true 或 false")
+		}
+		filter.IncludeMerges = includeMerges
+	}
+	if s := c.Query("on_default_branch"); s != "" {
+		onDefaultBranch, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("on_default_branch 必须为 true 或 false")
+		}
+		filter.OnDefaultBranch = onDefaultBranch
+	}
+	if s := c.Query("include_duplicates"); s != "" {
+		includeDuplicates, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("include_duplicates 必须为 true 或 false")
+		}
+		filter.IncludeDuplicates = includeDuplicates
+	}
+	if s := c.Query("exclude_reverts"); s != "" {
+		excludeReverts, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("exclude_reverts 必须为 true 或 false")
+		}
+		filter.ExcludeReverts = excludeReverts
+	}
+	startDate, endDate, err := parseDateRange(c)
+	if err != nil {
+		return nil, err
+	}
+	filter.StartDate, filter.EndDate = startDate, endDate
+	return filter, nil
+}
+
+// parsePositiveInt 解析正整数，非法值使用默认值
+func parsePositiveInt(s string, defaultValue int) int {
+	v, err := strconv.Atoi(s)
+	if err != nil || v <= 0 {
+		return defaultValue
+	}
+	return v
+}
+
+// includes 判断 include 查询参数（逗号分隔）是否包含指定的可选数据
+func includes(c *gin.Context, name string) bool {
+	for _, item := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(item) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLimit 解析 limit 查询参数，非法值使用默认值，超过上限时截断
+func parseLimit(c *gin.Context, defaultLimit, maxLimit int) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 {
+		return defaultLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
+}
+
+// parseDateRange 解析 start_date / end_date 查询参数（格式 2006-01-02）
+// 日期按 tz 参数指定的时区解释（缺省为 DB_TIMEZONE），返回左闭右开区间：end_date 当天也包含在内
+// 日期格式错误或 end_date 早于 start_date 时返回错误
+func parseDateRange(c *gin.Context) (startDate, endDate *time.Time, err error) {
+	loc, err := parseLocation(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseDateRangeIn(c, loc)
+}
+
+// parseDateRangeIn 按指定时区解析 start_date / end_date 查询参数
+func parseDateRangeIn(c *gin.Context, loc *time.Location) (startDate, endDate *time.Time, err error) {
+	if startStr := c.Query("start_date"); startStr != "" {
+		t, err := time.ParseInLocation("2006-01-02", startStr, loc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("start_date 格式错误，应为 YYYY-MM-DD: %s", startStr)
+		}
+		startDate = &t
+	}
+	if endStr := c.Query("end_date"); endStr != "" {
+		t, err := time.ParseInLocation("2006-01-02", endStr, loc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("end_date 格式错误，应为 YYYY-MM-DD: %s", endStr)
+		}
+		// 次日零点（按日历日计算，夏令时切换日同样正确），查询条件为 timestamp < endDate
+		t = t.AddDate(0, 0, 1)
+		endDate = &t
+	}
+	if startDate != nil && endDate != nil && !startDate.Before(*endDate) {
+		return nil, nil, fmt.Errorf("end_date 不能早于 start_date")
+	}
+	return startDate, endDate, nil
+}
+
+// parseLocation 解析 tz 查询参数（IANA 时区名，如 Europe/Berlin），缺省使用默认统计时区
+func parseLocation(c *gin.Context) (*time.Location, error) {
+	tz := c.Query("tz")
+	if tz == "" {
+		return utils.DefaultLocation(), nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("tz 参数无效: %s", tz)
+	}
+	return loc, nil
+}
+
+// parseWeekStart 解析 week_start 查询参数（monday / sunday 等），缺省为周一
+func parseWeekStart(c *gin.Context) (time.Weekday, error) {
+	value := strings.ToLower(c.DefaultQuery("week_start", "monday"))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.ToLower(d.String()) == value {
+			return d, nil
+		}
+	}
+	return time.Monday, fmt.Errorf("week_start 参数无效: %s", value)
+}
+
+// parseTimeSeriesQuery 解析时间序列查询参数（group_by, tz, week_start 及通用过滤参数）
+func parseTimeSeriesQuery(c *gin.Context, groupBy string) (*stats.TimeSeriesQuery, error) {
+	if !stats.ValidGroupBy(groupBy) {
+		return nil, fmt.Errorf("group_by 参数无效，可选值: day, week, month")
+	}
+	loc, err := parseLocation(c)
+	if err != nil {
+		return nil, err
+	}
+	weekStart, err := parseWeekStart(c)
+	if err != nil {
+		return nil, err
+	}
+	// 日期边界与分桶使用同一时区（均来自 tz 参数）
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats.TimeSeriesQuery{
+		Filter:    *filter,
+		GroupBy:   groupBy,
+		Location:  loc,
+		WeekStart: weekStart,
+	}, nil
+}
+
+// parseCategoryQuery 解析文件类别分布查询参数（tz, week_start 及通用过滤参数）
+func parseCategoryQuery(c *gin.Context, groupBy, period string) (*stats.CategoryQuery, error) {
+	if !stats.ValidCategoryGroupBy(groupBy) {
+		return nil, fmt.Errorf("group_by 参数无效，可选值: member, project")
+	}
+	if period != "" && !stats.ValidGroupBy(period) {
+		return nil, fmt.Errorf("period 参数无效，可选值: day, week, month")
+	}
+	loc, err := parseLocation(c)
+	if err != nil {
+		return nil, err
+	}
+	weekStart, err := parseWeekStart(c)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats.CategoryQuery{
+		Filter:    *filter,
+		GroupBy:   groupBy,
+		Period:    period,
+		Location:  loc,
+		WeekStart: weekStart,
+	}, nil
+}
+
+// parseOwnershipQuery 解析代码归属查询参数（path, depth, half_life_days 及通用过滤参数）
+func parseOwnershipQuery(c *gin.Context) (*stats.OwnershipQuery, error) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		return nil, err
+	}
+	if filter.ProjectID == nil && filter.ProjectPath == "" {
+		return nil, fmt.Errorf("project_id 或 project_path 参数必填")
+	}
+
+	return &stats.OwnershipQuery{
+		Filter:       *filter,
+		PathPrefix:   c.Query("path"),
+		Depth:        parsePositiveInt(c.Query("depth"), 0),
+		HalfLifeDays: parsePositiveInt(c.Query("half_life_days"), 0),
+	}, nil
+}
+
+// parseLocalReference 解析未指定项目的引用（#123、!45 或 123）
+func parseLocalReference(key string) (number, refType string, ok bool) {
+	refType = utils.ReferenceIssue
+	switch {
+	case strings.HasPrefix(key, "#"):
+		key = key[1:]
+	case strings.HasPrefix(key, "!"):
+		key = key[1:]
+		refType = utils.ReferenceMergeRequest
+	}
+	if _, err := strconv.ParseUint(key, 10, 64); err != nil {
+		return "", "", false
+	}
+	return key, refType, true
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TeamHandler 团队管理处理器
+type TeamHandler struct {
+	logger      *zap.Logger
+	teamService *service.TeamService
+}
+
+// NewTeamHandler 创建新的团队管理处理器
+func NewTeamHandler(db *gorm.DB, logger *zap.Logger) *TeamHandler {
+	return &TeamHandler{
+		logger:      logger,
+		teamService: service.NewTeamService(db, logger),
+	}
+}
+
+// ListTeams 获取所有团队
+// GET /api/teams
+func (h *TeamHandler) ListTeams(c *gin.Context) {
+	teams, err := h.teamService.ListTeams()
+	if err != nil {
+		h.logger.Error("获取团队列表失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取团队列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"teams": teams,
+		"count": len(teams),
+	})
+}
+
+// GetTeam 获取团队详情
+// GET /api/teams/:name
+func (h *TeamHandler) GetTeam(c *gin.Context) {
+	name := c.Param("name")
+	team, err := h.teamService.GetTeam(name)
+	if err != nil {
+		h.logger.Error("获取团队失败", zap.Error(err), zap.String("team", name))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取团队失败"})
+		return
+	}
+	if team == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "团队不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// SaveTeam 创建或更新团队（成员整体替换）
+// PUT /api/teams/:name
+// Body: {"description": "后端组", "members": [{"email": "a@example.com", "name": "A"}]}
+func (h *TeamHandler) SaveTeam(c *gin.Context) {
+	var req struct {
+		Description string `json:"description"`
+		Members     []struct {
+			Email string `json:"email" binding:"required"`
+			Name  string `json:"name"`
+		} `json:"members"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("解析请求失败", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	members := make([]model.TeamMember, 0, len(req.Members))
+	for _, m := range req.Members {
+		members = append(members, model.TeamMember{
+			MemberEmail: m.Email,
+			MemberName:  m.Name,
+		})
+	}
+
+	team, err := h.teamService.SaveTeam(c.Param("name"), req.Description, members)
+	if err != nil {
+		h.logger.Error("保存团队失败", zap.Error(err), zap.String("team", c.Param("name")))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存团队失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// DeleteTeam 删除团队
+// DELETE /api/teams/:name
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.teamService.DeleteTeam(name); err != nil {
+		h.logger.Error("删除团队失败", zap.Error(err), zap.String("team", name))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除团队失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "团队已删除", "team": name})
+}
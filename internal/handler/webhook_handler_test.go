@@ -17,7 +17,7 @@ func TestWebhookHandler_Test(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 
 	// 创建 handler
-	handler := NewWebhookHandler(logger)
+	handler := NewWebhookHandler(nil, nil, "", logger)
 
 	// 创建测试请求
 	req, _ := http.NewRequest("GET", "/webhook/test", nil)
@@ -2,24 +2,34 @@ package model
 
 import (
 	"time"
+)
 
-	"gorm.io/gorm"
+// 聚合周期类型
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
 )
 
 // MemberContribution 成员贡献聚合统计表
+// 每行对应 (成员, 平台实例上的项目, 周期) 的累计值，周期区间为 [StartDate, EndDate)
 type MemberContribution struct {
 	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
-	MemberEmail  string    `gorm:"type:varchar(255);not null;index" json:"member_email"`
+	MemberEmail  string    `gorm:"type:varchar(255);not null;index;uniqueIndex:idx_member_contributions_unique,priority:1" json:"member_email"`
 	MemberName   string    `gorm:"type:varchar(255)" json:"member_name"`
-	ProjectID    *int      `gorm:"type:integer;index" json:"project_id"`
+	ProjectID    int       `gorm:"type:integer;not null;default:0;index;uniqueIndex:idx_member_contributions_unique,priority:4" json:"project_id"` // 没有项目信息时为 0
+	Platform     string    `gorm:"type:varchar(20);not null;default:'gitlab';uniqueIndex:idx_member_contributions_unique,priority:5" json:"platform"`
+	Instance     string    `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_member_contributions_unique,priority:6" json:"instance"`
 	ProjectName  string    `gorm:"type:varchar(255)" json:"project_name"`
-	StartDate    time.Time `gorm:"type:date;not null;index:idx_period" json:"start_date"`
-	EndDate      time.Time `gorm:"type:date;not null;index:idx_period" json:"end_date"`
+	PeriodType   string    `gorm:"type:varchar(10);not null;default:'day';index:idx_member_contributions_period;uniqueIndex:idx_member_contributions_unique,priority:2" json:"period_type"`
+	StartDate    time.Time `gorm:"type:date;not null;index:idx_member_contributions_period;uniqueIndex:idx_member_contributions_unique,priority:3" json:"start_date"`
+	EndDate      time.Time `gorm:"type:date;not null;index:idx_member_contributions_period" json:"end_date"`
 	CommitCount  int       `gorm:"type:integer;default:0" json:"commit_count"`
 	Additions    int       `gorm:"type:integer;default:0" json:"additions"`
 	Deletions    int       `gorm:"type:integer;default:0" json:"deletions"`
-	NetLines     int       `gorm:"type:integer;generatedAlwaysAs:(additions - deletions) stored" json:"net_lines"`
-	TotalChanges int       `gorm:"type:integer;generatedAlwaysAs:(additions + deletions) stored" json:"total_changes"`
+	FileCount    int       `gorm:"type:integer;default:0" json:"file_count"`
+	NetLines     int       `gorm:"->;type:integer" json:"net_lines"`     // 002 迁移中的生成列 additions - deletions，只读
+	TotalChanges int       `gorm:"->;type:integer" json:"total_changes"` // 002 迁移中的生成列 additions + deletions，只读
 	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -30,16 +40,21 @@ func (MemberContribution) TableName() string {
 }
 
 // MemberLanguageStat 成员语言统计表
+// 每行对应 (成员, 语言, 平台实例上的项目, 周期) 的累计值，周期区间为 [PeriodStart, PeriodEnd)
 type MemberLanguageStat struct {
 	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
-	MemberEmail string    `gorm:"type:varchar(255);not null;index" json:"member_email"`
-	Language    string    `gorm:"type:varchar(100);not null;index" json:"language"`
+	MemberEmail string    `gorm:"type:varchar(255);not null;index;uniqueIndex:idx_member_language_stats_unique,priority:1" json:"member_email"`
+	Language    string    `gorm:"type:varchar(100);not null;index;uniqueIndex:idx_member_language_stats_unique,priority:2" json:"language"`
 	LinesAdded  int       `gorm:"type:integer;default:0" json:"lines_added"`
 	LinesRemoved int      `gorm:"type:integer;default:0" json:"lines_removed"`
 	FileCount   int       `gorm:"type:integer;default:0" json:"file_count"`
-	PeriodStart time.Time `gorm:"type:date;not null;index:idx_period" json:"period_start"`
-	PeriodEnd   time.Time `gorm:"type:date;not null;index:idx_period" json:"period_end"`
-	ProjectID   *int      `gorm:"type:integer" json:"project_id"`
+	CommitCount int       `gorm:"type:integer;default:0" json:"commit_count"`
+	PeriodType  string    `gorm:"type:varchar(10);not null;default:'day';index:idx_member_language_stats_period;uniqueIndex:idx_member_language_stats_unique,priority:3" json:"period_type"`
+	PeriodStart time.Time `gorm:"type:date;not null;index:idx_member_language_stats_period;uniqueIndex:idx_member_language_stats_unique,priority:4" json:"period_start"`
+	PeriodEnd   time.Time `gorm:"type:date;not null;index:idx_member_language_stats_period" json:"period_end"`
+	ProjectID   int       `gorm:"type:integer;not null;default:0;uniqueIndex:idx_member_language_stats_unique,priority:5" json:"project_id"` // 没有项目信息时为 0
+	Platform    string    `gorm:"type:varchar(20);not null;default:'gitlab';uniqueIndex:idx_member_language_stats_unique,priority:6" json:"platform"`
+	Instance    string    `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_member_language_stats_unique,priority:7" json:"instance"`
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -49,46 +64,19 @@ func (MemberLanguageStat) TableName() string {
 	return "member_language_stats"
 }
 
-// BeforeCreate 创建前钩子 - 确保唯一性
-func (m *MemberContribution) BeforeCreate(tx *gorm.DB) error {
-	var count int64
-	query := tx.Model(&MemberContribution{}).
-		Where("member_email = ?", m.MemberEmail).
-		Where("start_date = ?", m.StartDate).
-		Where("end_date = ?", m.EndDate)
-	
-	if m.ProjectID != nil {
-		query = query.Where("project_id = ?", *m.ProjectID)
-	} else {
-		query = query.Where("project_id IS NULL")
-	}
-	
-	query.Count(&count)
-	if count > 0 {
-		return gorm.ErrDuplicatedKey
-	}
-	return nil
+// RollupState 聚合表状态（单行，ID 固定为 1）
+// 聚合表只在完整重建（cmd/rollup -all）后与明细一致，此后新提交入库时增量累加；
+// 重新解析提交说明、重新分类文件、重新分组等批量修改明细的操作会将其标记为未就绪，
+// 未就绪期间统计查询回退到扫描明细表，直到再次完整重建
+type RollupState struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Ready     bool       `gorm:"not null;default:false" json:"ready"`
+	Reason    string     `gorm:"type:varchar(255)" json:"reason"` // 最近一次标记为未就绪的原因
+	BuiltAt   *time.Time `json:"built_at"`                        // 最近一次完整重建的时间
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
-// BeforeCreate 创建前钩子 - 确保唯一性
-func (m *MemberLanguageStat) BeforeCreate(tx *gorm.DB) error {
-	var count int64
-	query := tx.Model(&MemberLanguageStat{}).
-		Where("member_email = ?", m.MemberEmail).
-		Where("language = ?", m.Language).
-		Where("period_start = ?", m.PeriodStart).
-		Where("period_end = ?", m.PeriodEnd)
-	
-	if m.ProjectID != nil {
-		query = query.Where("project_id = ?", *m.ProjectID)
-	} else {
-		query = query.Where("project_id IS NULL")
-	}
-	
-	query.Count(&count)
-	if count > 0 {
-		return gorm.ErrDuplicatedKey
-	}
-	return nil
+// TableName 指定表名
+func (RollupState) TableName() string {
+	return "rollup_state"
 }
-
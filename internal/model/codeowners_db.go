@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// CodeOwners 来源
+const (
+	CodeOwnersSourceAPI   = "api"   // 通过平台 API 拉取
+	CodeOwnersSourceLocal = "local" // 从本地仓库镜像读取
+)
+
+// CodeOwnersFile 项目 CODEOWNERS 文件快照数据库模型（每个项目保留最新一份）
+type CodeOwnersFile struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProjectID   *int      `gorm:"type:integer;index" json:"project_id"`
+	ProjectPath string    `gorm:"type:varchar(500);not null;uniqueIndex" json:"project_path"`
+	Source      string    `gorm:"type:varchar(20);not null" json:"source"`
+	FilePath    string    `gorm:"type:varchar(255);not null" json:"file_path"` // 文件在仓库中的位置
+	Ref         string    `gorm:"type:varchar(255)" json:"ref"`
+	Content     string    `gorm:"type:text;not null" json:"content"`
+	FetchedAt   time.Time `gorm:"type:timestamp;not null" json:"fetched_at"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CodeOwnersFile) TableName() string {
+	return "codeowners_files"
+}
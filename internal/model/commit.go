@@ -6,6 +6,8 @@ import "time"
 type CommitRecord struct {
 	CommitID       string            `json:"commit_id"`
 	ProjectID      *int              `json:"project_id,omitempty"`
+	Platform       string            `json:"platform,omitempty"` // gitlab / github / gitee，为空时按 gitlab 处理
+	Instance       string            `json:"instance,omitempty"` // 平台实例主机名
 	Message        string            `json:"message"`
 	Title          string            `json:"title,omitempty"`
 	Timestamp      string            `json:"timestamp"`
@@ -36,17 +38,12 @@ type CommitRecord struct {
 	RepositoryGitSSHURL    string    `json:"repository_git_ssh_url,omitempty"`
 	RepositoryGitHTTPURL   string    `json:"repository_git_http_url,omitempty"`
 	RepositoryVisibilityLevel *int   `json:"repository_visibility_level,omitempty"`
-	// 推送信息
-	BeforeSHA              string    `json:"before_sha,omitempty"`
-	AfterSHA               string    `json:"after_sha,omitempty"`
-	CheckoutSHA            string    `json:"checkout_sha,omitempty"`
-	PushMessage            string    `json:"push_message,omitempty"`
-	TotalCommitsCount      int       `json:"total_commits_count,omitempty"`
-	// 推送用户信息（推送者，可能与提交作者不同）
-	PushUserID             *int      `json:"push_user_id,omitempty"`
-	PushUserName           string    `json:"push_user_name,omitempty"`
-	PushUserUsername       string    `json:"push_user_username,omitempty"`
-	PushUserEmail          string    `json:"push_user_email,omitempty"`
+	// PushID 所属推送（webhook 入库时先记录推送事件），导入的提交为空
+	PushID         *uint64           `json:"push_id,omitempty"`
+	// ParentIDs 父提交 SHA（可选，导入时从 API 获取），为空表示未知
+	ParentIDs      []string          `json:"parent_ids,omitempty"`
+	// PatchID 补丁标识（可选，导入时根据 diff 计算），用于识别 cherry-pick 等重复变更
+	PatchID        string            `json:"patch_id,omitempty"`
 	AddedFiles     []string          `json:"added_files"`
 	ModifiedFiles  []string          `json:"modified_files"`
 	RemovedFiles   []string          `json:"removed_files"`
@@ -58,5 +55,7 @@ type CommitRecord struct {
 type FileStat struct {
 	AddedLines   int `json:"added_lines"`
 	RemovedLines int `json:"removed_lines"`
+	// Head 文件开头内容（可选，从 diff 还原），用于 shebang 等基于内容的语言判断
+	Head string `json:"head,omitempty"`
 }
 
@@ -1,19 +1,48 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// DefaultPlatform 未指定平台时（历史数据、旧版接口）使用的平台
+const DefaultPlatform = "gitlab"
+
 // Commit 提交记录数据库模型
+// 同一提交以 (commit_id, project_id, platform, instance) 唯一，不同平台或实例的项目 ID 可能相同
 type Commit struct {
 	ID               uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
-	CommitID         string    `gorm:"type:varchar(255);not null;index:idx_commits_commit_project,unique" json:"commit_id"`
-	ProjectID        *int      `gorm:"type:integer;index;index:idx_commits_commit_project,unique" json:"project_id"`
+	CommitID         string    `gorm:"type:varchar(255);not null;index:idx_commits_identity,unique" json:"commit_id"`
+	ProjectID        *int      `gorm:"type:integer;index;index:idx_commits_identity,unique" json:"project_id"`
+	Platform         string    `gorm:"type:varchar(20);not null;default:'gitlab';index;index:idx_commits_identity,unique" json:"platform"`
+	Instance         string    `gorm:"type:varchar(255);not null;default:'';index:idx_commits_identity,unique" json:"instance"` // 平台实例主机名，如 gitlab.example.com
 	Message          string    `gorm:"type:text;not null" json:"message"`
 	Title            string    `gorm:"type:varchar(255)" json:"title"`
+	// Conventional Commits 解析结果（CommitType 为空表示不符合规范）
+	CommitType       string    `gorm:"type:varchar(50);index" json:"commit_type"`
+	CommitScope      string    `gorm:"type:varchar(100)" json:"commit_scope"`
+	IsBreaking       bool      `gorm:"type:boolean;default:false" json:"is_breaking"`
+	Trailers         Trailers  `gorm:"type:text" json:"trailers,omitempty"`
+	// 父提交（导入时从 API 获取，webhook 不提供），父提交多于一个或提交说明符合合并格式时视为合并提交
+	ParentSHAs       SHAList   `gorm:"type:text" json:"parent_shas,omitempty"`
+	IsMerge          bool      `gorm:"type:boolean;default:false;index" json:"is_merge"`
+	// 等价提交分组：cherry-pick、rebase 或推送到 fork 的同一逻辑变更共享 ChangeKey，统计时只计一次
+	PatchID          string    `gorm:"type:varchar(40);index" json:"patch_id,omitempty"`
+	CherryPickedFrom string    `gorm:"type:varchar(64);index" json:"cherry_picked_from,omitempty"`
+	ChangeKey        string    `gorm:"type:varchar(128);index" json:"change_key"`
+	DuplicateOf      *uint64   `gorm:"type:bigint;index" json:"duplicate_of,omitempty"` // 同组最早入库的提交 ID，为空表示本身即代表提交
+	// 回滚关系：RevertsSHA 为提交说明中声明的被回滚提交，RevertOf / RevertedBy 为同一项目内已关联的提交 ID
+	IsRevert         bool      `gorm:"type:boolean;default:false;index" json:"is_revert"`
+	RevertsSHA       string    `gorm:"type:varchar(64);index" json:"reverts_sha,omitempty"`
+	RevertOf         *uint64   `gorm:"type:bigint;index" json:"revert_of,omitempty"`
+	RevertedBy       *uint64   `gorm:"type:bigint;index" json:"reverted_by,omitempty"`
 	Timestamp        time.Time `gorm:"type:timestamp;not null;index" json:"timestamp"` // 保持向后兼容
+	AuthorTZOffset   *int      `gorm:"type:integer" json:"author_tz_offset"`               // 作者时区相对 UTC 的偏移（分钟）
 	Author           string    `gorm:"type:varchar(255);not null" json:"author"`
 	AuthorEmail      string    `gorm:"type:varchar(255);not null;index" json:"author_email"`
 	CommitterName    string    `gorm:"type:varchar(255)" json:"committer_name"`
@@ -24,43 +53,28 @@ type Commit struct {
 	RefProtected    *bool     `gorm:"type:boolean;default:false;index" json:"ref_protected"`
 	URL              string    `gorm:"type:text" json:"url"`
 	ProjectName      string    `gorm:"type:varchar(255);not null;index" json:"project_name"`
-	ProjectPath      string    `gorm:"type:varchar(500);not null" json:"project_path"`
-	// 推送用户信息（推送者，可能与提交作者不同）
-	PushUserID       *int      `gorm:"type:integer;index" json:"push_user_id"`
-	PushUserName     string    `gorm:"type:varchar(255)" json:"push_user_name"`
-	PushUserUsername string    `gorm:"type:varchar(255);index" json:"push_user_username"`
-	PushUserEmail    string    `gorm:"type:varchar(255)" json:"push_user_email"`
-	// 推送相关字段
-	BeforeSHA        string    `gorm:"type:varchar(40)" json:"before_sha"`
-	AfterSHA         string    `gorm:"type:varchar(40)" json:"after_sha"`
-	CheckoutSHA      string    `gorm:"type:varchar(40)" json:"checkout_sha"`
-	PushMessage      string    `gorm:"type:text" json:"push_message"`
-	TotalCommitsCount int      `gorm:"type:integer;default:0" json:"total_commits_count"`
-	// 项目扩展信息
-	ProjectDescription   string    `gorm:"type:text" json:"project_description"`
-	ProjectWebURL         string    `gorm:"type:text" json:"project_web_url"`
+	ProjectPath      string    `gorm:"type:varchar(500);not null;index" json:"project_path"`
+	ProjectRefID     *uint64   `gorm:"type:bigint;index" json:"project_ref_id"` // projects.id，项目重命名后不变
+	// 提交时的项目路径快照（项目元数据和重命名历史见 projects 表）
 	ProjectNamespace      string    `gorm:"type:varchar(255);index" json:"project_namespace"`
-	ProjectVisibilityLevel *int     `gorm:"type:integer;index" json:"project_visibility_level"`
-	ProjectDefaultBranch  string    `gorm:"type:varchar(255)" json:"project_default_branch"`
-	ProjectGitSSHURL      string    `gorm:"type:text" json:"project_git_ssh_url"`
-	ProjectGitHTTPURL     string    `gorm:"type:text" json:"project_git_http_url"`
-	// 仓库信息
-	RepositoryName         string    `gorm:"type:varchar(255)" json:"repository_name"`
-	RepositoryURL          string    `gorm:"type:text" json:"repository_url"`
-	RepositoryDescription  string    `gorm:"type:text" json:"repository_description"`
-	RepositoryHomepage     string    `gorm:"type:text" json:"repository_homepage"`
-	RepositoryGitSSHURL    string    `gorm:"type:text" json:"repository_git_ssh_url"`
-	RepositoryGitHTTPURL   string    `gorm:"type:text" json:"repository_git_http_url"`
-	RepositoryVisibilityLevel *int   `gorm:"type:integer" json:"repository_visibility_level"`
+	AuthorCount      int       `gorm:"type:integer;not null;default:1" json:"author_count"` // 作者数（提交作者 + 共同作者）
 	TotalAddedLines  int       `gorm:"type:integer;default:0" json:"total_added_lines"`
 	TotalRemovedLines int      `gorm:"type:integer;default:0" json:"total_removed_lines"`
 	TotalChangedFiles int      `gorm:"type:integer;default:0" json:"total_changed_files"`
+	// 生成代码、第三方代码和锁文件的变更，不计入上面的总计
+	ExcludedAddedLines   int `gorm:"type:integer;default:0" json:"excluded_added_lines"`
+	ExcludedRemovedLines int `gorm:"type:integer;default:0" json:"excluded_removed_lines"`
+	ExcludedChangedFiles int `gorm:"type:integer;default:0" json:"excluded_changed_files"`
 	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
 	// 关联关系
 	Files    []CommitFile    `gorm:"foreignKey:CommitID;references:ID;constraint:OnDelete:CASCADE" json:"files,omitempty"`
 	Languages []CommitLanguage `gorm:"foreignKey:CommitID;references:ID;constraint:OnDelete:CASCADE" json:"languages,omitempty"`
+	References []CommitReference `gorm:"foreignKey:CommitID;references:ID;constraint:OnDelete:CASCADE" json:"references,omitempty"`
+	Branches   []CommitBranch    `gorm:"foreignKey:CommitID;references:ID;constraint:OnDelete:CASCADE" json:"branches,omitempty"`
+	Authors    []CommitAuthor    `gorm:"foreignKey:CommitID;references:ID;constraint:OnDelete:CASCADE" json:"authors,omitempty"`
+	Project    *Project          `gorm:"foreignKey:ProjectRefID;references:ID" json:"project,omitempty"`
 }
 
 // TableName 指定表名
@@ -68,6 +82,71 @@ func (Commit) TableName() string {
 	return "commits"
 }
 
+// Trailers 提交说明末尾的 trailer（如 Signed-off-by、Refs），以 JSON 文本存储
+type Trailers map[string][]string
+
+// Value 实现 driver.Valuer
+func (t Trailers) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner
+func (t *Trailers) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为 Trailers", value)
+	}
+	if len(data) == 0 {
+		*t = nil
+		return nil
+	}
+	return json.Unmarshal(data, t)
+}
+
+// SHAList 提交 SHA 列表，以空格分隔的文本存储
+type SHAList []string
+
+// Value 实现 driver.Valuer
+func (l SHAList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	return strings.Join(l, " "), nil
+}
+
+// Scan 实现 sql.Scanner
+func (l *SHAList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+	case []byte:
+		*l = strings.Fields(string(v))
+	case string:
+		*l = strings.Fields(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为 SHAList", value)
+	}
+	if len(*l) == 0 {
+		*l = nil
+	}
+	return nil
+}
+
 // CommitFile 文件变更数据库模型
 type CommitFile struct {
 	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -79,9 +158,18 @@ type CommitFile struct {
 	AddedLines   int       `gorm:"type:integer;default:0" json:"added_lines"`
 	RemovedLines int       `gorm:"type:integer;default:0" json:"removed_lines"`
 	Language     string    `gorm:"type:varchar(50);index" json:"language"`
+	// Classification 文件分类：generated / vendored / lockfile，空字符串表示普通文件
+	Classification string  `gorm:"type:varchar(20);default:'';index" json:"classification"`
+	// Category 文件类别：production / test / docs / config / build
+	Category     string    `gorm:"type:varchar(20);default:'';index" json:"category"`
 	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
+// Excluded 文件是否被排除在行数统计之外
+func (f *CommitFile) Excluded() bool {
+	return f.Classification != ""
+}
+
 // TableName 指定表名
 func (CommitFile) TableName() string {
 	return "commit_files"
@@ -103,11 +191,45 @@ func (CommitLanguage) TableName() string {
 	return "commit_languages"
 }
 
+// CommitBranch 提交出现过的分支（同一提交可能先推送到功能分支，合并后再出现在默认分支）
+type CommitBranch struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	CommitID    uint64    `gorm:"type:bigint;not null;uniqueIndex:idx_commit_branches_commit_branch" json:"commit_id"`
+	Branch      string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_commit_branches_commit_branch;index" json:"branch"`
+	IsDefault   bool      `gorm:"type:boolean;default:false;index" json:"is_default"` // 推送时是否为项目默认分支
+	FirstSeenAt time.Time `gorm:"type:timestamp;not null" json:"first_seen_at"`
+}
+
+// TableName 指定表名
+func (CommitBranch) TableName() string {
+	return "commit_branches"
+}
+
+// CommitAuthor 提交的作者（提交作者本人及 Co-authored-by 声明的共同作者，每人一行）
+// 按成员统计时按此表归属提交，split 模式下行数在 commits.author_count 位作者之间均分
+type CommitAuthor struct {
+	ID        uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	CommitID  uint64 `gorm:"type:bigint;not null;uniqueIndex:idx_commit_authors_commit_email" json:"commit_id"`
+	Email     string `gorm:"type:varchar(255);not null;uniqueIndex:idx_commit_authors_commit_email;index" json:"email"`
+	Name      string `gorm:"type:varchar(255)" json:"name"`
+	IsPrimary bool   `gorm:"type:boolean;default:false" json:"is_primary"` // 是否为提交作者（余数行计入提交作者）
+}
+
+// TableName 指定表名
+func (CommitAuthor) TableName() string {
+	return "commit_authors"
+}
+
 // BeforeCreate 创建前钩子
 func (c *Commit) BeforeCreate(tx *gorm.DB) error {
-	// 确保 (commit_id, project_id) 组合唯一
+	// 确保 (commit_id, project_id, platform, instance) 组合唯一
+	if c.Platform == "" {
+		c.Platform = DefaultPlatform
+	}
 	var count int64
-	query := tx.Model(&Commit{}).Where("commit_id = ?", c.CommitID)
+	query := tx.Model(&Commit{}).
+		Where("commit_id = ?", c.CommitID).
+		Where("platform = ? AND instance = ?", c.Platform, c.Instance)
 	if c.ProjectID != nil {
 		query = query.Where("project_id = ?", *c.ProjectID)
 	} else {
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// DataMigration 已执行的一次性数据修正（表结构由 AutoMigrate 维护，历史数据的修正记录在这里，避免重复执行）
+type DataMigration struct {
+	Name      string    `gorm:"type:varchar(100);primaryKey" json:"name"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+// TableName 指定表名
+func (DataMigration) TableName() string {
+	return "data_migrations"
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// ProjectFileRule 项目级文件分类规则数据库模型
+// 优先于内置规则，用于把额外的路径标记为生成/第三方/锁文件，或把误判的路径恢复为普通文件
+type ProjectFileRule struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProjectPath string    `gorm:"type:varchar(500);not null;index" json:"project_path"`
+	Pattern     string    `gorm:"type:varchar(500);not null" json:"pattern"`
+	Class       string    `gorm:"type:varchar(20)" json:"class"`          // generated | vendored | lockfile，空字符串表示普通文件
+	Position    int       `gorm:"type:integer;default:0" json:"position"` // 规则顺序，靠后的优先
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ProjectFileRule) TableName() string {
+	return "project_file_rules"
+}
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// Project 项目数据库模型（每个平台实例上的项目一行，每次推送或导入时更新元数据）
+// 项目重命名或迁移命名空间后 ID 不变，提交通过 commits.project_ref_id 关联，历史不会按路径拆分
+type Project struct {
+	ID         uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	Platform   string `gorm:"type:varchar(20);not null;default:'gitlab';uniqueIndex:idx_projects_identity" json:"platform"`
+	Instance   string `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_projects_identity" json:"instance"`
+	ExternalID int    `gorm:"type:integer;not null;uniqueIndex:idx_projects_identity" json:"project_id"` // 平台上的项目 ID
+	// 当前路径和名称（重命名后更新，历史路径见 Paths）
+	Path        string `gorm:"type:varchar(500);not null;index" json:"path"`
+	Name        string `gorm:"type:varchar(255)" json:"name"`
+	Namespace   string `gorm:"type:varchar(255);index" json:"namespace"`
+	Description string `gorm:"type:text" json:"description"`
+	WebURL      string `gorm:"type:text" json:"web_url"`
+	// 项目扩展信息
+	VisibilityLevel *int   `gorm:"type:integer" json:"visibility_level"`
+	DefaultBranch   string `gorm:"type:varchar(255)" json:"default_branch"`
+	GitSSHURL       string `gorm:"type:text" json:"git_ssh_url"`
+	GitHTTPURL      string `gorm:"type:text" json:"git_http_url"`
+	// 仓库信息
+	RepositoryName            string    `gorm:"type:varchar(255)" json:"repository_name"`
+	RepositoryURL             string    `gorm:"type:text" json:"repository_url"`
+	RepositoryDescription     string    `gorm:"type:text" json:"repository_description"`
+	RepositoryHomepage        string    `gorm:"type:text" json:"repository_homepage"`
+	RepositoryGitSSHURL       string    `gorm:"type:text" json:"repository_git_ssh_url"`
+	RepositoryGitHTTPURL      string    `gorm:"type:text" json:"repository_git_http_url"`
+	RepositoryVisibilityLevel *int      `gorm:"type:integer" json:"repository_visibility_level"`
+	LastEventAt               time.Time `gorm:"type:timestamp;not null" json:"last_event_at"` // 最近一次推送或导入的时间
+	CreatedAt                 time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt                 time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	Paths []ProjectPathHistory `gorm:"foreignKey:ProjectRefID;references:ID;constraint:OnDelete:CASCADE" json:"paths,omitempty"`
+}
+
+// TableName 指定表名
+func (Project) TableName() string {
+	return "projects"
+}
+
+// ProjectPathHistory 项目使用过的路径（含当前路径），按旧路径查询时用于找到同一项目
+type ProjectPathHistory struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProjectRefID uint64    `gorm:"type:bigint;not null;uniqueIndex:idx_project_paths_project_path" json:"project_ref_id"` // projects.id
+	Path         string    `gorm:"type:varchar(500);not null;uniqueIndex:idx_project_paths_project_path;index" json:"path"`
+	Namespace    string    `gorm:"type:varchar(255);index" json:"namespace"`
+	FirstSeenAt  time.Time `gorm:"type:timestamp;not null" json:"first_seen_at"`
+	LastSeenAt   time.Time `gorm:"type:timestamp;not null" json:"last_seen_at"`
+}
+
+// TableName 指定表名
+func (ProjectPathHistory) TableName() string {
+	return "project_paths"
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// PushRecord 推送事件记录（一次推送一条，不含提交内容）
+type PushRecord struct {
+	Platform          string `json:"platform,omitempty"`
+	Instance          string `json:"instance,omitempty"`
+	ProjectID         *int   `json:"project_id,omitempty"`
+	ProjectPath       string `json:"project_path"`
+	Ref               string `json:"ref"` // 分支名，标签推送时为完整引用（refs/tags/...）
+	RefProtected      *bool  `json:"ref_protected,omitempty"`
+	BeforeSHA         string `json:"before_sha,omitempty"`
+	AfterSHA          string `json:"after_sha,omitempty"`
+	CheckoutSHA       string `json:"checkout_sha,omitempty"`
+	Message           string `json:"message,omitempty"`
+	TotalCommitsCount int    `json:"total_commits_count"` // 平台报告的提交总数（可能多于负载中包含的提交）
+	// 推送者（可能与提交作者不同）
+	PusherID       *int      `json:"pusher_id,omitempty"`
+	PusherName     string    `json:"pusher_name,omitempty"`
+	PusherUsername string    `json:"pusher_username,omitempty"`
+	PusherEmail    string    `json:"pusher_email,omitempty"`
+	PushedAt       time.Time `json:"pushed_at"` // 为空时记为处理时间
+}
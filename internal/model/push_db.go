@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Push 推送事件数据库模型（每次推送一行，不推送新提交的推送如删除分支也会记录）
+// 同一推送以 (platform, instance, project_id, ref, before_sha, after_sha) 唯一，webhook 重投不会重复记录；
+// 项目 ID 未知时记为 0（NULL 不受唯一索引约束）
+type Push struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Platform          string    `gorm:"type:varchar(20);not null;default:'gitlab';uniqueIndex:idx_pushes_identity" json:"platform"`
+	Instance          string    `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_pushes_identity" json:"instance"`
+	ProjectID         int       `gorm:"type:integer;not null;default:0;uniqueIndex:idx_pushes_identity" json:"project_id"`
+	ProjectPath       string    `gorm:"type:varchar(500);not null;index" json:"project_path"` // 推送时的项目路径
+	Ref               string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_pushes_identity" json:"ref"`
+	RefProtected      bool      `gorm:"type:boolean;default:false;index" json:"ref_protected"`
+	BeforeSHA         string    `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_pushes_identity" json:"before_sha"`
+	AfterSHA          string    `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_pushes_identity" json:"after_sha"`
+	CheckoutSHA       string    `gorm:"type:varchar(64)" json:"checkout_sha"`
+	Message           string    `gorm:"type:text" json:"message"`
+	TotalCommitsCount int       `gorm:"type:integer;default:0" json:"total_commits_count"`
+	PusherID          *int      `gorm:"type:integer;index" json:"pusher_id"`
+	PusherName        string    `gorm:"type:varchar(255)" json:"pusher_name"`
+	PusherUsername    string    `gorm:"type:varchar(255);index" json:"pusher_username"`
+	PusherEmail       string    `gorm:"type:varchar(255);index" json:"pusher_email"`
+	PushedAt          time.Time `gorm:"type:timestamp;not null;index" json:"pushed_at"` // 服务处理推送事件的时间（推送 webhook 不带推送时间），事件延迟投递时晚于实际推送
+	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (Push) TableName() string {
+	return "pushes"
+}
+
+// PushCommit 推送与提交的关联（同一提交合并或 cherry-pick 到其他分支时会出现在多次推送中）
+type PushCommit struct {
+	ID       uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	PushID   uint64 `gorm:"type:bigint;not null;uniqueIndex:idx_push_commits_push_commit" json:"push_id"`
+	CommitID uint64 `gorm:"type:bigint;not null;uniqueIndex:idx_push_commits_push_commit;index" json:"commit_id"` // commits.id
+}
+
+// TableName 指定表名
+func (PushCommit) TableName() string {
+	return "push_commits"
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// CommitReference 提交引用的 issue / 合并请求 / 外部工单数据库模型
+type CommitReference struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	CommitID   uint64    `gorm:"type:bigint;not null;index" json:"commit_id"`
+	RefType    string    `gorm:"type:varchar(20);not null" json:"ref_type"`       // issue | merge_request | external
+	RefKey     string    `gorm:"type:varchar(500);not null;index" json:"ref_key"` // 规范化后的键，如 group/project#123、ABC-123
+	RefProject string    `gorm:"type:varchar(500)" json:"ref_project,omitempty"`  // 引用中显式指定的项目
+	RefNumber  string    `gorm:"type:varchar(50)" json:"ref_number,omitempty"`
+	IsClosing  bool      `gorm:"type:boolean;default:false" json:"is_closing"`
+	Source     string    `gorm:"type:varchar(20);not null" json:"source"` // message | branch
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (CommitReference) TableName() string {
+	return "commit_references"
+}
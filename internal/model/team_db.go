@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Team 团队数据库模型
+type Team struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	Members []TeamMember `gorm:"foreignKey:TeamID;references:ID;constraint:OnDelete:CASCADE" json:"members,omitempty"`
+}
+
+// TableName 指定表名
+func (Team) TableName() string {
+	return "teams"
+}
+
+// TeamMember 团队成员数据库模型（成员以提交邮箱标识）
+type TeamMember struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TeamID      uint64    `gorm:"type:bigint;not null;uniqueIndex:idx_team_members_team_email" json:"team_id"`
+	MemberEmail string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_team_members_team_email;index" json:"member_email"`
+	MemberName  string    `gorm:"type:varchar(255)" json:"member_name"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (TeamMember) TableName() string {
+	return "team_members"
+}
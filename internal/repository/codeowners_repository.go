@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"fmt"
+
+	"gitlab-webhook-server/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CodeOwnersRepository CODEOWNERS 仓库
+type CodeOwnersRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewCodeOwnersRepository 创建新的 CODEOWNERS 仓库
+func NewCodeOwnersRepository(db *gorm.DB, logger *zap.Logger) *CodeOwnersRepository {
+	return &CodeOwnersRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Save 保存项目的 CODEOWNERS 快照（按 project_path 覆盖）
+func (r *CodeOwnersRepository) Save(file *model.CodeOwnersFile) error {
+	var existing model.CodeOwnersFile
+	err := r.db.Where("project_path = ?", file.ProjectPath).First(&existing).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		if err := r.db.Create(file).Error; err != nil {
+			return fmt.Errorf("保存 CODEOWNERS 失败: %w", err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("查询 CODEOWNERS 失败: %w", err)
+	}
+
+	file.ID = existing.ID
+	file.CreatedAt = existing.CreatedAt
+	if err := r.db.Save(file).Error; err != nil {
+		return fmt.Errorf("更新 CODEOWNERS 失败: %w", err)
+	}
+	return nil
+}
+
+// Get 获取项目的 CODEOWNERS 快照，projectPath 优先；不存在时返回 nil
+func (r *CodeOwnersRepository) Get(projectID *int, projectPath string) (*model.CodeOwnersFile, error) {
+	query := r.db.Model(&model.CodeOwnersFile{})
+	switch {
+	case projectPath != "":
+		query = query.Where("project_path = ?", projectPath)
+	case projectID != nil:
+		query = query.Where("project_id = ?", *projectID)
+	default:
+		return nil, fmt.Errorf("project_id 或 project_path 必须指定一个")
+	}
+
+	var file model.CodeOwnersFile
+	if err := query.First(&file).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询 CODEOWNERS 失败: %w", err)
+	}
+	return &file, nil
+}
+
+// GetUsernameEmails 根据推送记录获取用户名对应的提交邮箱
+func (r *CodeOwnersRepository) GetUsernameEmails(usernames []string) (map[string][]string, error) {
+	result := make(map[string][]string, len(usernames))
+	if len(usernames) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		PusherUsername string
+		PusherEmail    string
+	}
+	if err := r.db.Model(&model.Push{}).
+		Distinct("pusher_username", "pusher_email").
+		Where("pusher_username IN ?", usernames).
+		Where("pusher_email <> ''").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询用户名邮箱映射失败: %w", err)
+	}
+
+	for _, row := range rows {
+		result[row.PusherUsername] = append(result[row.PusherUsername], row.PusherEmail)
+	}
+	return result, nil
+}
@@ -54,86 +54,96 @@ func (r *CommitRepository) GetCommitByCommitID(commitID string) (*model.Commit,
 	return &commit, nil
 }
 
-// GetMemberCommits 获取成员的提交记录
-func (r *CommitRepository) GetMemberCommits(
-	authorEmail string,
-	startDate, endDate *time.Time,
-) ([]*model.Commit, error) {
-	var commits []*model.Commit
-	query := r.db.Where("author_email = ?", authorEmail)
-
-	if startDate != nil {
-		query = query.Where("timestamp >= ?", *startDate)
+// FindCommitsBySHA 按 SHA 查找提交（短 SHA 按前缀匹配），按入库顺序返回
+// scope 的平台、实例、项目 ID 和项目路径条件限定项目范围，为 nil 时查找所有项目（同一 SHA 可能出现在 fork 中）
+func (r *CommitRepository) FindCommitsBySHA(sha string, scope *StatsFilter) ([]*model.Commit, error) {
+	query := r.db.Model(&model.Commit{})
+	if len(sha) < 40 {
+		query = query.Where("commits.commit_id LIKE ?", escapeLike(sha)+"%")
+	} else {
+		query = query.Where("commits.commit_id = ?", sha)
 	}
-	if endDate != nil {
-		query = query.Where("timestamp < ?", *endDate)
+	if scope != nil {
+		query = scope.conditions(query)
 	}
 
-	if err := query.Order("timestamp DESC").Find(&commits).Error; err != nil {
-		return nil, fmt.Errorf("查询成员提交记录失败: %w", err)
+	var commits []*model.Commit
+	if err := query.Order("commits.id").Find(&commits).Error; err != nil {
+		return nil, fmt.Errorf("查询提交记录失败: %w", err)
 	}
-
 	return commits, nil
 }
 
-// GetMemberStats 获取成员统计信息
-func (r *CommitRepository) GetMemberStats(
-	authorEmail string,
-	startDate, endDate *time.Time,
-) (*MemberStats, error) {
-	var stats MemberStats
-	query := r.db.Model(&model.Commit{}).Where("author_email = ?", authorEmail)
-
-	if startDate != nil {
-		query = query.Where("timestamp >= ?", *startDate)
+// GetCommitDetail 获取提交及其文件变更、语言统计、分支、引用、作者和项目记录，不存在时返回 nil
+func (r *CommitRepository) GetCommitDetail(id uint64) (*model.Commit, error) {
+	var commit model.Commit
+	err := r.db.
+		Preload("Files", func(db *gorm.DB) *gorm.DB { return db.Order("file_path") }).
+		Preload("Languages", func(db *gorm.DB) *gorm.DB { return db.Order("added_lines DESC") }).
+		Preload("Branches", func(db *gorm.DB) *gorm.DB { return db.Order("first_seen_at") }).
+		Preload("References").
+		Preload("Authors", func(db *gorm.DB) *gorm.DB { return db.Order("is_primary DESC, id") }).
+		Preload("Project").
+		Where("id = ?", id).
+		Take(&commit).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
 	}
-	if endDate != nil {
-		query = query.Where("timestamp < ?", *endDate)
+	if err != nil {
+		return nil, fmt.Errorf("查询提交详情失败: %w", err)
 	}
+	return &commit, nil
+}
 
-	if err := query.Select(
-		"COUNT(*) as commit_count",
-		"COALESCE(SUM(total_added_lines), 0) as total_added",
-		"COALESCE(SUM(total_removed_lines), 0) as total_removed",
-		"COALESCE(SUM(total_changed_files), 0) as total_files",
-	).Scan(&stats).Error; err != nil {
-		return nil, fmt.Errorf("查询成员统计失败: %w", err)
+// GetCommitPushes 获取包含指定提交的推送（按推送时间）
+func (r *CommitRepository) GetCommitPushes(commitID uint64) ([]*model.Push, error) {
+	var pushes []*model.Push
+	if err := r.db.
+		Joins("JOIN push_commits ON push_commits.push_id = pushes.id").
+		Where("push_commits.commit_id = ?", commitID).
+		Order("pushes.pushed_at").
+		Find(&pushes).Error; err != nil {
+		return nil, fmt.Errorf("查询提交的推送记录失败: %w", err)
 	}
+	return pushes, nil
+}
 
-	return &stats, nil
+// GetCommitSHAs 获取提交 ID 对应的 SHA
+func (r *CommitRepository) GetCommitSHAs(ids []uint64) (map[uint64]string, error) {
+	shas := make(map[uint64]string, len(ids))
+	if len(ids) == 0 {
+		return shas, nil
+	}
+	var commits []*model.Commit
+	if err := r.db.Select("id", "commit_id").Where("id IN ?", ids).Find(&commits).Error; err != nil {
+		return nil, fmt.Errorf("查询提交 SHA 失败: %w", err)
+	}
+	for _, c := range commits {
+		shas[c.ID] = c.CommitID
+	}
+	return shas, nil
 }
 
-// GetLanguageStats 获取语言统计信息
-func (r *CommitRepository) GetLanguageStats(
+// GetMemberCommits 获取成员的提交记录
+func (r *CommitRepository) GetMemberCommits(
 	authorEmail string,
 	startDate, endDate *time.Time,
-) ([]*LanguageStats, error) {
-	var stats []*LanguageStats
-
-	query := r.db.Table("commit_languages").
-		Select(
-			"commit_languages.language",
-			"COALESCE(SUM(commit_languages.added_lines), 0) as total_added",
-			"COALESCE(SUM(commit_languages.removed_lines), 0) as total_removed",
-			"COALESCE(SUM(commit_languages.file_count), 0) as total_files",
-		).
-		Joins("JOIN commits ON commit_languages.commit_id = commits.id").
-		Where("commits.author_email = ?", authorEmail)
+) ([]*model.Commit, error) {
+	var commits []*model.Commit
+	query := r.db.Where("author_email = ?", authorEmail)
 
 	if startDate != nil {
-		query = query.Where("commits.timestamp >= ?", *startDate)
+		query = query.Where("timestamp >= ?", startDate.UTC())
 	}
 	if endDate != nil {
-		query = query.Where("commits.timestamp < ?", *endDate)
+		query = query.Where("timestamp < ?", endDate.UTC())
 	}
 
-	if err := query.Group("commit_languages.language").
-		Order("total_added DESC").
-		Scan(&stats).Error; err != nil {
-		return nil, fmt.Errorf("查询语言统计失败: %w", err)
+	if err := query.Order("timestamp DESC").Find(&commits).Error; err != nil {
+		return nil, fmt.Errorf("查询成员提交记录失败: %w", err)
 	}
 
-	return stats, nil
+	return commits, nil
 }
 
 // MemberStats 成员统计信息
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// 提交搜索的排序字段
+const (
+	CommitSortTimestamp = "timestamp" // 提交时间（默认）
+	CommitSortLines     = "lines"     // 新增 + 删除行数
+	CommitSortAdded     = "added"     // 新增行数
+	CommitSortRemoved   = "removed"   // 删除行数
+)
+
+// commitSortColumns 排序字段对应的列表达式（行数不含生成代码、第三方代码和锁文件）
+var commitSortColumns = map[string]string{
+	CommitSortTimestamp: "commits.timestamp",
+	CommitSortLines:     "(commits.total_added_lines + commits.total_removed_lines)",
+	CommitSortAdded:     "commits.total_added_lines",
+	CommitSortRemoved:   "commits.total_removed_lines",
+}
+
+// ValidCommitSort 判断排序字段是否受支持
+func ValidCommitSort(sort string) bool {
+	_, ok := commitSortColumns[sort]
+	return ok
+}
+
+// CommitSearch 提交搜索条件
+// Filter 的 email / team 匹配提交作者及共同作者，不对等价提交去重
+type CommitSearch struct {
+	Filter   StatsFilter
+	Language string // 包含该语言文件变更的提交
+	MinLines *int   // 新增 + 删除行数下限（含）
+	MaxLines *int   // 新增 + 删除行数上限（含）
+	Query    string // 提交说明全文检索
+	Sort     string // timestamp | lines | added | removed
+	Asc      bool
+	Cursor   *CommitCursor // 上一页返回的 NextCursor 解码结果，为 nil 表示第一页
+	Limit    int
+}
+
+// CommitPage 提交搜索的一页结果
+type CommitPage struct {
+	Commits    []*model.Commit
+	NextCursor string // 为空表示没有下一页
+}
+
+// CommitCursor 游标：上一页最后一条的排序值和 ID
+type CommitCursor struct {
+	Time  *time.Time `json:"t,omitempty"`
+	Lines *int64     `json:"n,omitempty"`
+	ID    uint64     `json:"id"`
+}
+
+// encodeCommitCursor 编码游标
+func encodeCommitCursor(c *CommitCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCommitCursor 解码上一页返回的游标
+func DecodeCommitCursor(s string) (*CommitCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("cursor 无效")
+	}
+	var c CommitCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return nil, fmt.Errorf("cursor 无效")
+	}
+	return &c, nil
+}
+
+// Matches 判断游标是否由同一排序字段生成
+func (c *CommitCursor) Matches(sort string) bool {
+	if sort == CommitSortTimestamp {
+		return c.Time != nil
+	}
+	return c.Lines != nil
+}
+
+// fullTextCondition 提交说明全文检索条件（全文索引由 database.Migrate 或 022 迁移创建）
+// MySQL 使用 ngram FULLTEXT 索引（BOOLEAN MODE，支持 +、-、"..." 等运算符），
+// PostgreSQL 使用 simple 配置的 tsvector（按空白和标点分词，所有词都需出现），其他数据库退化为 LIKE
+func fullTextCondition(db *gorm.DB, text string) (string, []interface{}) {
+	switch db.Dialector.Name() {
+	case "mysql":
+		return "MATCH(commits.message) AGAINST (? IN BOOLEAN MODE)", []interface{}{text}
+	case "postgres":
+		return "to_tsvector('simple', commits.message) @@ plainto_tsquery('simple', ?)", []interface{}{text}
+	default:
+		return "commits.message LIKE ?", []interface{}{"%" + escapeLike(text) + "%"}
+	}
+}
+
+// SearchCommits 按条件搜索提交，按排序字段和 ID 分页（游标分页，翻页期间新入库的提交不会导致重复或遗漏）
+func (r *CommitRepository) SearchCommits(search *CommitSearch) (*CommitPage, error) {
+	sortColumn, ok := commitSortColumns[search.Sort]
+	if !ok {
+		return nil, fmt.Errorf("不支持的排序字段: %s", search.Sort)
+	}
+
+	scoped := search.Filter
+	scoped.AuthorEmail, scoped.Team = "", ""
+	query := scoped.conditions(r.db.Model(&model.Commit{}))
+	if email := search.Filter.AuthorEmail; email != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM commit_authors WHERE commit_authors.commit_id = commits.id AND commit_authors.email = ?)",
+			email)
+	}
+	if team := search.Filter.Team; team != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM commit_authors WHERE commit_authors.commit_id = commits.id AND commit_authors.email IN (?))",
+			teamMemberSubQuery(r.db.Session(&gorm.Session{NewDB: true}), team))
+	}
+	if search.Language != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM commit_languages WHERE commit_languages.commit_id = commits.id AND commit_languages.language = ?)",
+			search.Language)
+	}
+	lines := commitSortColumns[CommitSortLines]
+	if search.MinLines != nil {
+		query = query.Where(lines+" >= ?", *search.MinLines)
+	}
+	if search.MaxLines != nil {
+		query = query.Where(lines+" <= ?", *search.MaxLines)
+	}
+	if search.Query != "" {
+		condition, args := fullTextCondition(r.db, search.Query)
+		query = query.Where(condition, args...)
+	}
+
+	op, direction := "<", "DESC"
+	if search.Asc {
+		op, direction = ">", "ASC"
+	}
+	if cursor := search.Cursor; cursor != nil {
+		if !cursor.Matches(search.Sort) {
+			return nil, fmt.Errorf("cursor 与排序字段 %s 不匹配", search.Sort)
+		}
+		var value interface{}
+		if cursor.Time != nil {
+			value = cursor.Time.UTC()
+		} else {
+			value = *cursor.Lines
+		}
+		query = query.Where("("+sortColumn+" "+op+" ? OR ("+sortColumn+" = ? AND commits.id "+op+" ?))",
+			value, value, cursor.ID)
+	}
+
+	// 多取一条判断是否还有下一页
+	var commits []*model.Commit
+	if err := query.
+		Order(sortColumn + " " + direction).
+		Order("commits.id " + direction).
+		Limit(search.Limit + 1).
+		Find(&commits).Error; err != nil {
+		return nil, fmt.Errorf("搜索提交失败: %w", err)
+	}
+
+	page := &CommitPage{Commits: commits}
+	if len(commits) > search.Limit {
+		page.Commits = commits[:search.Limit]
+		page.NextCursor = encodeCommitCursor(commitCursorOf(page.Commits[search.Limit-1], search.Sort))
+	}
+	return page, nil
+}
+
+// commitCursorOf 生成指向该提交之后的游标
+func commitCursorOf(commit *model.Commit, sort string) *CommitCursor {
+	cursor := &CommitCursor{ID: commit.ID}
+	var lines int64
+	switch sort {
+	case CommitSortTimestamp:
+		t := commit.Timestamp
+		cursor.Time = &t
+		return cursor
+	case CommitSortLines:
+		lines = int64(commit.TotalAddedLines + commit.TotalRemovedLines)
+	case CommitSortAdded:
+		lines = int64(commit.TotalAddedLines)
+	case CommitSortRemoved:
+		lines = int64(commit.TotalRemovedLines)
+	}
+	cursor.Lines = &lines
+	return cursor
+}
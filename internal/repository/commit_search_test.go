@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+)
+
+func TestCommitCursor(t *testing.T) {
+	commit := &model.Commit{
+		ID:                42,
+		Timestamp:         time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		TotalAddedLines:   120,
+		TotalRemovedLines: 30,
+	}
+
+	cursor, err := DecodeCommitCursor(encodeCommitCursor(commitCursorOf(commit, CommitSortTimestamp)))
+	if err != nil {
+		t.Fatalf("DecodeCommitCursor: %v", err)
+	}
+	if cursor.ID != 42 || cursor.Time == nil || !cursor.Time.Equal(commit.Timestamp) {
+		t.Errorf("timestamp cursor = %+v", cursor)
+	}
+	if !cursor.Matches(CommitSortTimestamp) || cursor.Matches(CommitSortLines) {
+		t.Error("时间游标只能用于按时间排序")
+	}
+
+	cursor, err = DecodeCommitCursor(encodeCommitCursor(commitCursorOf(commit, CommitSortLines)))
+	if err != nil {
+		t.Fatalf("DecodeCommitCursor: %v", err)
+	}
+	if cursor.ID != 42 || cursor.Lines == nil || *cursor.Lines != 150 {
+		t.Errorf("lines cursor = %+v", cursor)
+	}
+	if !cursor.Matches(CommitSortRemoved) || cursor.Matches(CommitSortTimestamp) {
+		t.Error("行数游标只能用于按行数排序")
+	}
+
+	for _, bad := range []string{"not-base64!", "e30", encodeCommitCursor(&CommitCursor{})} {
+		if _, err := DecodeCommitCursor(bad); err == nil {
+			t.Errorf("DecodeCommitCursor(%q) 应返回错误", bad)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"gitlab-webhook-server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// joinAuthors 关联提交的全部作者（提交作者及 Co-authored-by 共同作者），每位作者一行
+// 每个提交至少有提交作者本人一行（历史提交由 020 迁移或 database.Migrate 回填）
+func joinAuthors(query *gorm.DB) *gorm.DB {
+	return query.Joins("JOIN commit_authors ON commit_authors.commit_id = commits.id")
+}
+
+// creditLines 按 CREDIT_MODE 计算 commit_authors 当前作者分得的行数表达式（与聚合引擎的 utils.CreditLines 一致）
+// split 模式下共同作者各得整除部分，余数计入提交作者
+func creditLines(db *gorm.DB, lines string) string {
+	if utils.CreditMode() != utils.CreditModeSplit {
+		return lines
+	}
+	share := "(" + lines + ") / commits.author_count"
+	if db.Dialector.Name() == "mysql" {
+		share = "(" + lines + ") DIV commits.author_count"
+	}
+	return "(CASE WHEN commits.author_count <= 1 THEN " + lines +
+		" WHEN commit_authors.is_primary THEN (" + lines + ") - (" + share + ") * (commits.author_count - 1)" +
+		" ELSE " + share + " END)"
+}
+
+// applyCredited 按作者统计时应用过滤条件：关联全部作者，成员和团队条件作用于作者（含共同作者）
+func (f *StatsFilter) applyCredited(query *gorm.DB) *gorm.DB {
+	scoped := *f
+	scoped.AuthorEmail, scoped.Team = "", ""
+	query = scoped.Apply(joinAuthors(query))
+	if f.AuthorEmail != "" {
+		query = query.Where("commit_authors.email = ?", f.AuthorEmail)
+	}
+	if f.Team != "" {
+		query = query.Where("commit_authors.email IN (?)",
+			teamMemberSubQuery(query.Session(&gorm.Session{NewDB: true}), f.Team))
+	}
+	return query
+}
+
+// joinCredit 按成员或团队过滤（不按成员拆分）时应用过滤条件：只保留有匹配作者（含共同作者）的提交，每个提交仍为一行
+// credit 子查询记录每个提交中匹配的作者数和是否包含提交作者，行数通过 creditedLines 计算这些作者合计分得的部分
+func (f *StatsFilter) joinCredit(query *gorm.DB) *gorm.DB {
+	authors := query.Session(&gorm.Session{NewDB: true}).Table("commit_authors").
+		Select(
+			"commit_authors.commit_id",
+			"COUNT(*) as authors",
+			"MAX(CASE WHEN commit_authors.is_primary THEN 1 ELSE 0 END) as has_primary",
+		).
+		Group("commit_authors.commit_id")
+	if f.AuthorEmail != "" {
+		authors = authors.Where("commit_authors.email = ?", f.AuthorEmail)
+	}
+	if f.Team != "" {
+		authors = authors.Where("commit_authors.email IN (?)",
+			teamMemberSubQuery(query.Session(&gorm.Session{NewDB: true}), f.Team))
+	}
+
+	scoped := *f
+	scoped.AuthorEmail, scoped.Team = "", ""
+	return scoped.Apply(query.Joins("JOIN (?) credit ON credit.commit_id = commits.id", authors))
+}
+
+// creditedLines 与 joinCredit 配合使用：匹配的作者合计分得的行数表达式
+// 与逐个作者按 creditLines 计算后求和一致，同一提交的多位团队成员不会重复计入
+func creditedLines(db *gorm.DB, lines string) string {
+	if utils.CreditMode() != utils.CreditModeSplit {
+		return lines
+	}
+	share := "(" + lines + ") / commits.author_count"
+	if db.Dialector.Name() == "mysql" {
+		share = "(" + lines + ") DIV commits.author_count"
+	}
+	return "(CASE WHEN commits.author_count <= 1 THEN " + lines +
+		" ELSE credit.authors * (" + share + ") + credit.has_primary * ((" + lines + ") - (" + share + ") * commits.author_count) END)"
+}
+
+// creditScope 按作者归属统计时应用过滤条件，返回查询和行数表达式的转换函数：
+// byMember 为 true 时每位作者（含共同作者）一行，分组键为 commit_authors.email，行数按 CREDIT_MODE 归属；
+// 否则按成员或团队过滤时每个提交一行，只计匹配作者分得的行数；没有成员条件时为普通的提交范围
+func (r *StatsRepository) creditScope(filter *StatsFilter, query *gorm.DB, byMember bool) (*gorm.DB, func(string) string) {
+	switch {
+	case byMember:
+		return filter.applyCredited(query), func(lines string) string { return creditLines(r.db, lines) }
+	case filter.AuthorEmail != "" || filter.Team != "":
+		return filter.joinCredit(query), func(lines string) string { return creditedLines(r.db, lines) }
+	default:
+		return filter.Apply(query), func(lines string) string { return lines }
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// FileRuleRepository 项目级文件分类规则仓库
+type FileRuleRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewFileRuleRepository 创建新的文件分类规则仓库
+func NewFileRuleRepository(db *gorm.DB, logger *zap.Logger) *FileRuleRepository {
+	return &FileRuleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetRules 获取项目的文件分类规则（按顺序）
+func (r *FileRuleRepository) GetRules(projectPath string) ([]utils.FileClassRule, error) {
+	var rows []model.ProjectFileRule
+	if err := r.db.Where("project_path = ?", projectPath).
+		Order("position").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询文件分类规则失败: %w", err)
+	}
+
+	rules := make([]utils.FileClassRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, utils.FileClassRule{Pattern: row.Pattern, Class: row.Class})
+	}
+	return rules, nil
+}
+
+// SaveRules 整体替换项目的文件分类规则
+func (r *FileRuleRepository) SaveRules(projectPath string, rules []utils.FileClassRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_path = ?", projectPath).Delete(&model.ProjectFileRule{}).Error; err != nil {
+			return fmt.Errorf("清理文件分类规则失败: %w", err)
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+
+		rows := make([]model.ProjectFileRule, 0, len(rules))
+		for i, rule := range rules {
+			rows = append(rows, model.ProjectFileRule{
+				ProjectPath: projectPath,
+				Pattern:     rule.Pattern,
+				Class:       rule.Class,
+				Position:    i,
+			})
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return fmt.Errorf("保存文件分类规则失败: %w", err)
+		}
+		return nil
+	})
+}
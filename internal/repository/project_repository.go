@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"gitlab-webhook-server/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ProjectFilter 项目列表过滤条件，零值表示不过滤
+type ProjectFilter struct {
+	Platform  string
+	Instance  string
+	Namespace string // 命名空间（组），包含其下所有子组的项目
+	Path      string // 当前或曾用的路径
+}
+
+// ProjectRepository 项目仓库
+type ProjectRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewProjectRepository 创建新的项目仓库
+func NewProjectRepository(db *gorm.DB, logger *zap.Logger) *ProjectRepository {
+	return &ProjectRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// List 获取项目及其路径历史（按当前路径排序）
+func (r *ProjectRepository) List(filter *ProjectFilter) ([]*model.Project, error) {
+	query := r.db.Model(&model.Project{})
+	if filter.Platform != "" {
+		query = query.Where("platform = ?", filter.Platform)
+	}
+	if filter.Instance != "" {
+		query = query.Where("instance = ?", strings.ToLower(filter.Instance))
+	}
+	if filter.Namespace != "" {
+		query = query.Where("(namespace = ? OR path LIKE ?)",
+			filter.Namespace, escapeLike(strings.TrimSuffix(filter.Namespace, "/"))+"/%")
+	}
+	if filter.Path != "" {
+		query = query.Where("id IN (?)",
+			r.db.Model(&model.ProjectPathHistory{}).Select("project_ref_id").Where("path = ?", filter.Path))
+	}
+
+	var projects []*model.Project
+	if err := query.
+		Preload("Paths", func(db *gorm.DB) *gorm.DB { return db.Order("first_seen_at") }).
+		Order("path").
+		Find(&projects).Error; err != nil {
+		return nil, fmt.Errorf("查询项目失败: %w", err)
+	}
+	return projects, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RollupRepository 聚合统计仓库
+// 读取 member_contributions / member_language_stats 中预先聚合的数据
+type RollupRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewRollupRepository 创建新的聚合统计仓库
+func NewRollupRepository(db *gorm.DB, logger *zap.Logger) *RollupRepository {
+	return &RollupRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetMemberStats 从聚合表获取成员统计信息
+// period 必须能完整覆盖 [startDate, endDate)，由调用方保证
+func (r *RollupRepository) GetMemberStats(
+	authorEmail string,
+	period string,
+	startDate, endDate *time.Time,
+) (*MemberStats, error) {
+	var stats MemberStats
+	query := r.db.Model(&model.MemberContribution{}).
+		Where("member_email = ?", authorEmail).
+		Where("period_type = ?", period)
+
+	if startDate != nil {
+		query = query.Where("start_date >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("start_date < ?", *endDate)
+	}
+
+	if err := query.Select(
+		"COALESCE(SUM(commit_count), 0) as commit_count",
+		"COALESCE(SUM(additions), 0) as total_added",
+		"COALESCE(SUM(deletions), 0) as total_removed",
+		"COALESCE(SUM(file_count), 0) as total_files",
+	).Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("查询成员聚合统计失败: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// GetLanguageStats 从聚合表获取语言统计信息
+func (r *RollupRepository) GetLanguageStats(
+	authorEmail string,
+	period string,
+	startDate, endDate *time.Time,
+) ([]*LanguageStats, error) {
+	var stats []*LanguageStats
+	query := r.db.Model(&model.MemberLanguageStat{}).
+		Select(
+			"language",
+			"COALESCE(SUM(lines_added), 0) as total_added",
+			"COALESCE(SUM(lines_removed), 0) as total_removed",
+			"COALESCE(SUM(file_count), 0) as total_files",
+		).
+		Where("member_email = ?", authorEmail).
+		Where("period_type = ?", period)
+
+	if startDate != nil {
+		query = query.Where("period_start >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("period_start < ?", *endDate)
+	}
+
+	if err := query.Group("language").
+		Order("total_added DESC").
+		Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("查询语言聚合统计失败: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,784 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// StatsFilter 统计查询过滤条件
+// 所有条件都作用于 commits 表，零值表示不过滤
+type StatsFilter struct {
+	AuthorEmail string
+	ProjectID   *int
+	Platform    string // gitlab / github / gitee，不同平台的项目 ID 可能相同
+	Instance    string // 平台实例主机名
+	ProjectPath string // 项目当前或曾用的路径，重命名前后的提交都会匹配
+	Namespace   string // 命名空间（组），包含其下所有子组的项目
+	Team        string // 团队名称，限定作者为团队成员
+	Branch      string // 提交出现过的任一分支（含合并后出现的目标分支）
+	StartDate   *time.Time
+	EndDate     *time.Time
+	// IncludeGenerated 为 true 时行数统计包含生成代码、第三方代码和锁文件
+	IncludeGenerated bool
+	// IncludeMerges 为 true 时行数统计包含合并提交（合并提交始终计入提交数）
+	IncludeMerges bool
+	// OnDefaultBranch 为 true 时只统计已进入默认分支的提交，默认统计所有分支
+	OnDefaultBranch bool
+	// IncludeDuplicates 为 true 时 cherry-pick、fork 等等价提交各自计数，默认同一逻辑变更只计一次（只计代表提交）
+	IncludeDuplicates bool
+	// ExcludeReverts 为 true 时已关联的回滚提交和被回滚提交行数记为 0（提交数不变），写入后又回滚的代码净增为零；
+	// 回滚的回滚（重新应用）整条链都不计入行数
+	ExcludeReverts bool
+}
+
+// Apply 将过滤条件应用到以 commits 为主表的查询
+// 默认只统计代表提交（duplicate_of 为空，即每个逻辑变更最早入库的一条），与聚合表的去重口径一致；
+// 代表提交不在过滤范围内（如只查询 fork 项目）时，该逻辑变更不计入
+func (f *StatsFilter) Apply(query *gorm.DB) *gorm.DB {
+	query = f.conditions(query)
+	if !f.IncludeDuplicates {
+		query = query.Where("commits.duplicate_of IS NULL")
+	}
+	return query
+}
+
+// conditions 应用过滤条件（不去重）
+func (f *StatsFilter) conditions(query *gorm.DB) *gorm.DB {
+	if f.AuthorEmail != "" {
+		query = query.Where("commits.author_email = ?", f.AuthorEmail)
+	}
+	if f.ProjectID != nil {
+		query = query.Where("commits.project_id = ?", *f.ProjectID)
+	}
+	if f.Platform != "" {
+		query = query.Where("commits.platform = ?", f.Platform)
+	}
+	if f.Instance != "" {
+		query = query.Where("commits.instance = ?", strings.ToLower(f.Instance))
+	}
+	// 通过项目记录匹配，项目重命名或迁移命名空间后历史不会拆分；未关联项目记录的提交按提交时的路径匹配
+	if f.ProjectPath != "" {
+		query = query.Where("(commits.project_path = ? OR commits.project_ref_id IN (SELECT project_ref_id FROM project_paths WHERE path = ?))",
+			f.ProjectPath, f.ProjectPath)
+	}
+	if f.Namespace != "" {
+		prefix := escapeLike(strings.TrimSuffix(f.Namespace, "/")) + "/%"
+		query = query.Where("(commits.project_namespace = ? OR commits.project_path LIKE ? OR commits.project_ref_id IN (SELECT id FROM projects WHERE namespace = ? OR path LIKE ?))",
+			f.Namespace, prefix, f.Namespace, prefix)
+	}
+	if f.Team != "" {
+		query = query.Where("commits.author_email IN (?)",
+			teamMemberSubQuery(query.Session(&gorm.Session{NewDB: true}), f.Team))
+	}
+	// commits.branch 为首次推送的分支，未回填 commit_branches 的历史提交仍可匹配
+	if f.Branch != "" {
+		query = query.Where("(commits.branch = ? OR EXISTS (SELECT 1 FROM commit_branches WHERE commit_branches.commit_id = commits.id AND commit_branches.branch = ?))",
+			f.Branch, f.Branch)
+	}
+	if f.OnDefaultBranch {
+		query = query.Where("EXISTS (SELECT 1 FROM commit_branches WHERE commit_branches.commit_id = commits.id AND commit_branches.is_default = ?)",
+			true)
+	}
+	if f.StartDate != nil {
+		query = query.Where("commits.timestamp >= ?", f.StartDate.UTC())
+	}
+	if f.EndDate != nil {
+		query = query.Where("commits.timestamp < ?", f.EndDate.UTC())
+	}
+	return query
+}
+
+// lineColumns 获取新增行数、删除行数、变更文件数的列表达式
+// 默认只统计普通文件且合并提交记为 0，IncludeGenerated / IncludeMerges 时加上被排除的部分，ExcludeReverts 时回滚对记为 0
+func (f *StatsFilter) lineColumns() (added, removed, files string) {
+	added, removed, files = "commits.total_added_lines", "commits.total_removed_lines", "commits.total_changed_files"
+	if f.IncludeGenerated {
+		added = "(commits.total_added_lines + commits.excluded_added_lines)"
+		removed = "(commits.total_removed_lines + commits.excluded_removed_lines)"
+		files = "(commits.total_changed_files + commits.excluded_changed_files)"
+	}
+	if !f.IncludeMerges {
+		added = "CASE WHEN commits.is_merge THEN 0 ELSE " + added + " END"
+		removed = "CASE WHEN commits.is_merge THEN 0 ELSE " + removed + " END"
+		files = "CASE WHEN commits.is_merge THEN 0 ELSE " + files + " END"
+	}
+	if f.ExcludeReverts {
+		added = "CASE WHEN " + revertPairCondition + " THEN 0 ELSE " + added + " END"
+		removed = "CASE WHEN " + revertPairCondition + " THEN 0 ELSE " + removed + " END"
+		files = "CASE WHEN " + revertPairCondition + " THEN 0 ELSE " + files + " END"
+	}
+	return added, removed, files
+}
+
+// revertPairCondition 提交属于已关联的回滚对（回滚提交或被回滚提交）
+const revertPairCondition = "(commits.revert_of IS NOT NULL OR commits.reverted_by IS NOT NULL)"
+
+// lineScope 限定参与文件级行数统计的提交（语言、文件变更明细），默认排除合并提交，ExcludeReverts 时排除回滚对
+func (f *StatsFilter) lineScope(query *gorm.DB) *gorm.DB {
+	if !f.IncludeMerges {
+		query = query.Where("commits.is_merge = ?", false)
+	}
+	if f.ExcludeReverts {
+		query = query.Where("NOT " + revertPairCondition)
+	}
+	return query
+}
+
+// languageSource 获取语言统计的数据源
+// commit_languages 不包含被排除的文件，IncludeGenerated 时改为从 commit_files 汇总
+func (f *StatsFilter) languageSource(db *gorm.DB) (query *gorm.DB, table string) {
+	if f.IncludeGenerated {
+		return f.lineScope(db.Table("commit_files").
+			Joins("JOIN commits ON commit_files.commit_id = commits.id")), "commit_files"
+	}
+	return f.lineScope(db.Table("commit_languages").
+		Joins("JOIN commits ON commit_languages.commit_id = commits.id")), "commit_languages"
+}
+
+// languageFileCount 语言统计中文件数的聚合表达式
+func languageFileCount(table string) string {
+	if table == "commit_files" {
+		return "COUNT(*)"
+	}
+	return "COALESCE(SUM(commit_languages.file_count), 0)"
+}
+
+// projectPathExpr 按项目分组时的项目路径：关联了项目记录时取当前路径，重命名前后的提交归为同一组
+const projectPathExpr = "COALESCE(projects.path, commits.project_path)"
+
+// joinProjects 关联项目记录以使用 projectPathExpr
+func joinProjects(query *gorm.DB, join bool) *gorm.DB {
+	if !join {
+		return query
+	}
+	return query.Joins("LEFT JOIN projects ON projects.id = commits.project_ref_id")
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// StatsRepository 多维统计仓库
+type StatsRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewStatsRepository 创建新的多维统计仓库
+func NewStatsRepository(db *gorm.DB, logger *zap.Logger) *StatsRepository {
+	return &StatsRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// commits 创建应用了过滤条件的 commits 查询
+func (r *StatsRepository) commits(filter *StatsFilter) *gorm.DB {
+	return filter.Apply(r.db.Model(&model.Commit{}))
+}
+
+// GetSummary 获取汇总统计
+// 活跃贡献者包含共同作者；按成员或团队过滤时提交只计一次，行数为匹配作者按 CREDIT_MODE 分得的部分
+func (r *StatsRepository) GetSummary(filter *StatsFilter) (*SummaryStats, error) {
+	var stats SummaryStats
+	added, removed, files := filter.lineColumns()
+	query, credit := r.creditScope(filter, r.db.Model(&model.Commit{}), false)
+	if err := query.Select(
+		"COUNT(*) as commit_count",
+		"COALESCE(SUM("+credit(added)+"), 0) as total_added",
+		"COALESCE(SUM("+credit(removed)+"), 0) as total_removed",
+		"COALESCE(SUM("+files+"), 0) as total_files",
+		"MIN(commits.timestamp) as first_commit_at",
+		"MAX(commits.timestamp) as last_commit_at",
+	).Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("查询汇总统计失败: %w", err)
+	}
+
+	if err := filter.applyCredited(r.db.Model(&model.Commit{})).
+		Select("COUNT(DISTINCT commit_authors.email)").
+		Scan(&stats.ActiveContributors).Error; err != nil {
+		return nil, fmt.Errorf("查询活跃贡献者数失败: %w", err)
+	}
+	return &stats, nil
+}
+
+// GetLanguageMix 获取语言分布
+// 按成员或团队过滤时包含其作为共同作者的提交，行数为匹配作者按 CREDIT_MODE 分得的部分，同一提交只计一次
+func (r *StatsRepository) GetLanguageMix(filter *StatsFilter) ([]*LanguageStats, error) {
+	var stats []*LanguageStats
+	source, table := filter.languageSource(r.db)
+	query, credit := r.creditScope(filter, source, false)
+	query = query.Select(
+		table+".language",
+		"COALESCE(SUM("+credit(table+".added_lines")+"), 0) as total_added",
+		"COALESCE(SUM("+credit(table+".removed_lines")+"), 0) as total_removed",
+		languageFileCount(table)+" as total_files",
+	)
+
+	if err := query.Group(table + ".language").
+		Order("total_added DESC").
+		Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("查询语言分布失败: %w", err)
+	}
+	return stats, nil
+}
+
+// GetTopContributors 获取贡献者排行（按提交数）
+// 共同作者与提交作者一样计入，行数按 CREDIT_MODE 归属
+func (r *StatsRepository) GetTopContributors(filter *StatsFilter, limit int) ([]*ContributorStats, error) {
+	var stats []*ContributorStats
+	added, removed, files := filter.lineColumns()
+	query := filter.applyCredited(r.db.Model(&model.Commit{})).Select(
+		"commit_authors.email as email",
+		"MAX(commit_authors.name) as name",
+		"COUNT(*) as commit_count",
+		"COALESCE(SUM("+creditLines(r.db, added)+"), 0) as total_added",
+		"COALESCE(SUM("+creditLines(r.db, removed)+"), 0) as total_removed",
+		"COALESCE(SUM("+files+"), 0) as total_files",
+		"MAX(commits.timestamp) as last_commit_at",
+	).
+		Group("commit_authors.email").
+		Order("commit_count DESC").
+		Order("total_added DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("查询贡献者排行失败: %w", err)
+	}
+	return stats, nil
+}
+
+// GetBranchActivity 获取分支活跃度
+func (r *StatsRepository) GetBranchActivity(filter *StatsFilter, limit int) ([]*BranchStats, error) {
+	var stats []*BranchStats
+	added, removed, _ := filter.lineColumns()
+	// 同一提交计入其出现过的每个分支，没有分支记录的历史提交按首次推送的分支统计
+	branch := "COALESCE(commit_branches.branch, commits.branch)"
+	query := r.commits(filter).
+		Joins("LEFT JOIN commit_branches ON commit_branches.commit_id = commits.id").
+		Select(
+			branch+" as branch",
+			"COUNT(*) as commit_count",
+			"COUNT(DISTINCT commits.author_email) as contributors",
+			"COALESCE(SUM("+added+"), 0) as total_added",
+			"COALESCE(SUM("+removed+"), 0) as total_removed",
+			"MAX(commits.timestamp) as last_commit_at",
+		).
+		Group(branch).
+		Order("commit_count DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("查询分支活跃度失败: %w", err)
+	}
+	return stats, nil
+}
+
+// GetMemberMetrics 获取每个成员的各项指标（用于排行榜）
+// 共同作者与提交作者一样计入，行数按 CREDIT_MODE 归属
+func (r *StatsRepository) GetMemberMetrics(filter *StatsFilter) ([]*MemberMetrics, error) {
+	var metrics []*MemberMetrics
+	added, removed, files := filter.lineColumns()
+	if err := filter.applyCredited(r.db.Model(&model.Commit{})).Select(
+		"commit_authors.email as email",
+		"MAX(commit_authors.name) as name",
+		"COUNT(*) as commit_count",
+		"COALESCE(SUM("+creditLines(r.db, added)+"), 0) as total_added",
+		"COALESCE(SUM("+creditLines(r.db, removed)+"), 0) as total_removed",
+		"COALESCE(SUM("+files+"), 0) as total_files",
+	).
+		Group("commit_authors.email").
+		Scan(&metrics).Error; err != nil {
+		return nil, fmt.Errorf("查询成员指标失败: %w", err)
+	}
+	return metrics, nil
+}
+
+// GetMemberCommitTimes 获取每位作者（含共同作者）的提交时间，用于计算活跃天数
+func (r *StatsRepository) GetMemberCommitTimes(filter *StatsFilter) ([]*CommitPoint, error) {
+	var points []*CommitPoint
+	if err := filter.applyCredited(r.db.Model(&model.Commit{})).Select(
+		"commits.timestamp",
+		"commit_authors.email as author_email",
+		"commits.author_tz_offset",
+	).
+		Order("commits.timestamp").
+		Scan(&points).Error; err != nil {
+		return nil, fmt.Errorf("查询成员提交时间失败: %w", err)
+	}
+	return points, nil
+}
+
+// GetMemberLanguageLines 获取每个成员在指定语言上的新增/删除行数（含共同作者，行数按 CREDIT_MODE 归属）
+func (r *StatsRepository) GetMemberLanguageLines(filter *StatsFilter, language string) (map[string]*LanguageStats, error) {
+	var rows []struct {
+		Email string
+		LanguageStats
+	}
+	source, table := filter.languageSource(r.db)
+	query := filter.applyCredited(source.Select(
+		"commit_authors.email as email",
+		table+".language",
+		"COALESCE(SUM("+creditLines(r.db, table+".added_lines")+"), 0) as total_added",
+		"COALESCE(SUM("+creditLines(r.db, table+".removed_lines")+"), 0) as total_removed",
+		languageFileCount(table)+" as total_files",
+	).
+		Where(table+".language = ?", language))
+
+	if err := query.Group("commit_authors.email, " + table + ".language").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询成员语言行数失败: %w", err)
+	}
+
+	result := make(map[string]*LanguageStats, len(rows))
+	for i := range rows {
+		result[rows[i].Email] = &rows[i].LanguageStats
+	}
+	return result, nil
+}
+
+// GetMemberLanguageMix 获取每个成员（含共同作者）的语言分布，行数按 CREDIT_MODE 归属
+func (r *StatsRepository) GetMemberLanguageMix(filter *StatsFilter) ([]*MemberLanguageLines, error) {
+	var rows []*MemberLanguageLines
+	source, table := filter.languageSource(r.db)
+	if err := filter.applyCredited(source.Select(
+		"commit_authors.email as email",
+		table+".language",
+		"COALESCE(SUM("+creditLines(r.db, table+".added_lines")+"), 0) as total_added",
+		"COALESCE(SUM("+creditLines(r.db, table+".removed_lines")+"), 0) as total_removed",
+		languageFileCount(table)+" as total_files",
+	)).
+		Group("commit_authors.email, " + table + ".language").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询成员语言分布失败: %w", err)
+	}
+	return rows, nil
+}
+
+// groupKeyExpr 按 member / project 分组时的分组键表达式，成员为 commit_authors.email（需配合 creditScope 按成员展开），其余为空字符串
+func groupKeyExpr(groupBy string) string {
+	switch groupBy {
+	case "member":
+		return "commit_authors.email"
+	case "project":
+		return projectPathExpr
+	}
+	return "''"
+}
+
+// GetCommitPoints 获取提交时间点明细（只包含统计需要的列，用于按时间分桶）
+// 每个提交一行；按成员或团队过滤时包含其作为共同作者的提交，行数为匹配作者按 CREDIT_MODE 分得的部分
+func (r *StatsRepository) GetCommitPoints(filter *StatsFilter) ([]*CommitPoint, error) {
+	var points []*CommitPoint
+	added, removed, files := filter.lineColumns()
+	query, credit := r.creditScope(filter, r.db.Model(&model.Commit{}), false)
+	if err := query.Select(
+		"commits.timestamp",
+		"commits.author_email",
+		credit(added)+" as total_added_lines",
+		credit(removed)+" as total_removed_lines",
+		files+" as total_changed_files",
+		"commits.author_tz_offset",
+	).
+		Order("commits.timestamp").
+		Scan(&points).Error; err != nil {
+		return nil, fmt.Errorf("查询提交时间点失败: %w", err)
+	}
+	return points, nil
+}
+
+// GetFileChanges 获取文件变更明细（按提交时间升序），用于热点与归属分析
+// pathPrefix 非空时只返回该目录下的文件
+func (r *StatsRepository) GetFileChanges(filter *StatsFilter, pathPrefix string) ([]*FileChange, error) {
+	var changes []*FileChange
+	query := filter.Apply(r.db.Table("commit_files").
+		Select(
+			"commit_files.file_path",
+			"commit_files.change_type",
+			"commit_files.added_lines",
+			"commit_files.removed_lines",
+			"commits.author_email",
+			"commits.timestamp",
+		).
+		Joins("JOIN commits ON commit_files.commit_id = commits.id"))
+
+	if prefix := strings.Trim(pathPrefix, "/"); prefix != "" {
+		query = query.Where("commit_files.file_path LIKE ?", escapeLike(prefix)+"/%")
+	}
+	if !filter.IncludeGenerated {
+		query = query.Where("commit_files.classification = ?", "")
+	}
+	query = filter.lineScope(query)
+
+	if err := query.Order("commits.timestamp").
+		Order("commit_files.id").
+		Scan(&changes).Error; err != nil {
+		return nil, fmt.Errorf("查询文件变更明细失败: %w", err)
+	}
+	return changes, nil
+}
+
+// GetLastCommitTimes 获取成员在所有项目中的最后提交时间
+func (r *StatsRepository) GetLastCommitTimes(emails []string) (map[string]time.Time, error) {
+	result := make(map[string]time.Time, len(emails))
+	if len(emails) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		Email        string
+		LastCommitAt time.Time
+	}
+	if err := r.db.Model(&model.Commit{}).
+		Select("author_email as email", "MAX(timestamp) as last_commit_at").
+		Where("author_email IN ?", emails).
+		Group("author_email").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询成员最后提交时间失败: %w", err)
+	}
+
+	for _, row := range rows {
+		result[row.Email] = row.LastCommitAt
+	}
+	return result, nil
+}
+
+// GetCommitTypeCounts 获取各 Conventional Commits 类型的提交数
+// groupBy 为 member（按作者邮箱，含共同作者）或 project（按项目路径）时额外按该维度分组，为空时 Key 为空字符串
+func (r *StatsRepository) GetCommitTypeCounts(filter *StatsFilter, groupBy string) ([]*CommitTypeCount, error) {
+	keyExpr := groupKeyExpr(groupBy)
+	scoped, _ := r.creditScope(filter, r.db.Model(&model.Commit{}), groupBy == "member")
+
+	var counts []*CommitTypeCount
+	query := joinProjects(scoped, groupBy == "project").Select(
+		keyExpr+" as group_key",
+		"commits.commit_type",
+		"COUNT(*) as commit_count",
+		"COALESCE(SUM(CASE WHEN commits.is_breaking THEN 1 ELSE 0 END), 0) as breaking_count",
+	)
+	if groupBy != "" {
+		query = query.Group(keyExpr)
+	}
+	if err := query.Group("commits.commit_type").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("查询提交类型分布失败: %w", err)
+	}
+	return counts, nil
+}
+
+// GetRevertCounts 获取提交数、回滚提交数和被回滚提交数
+// groupBy 为 member（按作者邮箱，含共同作者）或 project（按项目路径）时按该维度分组，为空时返回一行且 Key 为空字符串
+// 被回滚的新增行数按 CREDIT_MODE 归属
+func (r *StatsRepository) GetRevertCounts(filter *StatsFilter, groupBy string) ([]*RevertCount, error) {
+	keyExpr := groupKeyExpr(groupBy)
+	scoped, credit := r.creditScope(filter, r.db.Model(&model.Commit{}), groupBy == "member")
+
+	var counts []*RevertCount
+	query := joinProjects(scoped, groupBy == "project").Select(
+		keyExpr+" as group_key",
+		"COUNT(*) as commit_count",
+		"COALESCE(SUM(CASE WHEN commits.is_revert THEN 1 ELSE 0 END), 0) as revert_count",
+		"COALESCE(SUM(CASE WHEN commits.reverted_by IS NOT NULL THEN 1 ELSE 0 END), 0) as reverted_count",
+		"COALESCE(SUM(CASE WHEN commits.reverted_by IS NOT NULL THEN "+credit("commits.total_added_lines")+" ELSE 0 END), 0) as reverted_added_lines",
+	)
+	if groupBy != "" {
+		query = query.Group(keyExpr)
+	}
+	if err := query.Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("查询回滚统计失败: %w", err)
+	}
+	return counts, nil
+}
+
+// GetCategoryLines 获取各文件类别（测试 / 生产 / 文档 / 配置 / 构建）的行数
+// groupBy 为 member / project 时额外按该维度分组；perCommit 为 true 时按提交拆分并返回提交时间，用于按时间分桶
+// 默认不包含生成代码、第三方代码、锁文件和合并提交；成员（含共同作者）的行数按 CREDIT_MODE 归属
+func (r *StatsRepository) GetCategoryLines(filter *StatsFilter, groupBy string, perCommit bool) ([]*CategoryLines, error) {
+	keyExpr := groupKeyExpr(groupBy)
+	query, credit := r.creditScope(filter, joinProjects(r.db.Table("commit_files").
+		Joins("JOIN commits ON commit_files.commit_id = commits.id"), groupBy == "project"), groupBy == "member")
+
+	columns := []interface{}{
+		keyExpr + " as group_key",
+		"commit_files.category",
+		"COALESCE(SUM(" + credit("commit_files.added_lines") + "), 0) as added_lines",
+		"COALESCE(SUM(" + credit("commit_files.removed_lines") + "), 0) as removed_lines",
+		"COUNT(*) as file_count",
+	}
+	if perCommit {
+		columns = append(columns, "commits.timestamp")
+	}
+
+	query = query.Select(columns[0], columns[1:]...)
+	if !filter.IncludeGenerated {
+		query = query.Where("commit_files.classification = ?", "")
+	}
+	query = filter.lineScope(query)
+	if groupBy != "" {
+		query = query.Group(keyExpr)
+	}
+	if perCommit {
+		query = query.Group("commits.id, commits.timestamp")
+	}
+
+	var lines []*CategoryLines
+	if err := query.Group("commit_files.category").
+		Scan(&lines).Error; err != nil {
+		return nil, fmt.Errorf("查询文件类别行数失败: %w", err)
+	}
+	return lines, nil
+}
+
+// GetCommitsByReference 获取引用了指定 issue / 合并请求 / 工单的提交（按时间升序）
+func (r *StatsRepository) GetCommitsByReference(refKey string) ([]*model.Commit, error) {
+	var commits []*model.Commit
+	if err := r.db.Model(&model.Commit{}).
+		Where("commits.id IN (?)", r.db.Table("commit_references").
+			Select("commit_id").
+			Where("ref_key = ?", refKey)).
+		Preload("References", "ref_key = ?", refKey).
+		Order("commits.timestamp").
+		Find(&commits).Error; err != nil {
+		return nil, fmt.Errorf("查询引用提交失败: %w", err)
+	}
+	return commits, nil
+}
+
+// GetReferenceCoverage 获取有/无工单引用的提交数
+// groupBy 为 member 时按作者邮箱（含共同作者）分组，为空时 Key 为空字符串
+func (r *StatsRepository) GetReferenceCoverage(filter *StatsFilter, groupBy string) ([]*ReferenceCoverage, error) {
+	keyExpr := "''"
+	if groupBy == "member" {
+		keyExpr = groupKeyExpr(groupBy)
+	}
+
+	var rows []*ReferenceCoverage
+	query, _ := r.creditScope(filter, r.db.Model(&model.Commit{}), groupBy == "member")
+	query = query.Select(
+		keyExpr+" as group_key",
+		"COUNT(*) as commit_count",
+		"COALESCE(SUM(CASE WHEN EXISTS (SELECT 1 FROM commit_references WHERE commit_references.commit_id = commits.id) THEN 1 ELSE 0 END), 0) as linked_count",
+	)
+	if groupBy != "" {
+		query = query.Group(keyExpr)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询工单引用覆盖率失败: %w", err)
+	}
+	return rows, nil
+}
+
+// pushConditions 将过滤条件应用到 pushes 表
+// AuthorEmail 和 Team 匹配推送者邮箱，Branch 匹配推送的引用，日期匹配推送时间；其余条件只适用于提交，忽略
+func (f *StatsFilter) pushConditions(query *gorm.DB) *gorm.DB {
+	if f.AuthorEmail != "" {
+		query = query.Where("pushes.pusher_email = ?", f.AuthorEmail)
+	}
+	if f.ProjectID != nil {
+		query = query.Where("pushes.project_id = ?", *f.ProjectID)
+	}
+	if f.Platform != "" {
+		query = query.Where("pushes.platform = ?", f.Platform)
+	}
+	if f.Instance != "" {
+		query = query.Where("pushes.instance = ?", strings.ToLower(f.Instance))
+	}
+	// 按项目记录匹配曾用路径，重命名前后的推送都会匹配
+	if f.ProjectPath != "" {
+		query = query.Where("(pushes.project_path = ? OR EXISTS (SELECT 1 FROM projects JOIN project_paths ON project_paths.project_ref_id = projects.id "+
+			"WHERE project_paths.path = ? AND projects.platform = pushes.platform AND projects.instance = pushes.instance AND projects.external_id = pushes.project_id))",
+			f.ProjectPath, f.ProjectPath)
+	}
+	if f.Namespace != "" {
+		query = query.Where("pushes.project_path LIKE ?", escapeLike(strings.TrimSuffix(f.Namespace, "/"))+"/%")
+	}
+	if f.Team != "" {
+		query = query.Where("pushes.pusher_email IN (?)",
+			teamMemberSubQuery(query.Session(&gorm.Session{NewDB: true}), f.Team))
+	}
+	if f.Branch != "" {
+		query = query.Where("pushes.ref = ?", f.Branch)
+	}
+	if f.StartDate != nil {
+		query = query.Where("pushes.pushed_at >= ?", f.StartDate.UTC())
+	}
+	if f.EndDate != nil {
+		query = query.Where("pushes.pushed_at < ?", f.EndDate.UTC())
+	}
+	return query
+}
+
+// pusherKeyExpr 推送者分组键：优先邮箱，平台未提供邮箱时使用用户名
+const pusherKeyExpr = "COALESCE(NULLIF(pushes.pusher_email, ''), pushes.pusher_username)"
+
+// GetPusherActivity 按推送者汇总推送次数、推送的提交数、受保护分支推送和推送他人提交的情况
+// 提交作者邮箱与推送者邮箱不同即视为他人的提交，推送者没有邮箱时不判断
+func (r *StatsRepository) GetPusherActivity(filter *StatsFilter) ([]*PusherActivity, error) {
+	pushIDs := filter.pushConditions(r.db.Model(&model.Push{})).Select("pushes.id")
+	perPush := r.db.Table("push_commits").
+		Select(
+			"push_commits.push_id",
+			"COUNT(*) as commit_count",
+			"SUM(CASE WHEN pushes.pusher_email <> '' AND LOWER(commits.author_email) <> LOWER(pushes.pusher_email) THEN 1 ELSE 0 END) as others_count",
+		).
+		Joins("JOIN commits ON commits.id = push_commits.commit_id").
+		Joins("JOIN pushes ON pushes.id = push_commits.push_id").
+		Where("push_commits.push_id IN (?)", pushIDs).
+		Group("push_commits.push_id")
+
+	var rows []*PusherActivity
+	if err := filter.pushConditions(r.db.Model(&model.Push{})).
+		Joins("LEFT JOIN (?) pc ON pc.push_id = pushes.id", perPush).
+		Select(
+			pusherKeyExpr+" as pusher_key",
+			"MAX(pushes.pusher_email) as pusher_email",
+			"MAX(pushes.pusher_username) as pusher_username",
+			"MAX(pushes.pusher_name) as pusher_name",
+			"COUNT(*) as push_count",
+			"SUM(CASE WHEN COALESCE(pc.commit_count, 0) = 0 THEN 1 ELSE 0 END) as empty_push_count",
+			"SUM(CASE WHEN pushes.ref_protected THEN 1 ELSE 0 END) as protected_push_count",
+			"COALESCE(SUM(pc.commit_count), 0) as commit_count",
+			"SUM(CASE WHEN COALESCE(pc.others_count, 0) > 0 THEN 1 ELSE 0 END) as others_push_count",
+			"COALESCE(SUM(pc.others_count), 0) as others_commit_count",
+			"MIN(pushes.pushed_at) as first_push_at",
+			"MAX(pushes.pushed_at) as last_push_at",
+		).
+		Group(pusherKeyExpr).
+		Order("push_count DESC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询推送者统计失败: %w", err)
+	}
+	return rows, nil
+}
+
+// SummaryStats 汇总统计信息
+type SummaryStats struct {
+	CommitCount        int        `json:"commit_count"`
+	ActiveContributors int        `json:"active_contributors"`
+	TotalAdded         int        `json:"total_added"`
+	TotalRemoved       int        `json:"total_removed"`
+	TotalFiles         int        `json:"total_files"`
+	FirstCommitAt      *time.Time `json:"first_commit_at,omitempty"`
+	LastCommitAt       *time.Time `json:"last_commit_at,omitempty"`
+}
+
+// ContributorStats 贡献者统计信息
+type ContributorStats struct {
+	Email        string     `json:"email"`
+	Name         string     `json:"name"`
+	CommitCount  int        `json:"commit_count"`
+	TotalAdded   int        `json:"total_added"`
+	TotalRemoved int        `json:"total_removed"`
+	TotalFiles   int        `json:"total_files"`
+	LastCommitAt *time.Time `json:"last_commit_at,omitempty"`
+}
+
+// MemberMetrics 成员指标
+type MemberMetrics struct {
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	CommitCount  int    `json:"commit_count"`
+	TotalAdded   int    `json:"total_added"`
+	TotalRemoved int    `json:"total_removed"`
+	TotalFiles   int    `json:"total_files"`
+	ActiveDays   int    `json:"active_days" gorm:"-"` // 按查询时区计算，由服务层填充
+}
+
+// MemberLanguageLines 成员在某种语言上的行数
+type MemberLanguageLines struct {
+	Email string
+	LanguageStats
+}
+
+// CommitPoint 提交时间点
+type CommitPoint struct {
+	Timestamp         time.Time
+	AuthorEmail       string
+	TotalAddedLines   int
+	TotalRemovedLines int
+	TotalChangedFiles int
+	AuthorTZOffset    *int // 作者提交时的 UTC 偏移（分钟），历史数据可能为空
+}
+
+// CommitTypeCount 某个分组下某种提交类型的数量（CommitType 为空表示不符合规范）
+type CommitTypeCount struct {
+	GroupKey      string
+	CommitType    string
+	CommitCount   int
+	BreakingCount int
+}
+
+// RevertCount 某个分组下的回滚情况
+type RevertCount struct {
+	GroupKey           string
+	CommitCount        int
+	RevertCount        int // 回滚提交数（Revert "..." / This reverts commit）
+	RevertedCount      int // 已被回滚的提交数
+	RevertedAddedLines int // 已被回滚的提交的新增行数
+}
+
+// CategoryLines 某个分组（及提交）下某个文件类别的行数
+type CategoryLines struct {
+	GroupKey     string
+	Category     string
+	Timestamp    time.Time // 仅按提交拆分时有值
+	AddedLines   int
+	RemovedLines int
+	FileCount    int
+}
+
+// ReferenceCoverage 某个分组下的提交数与带工单引用的提交数
+type ReferenceCoverage struct {
+	GroupKey    string
+	CommitCount int
+	LinkedCount int
+}
+
+// FileChange 单个文件在一次提交中的变更
+type FileChange struct {
+	FilePath     string
+	ChangeType   string
+	AddedLines   int
+	RemovedLines int
+	AuthorEmail  string
+	Timestamp    time.Time
+}
+
+// BranchStats 分支统计信息
+type BranchStats struct {
+	Branch       string     `json:"branch"`
+	CommitCount  int        `json:"commit_count"`
+	Contributors int        `json:"contributors"`
+	TotalAdded   int        `json:"total_added"`
+	TotalRemoved int        `json:"total_removed"`
+	LastCommitAt *time.Time `json:"last_commit_at,omitempty"`
+}
+
+// PusherActivity 某个推送者的推送汇总
+type PusherActivity struct {
+	PusherKey          string
+	PusherEmail        string
+	PusherUsername     string
+	PusherName         string
+	PushCount          int
+	EmptyPushCount     int // 没有关联提交的推送（删除分支、强制回退、新建分支等）
+	ProtectedPushCount int
+	CommitCount        int
+	OthersPushCount    int // 包含他人提交的推送数
+	OthersCommitCount  int
+	FirstPushAt        time.Time
+	LastPushAt         time.Time
+}
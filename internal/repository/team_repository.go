@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"fmt"
+
+	"gitlab-webhook-server/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TeamRepository 团队仓库
+type TeamRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewTeamRepository 创建新的团队仓库
+func NewTeamRepository(db *gorm.DB, logger *zap.Logger) *TeamRepository {
+	return &TeamRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListTeams 获取所有团队（包含成员）
+func (r *TeamRepository) ListTeams() ([]*model.Team, error) {
+	var teams []*model.Team
+	if err := r.db.Preload("Members").Order("name").Find(&teams).Error; err != nil {
+		return nil, fmt.Errorf("查询团队列表失败: %w", err)
+	}
+	return teams, nil
+}
+
+// GetTeamByName 根据名称获取团队（包含成员），不存在时返回 nil
+func (r *TeamRepository) GetTeamByName(name string) (*model.Team, error) {
+	var team model.Team
+	if err := r.db.Preload("Members").Where("name = ?", name).First(&team).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询团队失败: %w", err)
+	}
+	return &team, nil
+}
+
+// SaveTeam 创建或更新团队，并以 members 整体替换团队成员
+func (r *TeamRepository) SaveTeam(name, description string, members []model.TeamMember) (*model.Team, error) {
+	var team model.Team
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("name = ?", name).First(&team).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			team = model.Team{Name: name, Description: description}
+			if err := tx.Create(&team).Error; err != nil {
+				return fmt.Errorf("创建团队失败: %w", err)
+			}
+		case err != nil:
+			return fmt.Errorf("查询团队失败: %w", err)
+		default:
+			if err := tx.Model(&team).Update("description", description).Error; err != nil {
+				return fmt.Errorf("更新团队失败: %w", err)
+			}
+		}
+
+		if err := tx.Where("team_id = ?", team.ID).Delete(&model.TeamMember{}).Error; err != nil {
+			return fmt.Errorf("清理团队成员失败: %w", err)
+		}
+		for i := range members {
+			members[i].ID = 0
+			members[i].TeamID = team.ID
+		}
+		if len(members) > 0 {
+			if err := tx.Create(&members).Error; err != nil {
+				return fmt.Errorf("保存团队成员失败: %w", err)
+			}
+		}
+		team.Members = members
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// DeleteTeam 删除团队及其成员
+func (r *TeamRepository) DeleteTeam(name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var team model.Team
+		if err := tx.Where("name = ?", name).First(&team).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return fmt.Errorf("查询团队失败: %w", err)
+		}
+		if err := tx.Where("team_id = ?", team.ID).Delete(&model.TeamMember{}).Error; err != nil {
+			return fmt.Errorf("删除团队成员失败: %w", err)
+		}
+		if err := tx.Delete(&team).Error; err != nil {
+			return fmt.Errorf("删除团队失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// teamMemberSubQuery 团队成员邮箱子查询
+func teamMemberSubQuery(db *gorm.DB, team string) *gorm.DB {
+	return db.Table("team_members").
+		Select("team_members.member_email").
+		Joins("JOIN teams ON teams.id = team_members.team_id").
+		Where("teams.name = ?", team)
+}
@@ -25,6 +25,11 @@ func RegisterRoutes(
 	r *gin.Engine,
 	webhookHandler *handler.WebhookHandler,
 	statsHandler *handler.StatsHandler,
+	teamHandler *handler.TeamHandler,
+	codeOwnersHandler *handler.CodeOwnersHandler,
+	fileRuleHandler *handler.FileRuleHandler,
+	commitHandler *handler.CommitHandler,
+	projectHandler *handler.ProjectHandler,
 	importHandler *handler.ImportHandler,
 ) {
 	// 健康检查
@@ -54,6 +59,56 @@ func RegisterRoutes(
 		api.GET("/member", statsHandler.GetMemberStats)
 		api.GET("/languages", statsHandler.GetLanguageStats)
 		api.GET("/commits", statsHandler.GetMemberCommits)
+		api.GET("/project", statsHandler.GetProjectStats)
+		api.GET("/namespace", statsHandler.GetNamespaceStats)
+		api.GET("/leaderboard", statsHandler.GetLeaderboard)
+		api.GET("/compare", statsHandler.GetComparison)
+		api.GET("/timeseries", statsHandler.GetTimeSeries)
+		api.GET("/heatmap", statsHandler.GetHeatmap)
+		api.GET("/hotspots", statsHandler.GetHotspots)
+		api.GET("/ownership", statsHandler.GetOwnership)
+		api.GET("/ownership/inactive", statsHandler.GetInactiveOwnership)
+		api.GET("/codeowners", codeOwnersHandler.GetCodeOwnersStats)
+		api.GET("/commit-types", statsHandler.GetCommitTypes)
+		api.GET("/references", statsHandler.GetReferenceCoverage)
+		api.GET("/code-categories", statsHandler.GetCodeCategories)
+		api.GET("/pushers", statsHandler.GetPusherStats)
+		api.GET("/reverts", statsHandler.GetRevertStats)
+	}
+
+	// 工单关联 API（工单号可能包含 /，如 group/project#123，用通配参数接收，由处理器截取 /commits 之前的部分）
+	r.GET("/api/issues/*key", statsHandler.GetIssueCommits)
+
+	// 提交查询 API 路由组
+	commits := r.Group("/api/commits")
+	{
+		commits.GET("", commitHandler.SearchCommits)
+		commits.GET("/:sha", commitHandler.GetCommit)
+		commits.GET("/:sha/equivalents", commitHandler.GetEquivalentCommits)
+	}
+
+	// 团队管理 API 路由组
+	teams := r.Group("/api/teams")
+	{
+		teams.GET("", teamHandler.ListTeams)
+		teams.GET("/:name", teamHandler.GetTeam)
+		teams.PUT("/:name", teamHandler.SaveTeam)
+		teams.DELETE("/:name", teamHandler.DeleteTeam)
+	}
+
+	// CODEOWNERS API 路由组
+	codeOwners := r.Group("/api/codeowners")
+	{
+		codeOwners.GET("", codeOwnersHandler.GetCodeOwners)
+		codeOwners.POST("/sync", codeOwnersHandler.SyncCodeOwners)
+	}
+
+	// 项目配置 API 路由组（项目路径包含斜杠，通过 project_path 参数传递）
+	projects := r.Group("/api/projects")
+	{
+		projects.GET("", projectHandler.ListProjects)
+		projects.GET("/file-rules", fileRuleHandler.GetFileRules)
+		projects.PUT("/file-rules", fileRuleHandler.SaveFileRules)
 	}
 
 	// 导入 API 路由组（仅在 importHandler 不为 nil 时注册）
@@ -0,0 +1,462 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gitlabClient "gitlab-webhook-server/internal/gitlab"
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// unownedAreaKey 未被任何 CODEOWNERS 规则覆盖的文件
+const unownedAreaKey = "(unowned)"
+
+// CodeOwnersService CODEOWNERS 同步与归属合规统计服务
+type CodeOwnersService struct {
+	logger       *zap.Logger
+	repo         *repository.CodeOwnersRepository
+	statsRepo    *repository.StatsRepository
+	teamRepo     *repository.TeamRepository
+	gitlabClient *gitlabClient.Client
+	mirrorRoot   string
+}
+
+// NewCodeOwnersService 创建新的 CODEOWNERS 服务
+// gitlabClient 为 nil 时只能从本地镜像（mirrorRoot）读取
+func NewCodeOwnersService(
+	db *gorm.DB,
+	gitlabClient *gitlabClient.Client,
+	mirrorRoot string,
+	logger *zap.Logger,
+) *CodeOwnersService {
+	return &CodeOwnersService{
+		logger:       logger,
+		repo:         repository.NewCodeOwnersRepository(db, logger),
+		statsRepo:    repository.NewStatsRepository(db, logger),
+		teamRepo:     repository.NewTeamRepository(db, logger),
+		gitlabClient: gitlabClient,
+		mirrorRoot:   mirrorRoot,
+	}
+}
+
+// SyncCodeOwnersRequest CODEOWNERS 同步请求
+type SyncCodeOwnersRequest struct {
+	ProjectID   string `json:"project_id"`   // 平台项目 ID（API 来源时可代替 project_path）
+	ProjectPath string `json:"project_path"` // 项目完整路径，如 group/project
+	Ref         string `json:"ref"`          // 分支或标签，为空时使用默认分支
+	Source      string `json:"source"`       // api | local，默认 api
+}
+
+// Sync 拉取项目的 CODEOWNERS 文件并保存快照
+func (s *CodeOwnersService) Sync(req *SyncCodeOwnersRequest) (*model.CodeOwnersFile, error) {
+	if req.Source == "" {
+		req.Source = model.CodeOwnersSourceAPI
+	}
+
+	var (
+		file *model.CodeOwnersFile
+		err  error
+	)
+	switch req.Source {
+	case model.CodeOwnersSourceAPI:
+		file, err = s.fetchFromAPI(req)
+	case model.CodeOwnersSourceLocal:
+		file, err = s.readFromMirror(req)
+	default:
+		return nil, fmt.Errorf("不支持的 CODEOWNERS 来源: %s", req.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := utils.ParseCodeOwners(file.Content); err != nil {
+		return nil, err
+	}
+	file.FetchedAt = time.Now().UTC()
+	if err := s.repo.Save(file); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("CODEOWNERS 已同步",
+		zap.String("project_path", file.ProjectPath),
+		zap.String("source", file.Source),
+		zap.String("file_path", file.FilePath),
+	)
+	return file, nil
+}
+
+// fetchFromAPI 通过平台 API 获取 CODEOWNERS
+func (s *CodeOwnersService) fetchFromAPI(req *SyncCodeOwnersRequest) (*model.CodeOwnersFile, error) {
+	if s.gitlabClient == nil {
+		return nil, fmt.Errorf("未配置 GitLab API，无法通过 API 获取 CODEOWNERS")
+	}
+	pid := req.ProjectID
+	if pid == "" {
+		pid = req.ProjectPath
+	}
+	if pid == "" {
+		return nil, fmt.Errorf("project_id 或 project_path 必须指定一个")
+	}
+
+	project, _, err := s.gitlabClient.GetProject(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, location := range utils.CodeOwnersLocations {
+		content, resp, err := s.gitlabClient.GetRawFile(pid, location, req.Ref)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return nil, err
+		}
+		projectID := project.ID
+		return &model.CodeOwnersFile{
+			ProjectID:   &projectID,
+			ProjectPath: project.PathWithNamespace,
+			Source:      model.CodeOwnersSourceAPI,
+			FilePath:    location,
+			Ref:         req.Ref,
+			Content:     string(content),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("项目 %s 中未找到 CODEOWNERS 文件", project.PathWithNamespace)
+}
+
+// readFromMirror 从本地仓库镜像读取 CODEOWNERS（<mirrorRoot>/<project_path>/...）
+func (s *CodeOwnersService) readFromMirror(req *SyncCodeOwnersRequest) (*model.CodeOwnersFile, error) {
+	if s.mirrorRoot == "" {
+		return nil, fmt.Errorf("未配置 CODEOWNERS_MIRROR_ROOT，无法从本地镜像读取")
+	}
+	projectPath := strings.Trim(req.ProjectPath, "/")
+	if projectPath == "" {
+		return nil, fmt.Errorf("从本地镜像读取时 project_path 必填")
+	}
+
+	root := filepath.Clean(s.mirrorRoot)
+	repoDir := filepath.Join(root, filepath.FromSlash(projectPath))
+	if !strings.HasPrefix(repoDir, root+string(filepath.Separator)) {
+		return nil, fmt.Errorf("project_path 无效: %s", req.ProjectPath)
+	}
+
+	var projectID *int
+	if req.ProjectID != "" {
+		id, err := strconv.Atoi(req.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("project_id 必须为整数")
+		}
+		projectID = &id
+	}
+
+	for _, location := range utils.CodeOwnersLocations {
+		content, err := os.ReadFile(filepath.Join(repoDir, filepath.FromSlash(location)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("读取 CODEOWNERS 失败: %w", err)
+		}
+		return &model.CodeOwnersFile{
+			ProjectID:   projectID,
+			ProjectPath: projectPath,
+			Source:      model.CodeOwnersSourceLocal,
+			FilePath:    location,
+			Ref:         req.Ref,
+			Content:     string(content),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("本地镜像 %s 中未找到 CODEOWNERS 文件", projectPath)
+}
+
+// GetCodeOwners 获取项目的 CODEOWNERS 快照及解析后的规则，未同步时返回 nil
+func (s *CodeOwnersService) GetCodeOwners(projectID *int, projectPath string) (*model.CodeOwnersFile, *utils.CodeOwners, error) {
+	file, err := s.repo.Get(projectID, projectPath)
+	if err != nil || file == nil {
+		return nil, nil, err
+	}
+	co, err := utils.ParseCodeOwners(file.Content)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, co, nil
+}
+
+// AuthorChurn 作者在某个归属区域的变动行数
+type AuthorChurn struct {
+	Email string `json:"email"`
+	Churn int    `json:"churn"`
+}
+
+// CodeOwnersArea 一条 CODEOWNERS 规则（组件）覆盖范围内的变更统计
+type CodeOwnersArea struct {
+	Key             string         `json:"key"`
+	Section         string         `json:"section,omitempty"`
+	Pattern         string         `json:"pattern"`
+	Owners          []string       `json:"owners"`
+	Files           int            `json:"files"`
+	Changes         int            `json:"changes"`
+	OwnerChanges    int            `json:"owner_changes"`
+	NonOwnerChanges int            `json:"non_owner_changes"`
+	Churn           int            `json:"churn"`
+	OwnerChurn      int            `json:"owner_churn"`
+	NonOwnerChurn   int            `json:"non_owner_churn"`
+	NonOwnerRatio   float64        `json:"non_owner_ratio"`
+	TopNonOwners    []*AuthorChurn `json:"top_non_owners"`
+
+	files     map[string]bool
+	nonOwners map[string]int
+}
+
+// CodeOwnersOwnerStats 一个负责人（用户、组或邮箱）名下所有区域的变更统计
+type CodeOwnersOwnerStats struct {
+	Owner         string  `json:"owner"`
+	Members       int     `json:"members"` // 解析到的提交邮箱数量
+	Areas         int     `json:"areas"`
+	Changes       int     `json:"changes"`
+	Churn         int     `json:"churn"`
+	OwnerChurn    int     `json:"owner_churn"`
+	NonOwnerChurn int     `json:"non_owner_churn"`
+	NonOwnerRatio float64 `json:"non_owner_ratio"`
+
+	areas map[string]bool
+}
+
+// CodeOwnersReport 归属合规报告
+type CodeOwnersReport struct {
+	ProjectPath string                  `json:"project_path"`
+	Source      string                  `json:"source"`
+	FilePath    string                  `json:"file_path"`
+	FetchedAt   time.Time               `json:"fetched_at"`
+	Areas       []*CodeOwnersArea       `json:"areas"`
+	Owners      []*CodeOwnersOwnerStats `json:"owners"`
+	// UnresolvedOwners 无法映射到任何提交邮箱的负责人（其区域的变更都会计为非负责人）
+	UnresolvedOwners []string `json:"unresolved_owners"`
+}
+
+// GetReport 按 CODEOWNERS 规则统计负责人与非负责人的变更占比
+// filter 需限定到单个项目；未同步 CODEOWNERS 时返回 nil
+func (s *CodeOwnersService) GetReport(filter *repository.StatsFilter) (*CodeOwnersReport, error) {
+	file, co, err := s.GetCodeOwners(filter.ProjectID, filter.ProjectPath)
+	if err != nil || file == nil {
+		return nil, err
+	}
+
+	changes, err := s.statsRepo.GetFileChanges(filter, "")
+	if err != nil {
+		return nil, err
+	}
+
+	handles := make(map[string]bool)
+	for _, rule := range co.Rules {
+		for _, owner := range rule.Owners {
+			handles[owner] = true
+		}
+	}
+	ownerEmails, err := s.resolveOwners(handles)
+	if err != nil {
+		return nil, err
+	}
+
+	report := buildCodeOwnersReport(co, changes, ownerEmails)
+	report.ProjectPath = file.ProjectPath
+	report.Source = file.Source
+	report.FilePath = file.FilePath
+	report.FetchedAt = file.FetchedAt
+	return report, nil
+}
+
+// resolveOwners 将负责人映射为提交邮箱
+// 邮箱直接使用；@名称 依次匹配团队名（完整路径或最后一段）和推送记录中的用户名
+func (s *CodeOwnersService) resolveOwners(handles map[string]bool) (map[string]map[string]bool, error) {
+	teams, err := s.teamRepo.ListTeams()
+	if err != nil {
+		return nil, err
+	}
+	teamMembers := make(map[string][]string, len(teams))
+	for _, team := range teams {
+		for _, m := range team.Members {
+			teamMembers[team.Name] = append(teamMembers[team.Name], m.MemberEmail)
+		}
+	}
+
+	var usernames []string
+	for handle := range handles {
+		if strings.HasPrefix(handle, "@") {
+			usernames = append(usernames, strings.TrimPrefix(handle, "@"))
+		}
+	}
+	userEmails, err := s.repo.GetUsernameEmails(usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]bool, len(handles))
+	for handle := range handles {
+		emails := make(map[string]bool)
+		if !strings.HasPrefix(handle, "@") {
+			emails[handle] = true
+		} else {
+			name := strings.TrimPrefix(handle, "@")
+			for _, email := range teamMembers[name] {
+				emails[email] = true
+			}
+			for _, email := range teamMembers[path.Base(name)] {
+				emails[email] = true
+			}
+			for _, email := range userEmails[name] {
+				emails[email] = true
+			}
+		}
+		result[handle] = emails
+	}
+	return result, nil
+}
+
+// buildCodeOwnersReport 汇总每条规则（组件）和每个负责人的变更
+func buildCodeOwnersReport(
+	co *utils.CodeOwners,
+	changes []*repository.FileChange,
+	ownerEmails map[string]map[string]bool,
+) *CodeOwnersReport {
+	areas := make(map[string]*CodeOwnersArea)
+	owners := make(map[string]*CodeOwnersOwnerStats)
+	report := &CodeOwnersReport{
+		Areas:            []*CodeOwnersArea{},
+		Owners:           []*CodeOwnersOwnerStats{},
+		UnresolvedOwners: []string{},
+	}
+
+	area := func(key, section, pattern string, ruleOwners []string) *CodeOwnersArea {
+		a, ok := areas[key]
+		if !ok {
+			a = &CodeOwnersArea{
+				Key:       key,
+				Section:   section,
+				Pattern:   pattern,
+				Owners:    ruleOwners,
+				files:     make(map[string]bool),
+				nonOwners: make(map[string]int),
+			}
+			areas[key] = a
+			report.Areas = append(report.Areas, a)
+		}
+		return a
+	}
+
+	for _, c := range changes {
+		churn := c.AddedLines + c.RemovedLines
+		rules := co.Match(c.FilePath)
+		if len(rules) == 0 {
+			a := area(unownedAreaKey, "", "", []string{})
+			a.record(c.FilePath, c.AuthorEmail, churn, false)
+			continue
+		}
+
+		for _, rule := range rules {
+			isOwner := false
+			for _, owner := range rule.Owners {
+				if ownerEmails[owner][c.AuthorEmail] {
+					isOwner = true
+					break
+				}
+			}
+			a := area(rule.Key(), rule.Section, rule.Pattern, rule.Owners)
+			a.record(c.FilePath, c.AuthorEmail, churn, isOwner)
+
+			for _, owner := range rule.Owners {
+				o, ok := owners[owner]
+				if !ok {
+					o = &CodeOwnersOwnerStats{
+						Owner:   owner,
+						Members: len(ownerEmails[owner]),
+						areas:   make(map[string]bool),
+					}
+					owners[owner] = o
+					report.Owners = append(report.Owners, o)
+				}
+				o.areas[rule.Key()] = true
+				o.Changes++
+				o.Churn += churn
+				if ownerEmails[owner][c.AuthorEmail] {
+					o.OwnerChurn += churn
+				} else {
+					o.NonOwnerChurn += churn
+				}
+			}
+		}
+	}
+
+	for _, a := range report.Areas {
+		a.Files = len(a.files)
+		if a.Churn > 0 {
+			a.NonOwnerRatio = float64(a.NonOwnerChurn) / float64(a.Churn)
+		}
+		a.TopNonOwners = topAuthorChurn(a.nonOwners, 5)
+	}
+	for _, o := range report.Owners {
+		o.Areas = len(o.areas)
+		if o.Churn > 0 {
+			o.NonOwnerRatio = float64(o.NonOwnerChurn) / float64(o.Churn)
+		}
+	}
+
+	sort.SliceStable(report.Areas, func(i, j int) bool { return report.Areas[i].Churn > report.Areas[j].Churn })
+	sort.SliceStable(report.Owners, func(i, j int) bool { return report.Owners[i].Churn > report.Owners[j].Churn })
+
+	for handle, emails := range ownerEmails {
+		if len(emails) == 0 {
+			report.UnresolvedOwners = append(report.UnresolvedOwners, handle)
+		}
+	}
+	sort.Strings(report.UnresolvedOwners)
+
+	return report
+}
+
+// record 累加一次文件变更
+func (a *CodeOwnersArea) record(filePath, email string, churn int, isOwner bool) {
+	a.files[filePath] = true
+	a.Changes++
+	a.Churn += churn
+	if isOwner {
+		a.OwnerChanges++
+		a.OwnerChurn += churn
+		return
+	}
+	a.NonOwnerChanges++
+	a.NonOwnerChurn += churn
+	a.nonOwners[email] += churn
+}
+
+// topAuthorChurn 按变动行数取前 n 名作者
+func topAuthorChurn(churn map[string]int, n int) []*AuthorChurn {
+	result := make([]*AuthorChurn, 0, len(churn))
+	for email, lines := range churn {
+		result = append(result, &AuthorChurn{Email: email, Churn: lines})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Churn != result[j].Churn {
+			return result[i].Churn > result[j].Churn
+		}
+		return result[i].Email < result[j].Email
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/utils"
+)
+
+func TestBuildCodeOwnersReport(t *testing.T) {
+	co, err := utils.ParseCodeOwners("/api/ @backend\n/web/ @frontend fe@example.com\n")
+	if err != nil {
+		t.Fatalf("ParseCodeOwners() error = %v", err)
+	}
+	changes := []*repository.FileChange{
+		{FilePath: "api/a.go", AddedLines: 10, AuthorEmail: "be@example.com"},
+		{FilePath: "api/b.go", AddedLines: 5, RemovedLines: 5, AuthorEmail: "fe@example.com"},
+		{FilePath: "web/app.ts", AddedLines: 8, AuthorEmail: "fe@example.com"},
+		{FilePath: "README.md", AddedLines: 1, AuthorEmail: "be@example.com"},
+	}
+	ownerEmails := map[string]map[string]bool{
+		"@backend":       {"be@example.com": true},
+		"@frontend":      {},
+		"fe@example.com": {"fe@example.com": true},
+	}
+
+	report := buildCodeOwnersReport(co, changes, ownerEmails)
+	if len(report.Areas) != 3 {
+		t.Fatalf("len(Areas) = %d, want 3", len(report.Areas))
+	}
+
+	api := report.Areas[0]
+	if api.Key != "/api/" || api.Churn != 20 || api.OwnerChurn != 10 || api.NonOwnerChurn != 10 {
+		t.Errorf("api area = %+v", api)
+	}
+	if len(api.TopNonOwners) != 1 || api.TopNonOwners[0].Email != "fe@example.com" {
+		t.Errorf("api top non owners = %+v", api.TopNonOwners)
+	}
+	if web := report.Areas[1]; web.OwnerChurn != 8 || web.NonOwnerRatio != 0 {
+		t.Errorf("web area = %+v", web)
+	}
+	if unowned := report.Areas[2]; unowned.Key != unownedAreaKey || unowned.NonOwnerChurn != 1 {
+		t.Errorf("unowned area = %+v", unowned)
+	}
+
+	if len(report.UnresolvedOwners) != 1 || report.UnresolvedOwners[0] != "@frontend" {
+		t.Errorf("UnresolvedOwners = %v", report.UnresolvedOwners)
+	}
+}
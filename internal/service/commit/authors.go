@@ -0,0 +1,47 @@
+package commit
+
+import (
+	"fmt"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// buildAuthors 构造提交的作者列表：提交作者在前，随后是提交说明中 Co-authored-by 声明的共同作者
+func buildAuthors(author, authorEmail, message string) []model.CommitAuthor {
+	authors := []model.CommitAuthor{{
+		Email:     truncateString(authorEmail, 255),
+		Name:      truncateString(author, 255),
+		IsPrimary: true,
+	}}
+	for _, co := range utils.ParseCoAuthors(message, authorEmail) {
+		authors = append(authors, model.CommitAuthor{
+			Email: truncateString(co.Email, 255),
+			Name:  truncateString(co.Name, 255),
+		})
+	}
+	return authors
+}
+
+// replaceAuthors 按当前提交说明重建提交的作者列表（tx 须为事务）
+func replaceAuthors(tx *gorm.DB, commit *model.Commit) error {
+	authors := buildAuthors(commit.Author, commit.AuthorEmail, commit.Message)
+	if err := tx.Where("commit_id = ?", commit.ID).Delete(&model.CommitAuthor{}).Error; err != nil {
+		return fmt.Errorf("清理提交 %d 的作者失败: %w", commit.ID, err)
+	}
+	for i := range authors {
+		authors[i].CommitID = commit.ID
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&authors).Error; err != nil {
+		return fmt.Errorf("保存提交 %d 的作者失败: %w", commit.ID, err)
+	}
+	if err := tx.Model(&model.Commit{}).
+		Where("id = ?", commit.ID).
+		Update("author_count", len(authors)).Error; err != nil {
+		return fmt.Errorf("更新提交 %d 的作者数失败: %w", commit.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package commit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// newCommitBranch 根据推送记录构造分支记录，没有分支信息（如标签推送）时返回 nil
+func newCommitBranch(record *model.CommitRecord) *model.CommitBranch {
+	if record.Branch == "" || strings.HasPrefix(record.Branch, "refs/") {
+		return nil
+	}
+	return &model.CommitBranch{
+		Branch:      truncateString(record.Branch, 255),
+		IsDefault:   record.ProjectDefaultBranch != "" && record.Branch == record.ProjectDefaultBranch,
+		FirstSeenAt: time.Now().UTC(),
+	}
+}
+
+// recordBranch 为已入库的提交记录新出现的分支，已记录过的分支保留首次出现时间
+func (s *CommitServiceV2) recordBranch(commitID uint64, record *model.CommitRecord) error {
+	branch := newCommitBranch(record)
+	if branch == nil {
+		return nil
+	}
+	branch.CommitID = commitID
+
+	res := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(branch)
+	if res.Error != nil {
+		return fmt.Errorf("记录提交分支失败: %w", res.Error)
+	}
+	if res.RowsAffected > 0 {
+		s.logger.Info("提交出现在新分支",
+			zap.String("commit_id", record.CommitID),
+			zap.String("branch", branch.Branch),
+			zap.Bool("default_branch", branch.IsDefault),
+		)
+	}
+	return nil
+}
@@ -2,10 +2,12 @@ package commit
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"gitlab-webhook-server/internal/model"
 	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/service/rollup"
 	"gitlab-webhook-server/internal/utils"
 
 	"go.uber.org/zap"
@@ -14,26 +16,38 @@ import (
 
 // CommitServiceV2 提交服务 V2（带数据库支持）
 type CommitServiceV2 struct {
-	logger   *zap.Logger
-	repo     *repository.CommitRepository
-	db       *gorm.DB
+	logger     *zap.Logger
+	repo       *repository.CommitRepository
+	rollupRepo *repository.RollupRepository
+	statsRepo  *repository.StatsRepository
+	rollup     *rollup.Engine
+	fileRuleRepo *repository.FileRuleRepository
+	db         *gorm.DB
 }
 
 // NewCommitServiceV2 创建新的提交服务 V2
 func NewCommitServiceV2(db *gorm.DB, logger *zap.Logger) *CommitServiceV2 {
 	return &CommitServiceV2{
-		logger: logger,
-		repo:   repository.NewCommitRepository(db, logger),
-		db:     db,
+		logger:     logger,
+		repo:       repository.NewCommitRepository(db, logger),
+		rollupRepo: repository.NewRollupRepository(db, logger),
+		statsRepo:  repository.NewStatsRepository(db, logger),
+		rollup:     rollup.NewEngine(db, logger),
+		fileRuleRepo: repository.NewFileRuleRepository(db, logger),
+		db:         db,
 	}
 }
 
 // RecordCommit 记录代码提交（完整版本，包含行数和语言统计）
 // commitRecord 可以包含 DiffStats 字段来传递行数信息
 func (s *CommitServiceV2) RecordCommit(commitRecord *model.CommitRecord) error {
-	// 检查是否已存在（使用 commit_id + project_id 唯一性）
+	// 检查是否已存在（使用 commit_id + project_id + 平台实例唯一性）
+	if commitRecord.Platform == "" {
+		commitRecord.Platform = model.DefaultPlatform
+	}
 	var existing model.Commit
-	query := s.db.Where("commit_id = ?", commitRecord.CommitID)
+	query := s.db.Where("commit_id = ?", commitRecord.CommitID).
+		Where("platform = ? AND instance = ?", commitRecord.Platform, commitRecord.Instance)
 	
 	if commitRecord.ProjectID != nil {
 		query = query.Where("project_id = ?", *commitRecord.ProjectID)
@@ -43,28 +57,33 @@ func (s *CommitServiceV2) RecordCommit(commitRecord *model.CommitRecord) error {
 	
 	err := query.First(&existing).Error
 	if err == nil {
-		s.logger.Info("提交记录已存在，跳过",
+		s.logger.Info("提交记录已存在，跳过入库",
 			zap.String("commit_id", commitRecord.CommitID),
 			zap.Any("project_id", commitRecord.ProjectID),
+			zap.String("platform", commitRecord.Platform),
 		)
-		return nil
+		if err := s.syncProject(commitRecord); err != nil {
+			return err
+		}
+		if err := linkPush(s.db, existing.ID, commitRecord); err != nil {
+			return err
+		}
+		// 同一提交合并后会再次出现在目标分支，只补充分支记录和推送关联
+		return s.recordBranch(existing.ID, commitRecord)
 	}
 	if err != gorm.ErrRecordNotFound {
 		return fmt.Errorf("查询提交记录失败: %w", err)
 	}
 
-	// 解析时间戳
-	timestamp, err := time.Parse(time.RFC3339, commitRecord.Timestamp)
+	// 解析时间戳（保留原始时区偏移，入库统一使用 UTC）
+	timestamp, err := parseCommitTime(commitRecord)
 	if err != nil {
-		// 尝试其他格式
-		timestamp, err = time.Parse("2006-01-02T15:04:05Z07:00", commitRecord.Timestamp)
-		if err != nil {
-			s.logger.Warn("解析时间戳失败，使用当前时间",
-				zap.String("timestamp", commitRecord.Timestamp),
-				zap.Error(err),
-			)
-			timestamp = time.Now()
-		}
+		s.logger.Warn("解析时间戳失败，拒绝保存提交记录",
+			zap.String("commit_id", commitRecord.CommitID),
+			zap.String("timestamp", commitRecord.Timestamp),
+			zap.Error(err),
+		)
+		return err
 	}
 
 	// 处理 authored_date 和 committed_date
@@ -77,13 +96,35 @@ func (s *CommitServiceV2) RecordCommit(commitRecord *model.CommitRecord) error {
 		committedDate = *commitRecord.CommittedDate
 	}
 
+	// 作者所在时区相对 UTC 的偏移（分钟），优先取编写时间
+	_, offsetSeconds := authoredDate.Zone()
+	authorTZOffset := offsetSeconds / 60
+
+	timestamp = timestamp.UTC()
+	authoredDate = authoredDate.UTC()
+	committedDate = committedDate.UTC()
+
+	// 解析 Conventional Commits 标题和 trailer
+	conventional := utils.ParseConventionalCommit(commitRecord.Message)
+
 	// 创建提交记录
 	commit := &model.Commit{
 		CommitID:              commitRecord.CommitID,
 		ProjectID:             commitRecord.ProjectID,
+		Platform:              commitRecord.Platform,
+		Instance:              truncateString(commitRecord.Instance, 255),
 		Message:               commitRecord.Message,
 		Title:                 commitRecord.Title,
+		CommitType:            truncateString(conventional.Type, 47),
+		CommitScope:           truncateString(conventional.Scope, 97),
+		IsBreaking:            conventional.Breaking,
+		Trailers:              model.Trailers(conventional.Trailers),
+		ParentSHAs:            model.SHAList(commitRecord.ParentIDs),
+		IsMerge:               utils.IsMergeCommit(commitRecord.ParentIDs, commitRecord.Message),
+		PatchID:               commitRecord.PatchID,
+		CherryPickedFrom:      utils.CherryPickSource(commitRecord.Message),
 		Timestamp:             timestamp, // 保持向后兼容
+		AuthorTZOffset:        &authorTZOffset,
 		Author:                commitRecord.Author,
 		AuthorEmail:           commitRecord.AuthorEmail,
 		CommitterName:         commitRecord.CommitterName,
@@ -95,86 +136,71 @@ func (s *CommitServiceV2) RecordCommit(commitRecord *model.CommitRecord) error {
 		URL:                   commitRecord.URL,
 		ProjectName:           commitRecord.ProjectName,
 		ProjectPath:           commitRecord.ProjectPath,
-		ProjectDescription:    commitRecord.ProjectDescription,
-		ProjectWebURL:         commitRecord.ProjectWebURL,
 		ProjectNamespace:      commitRecord.ProjectNamespace,
-		ProjectVisibilityLevel: commitRecord.ProjectVisibilityLevel,
-		ProjectDefaultBranch:  commitRecord.ProjectDefaultBranch,
-		ProjectGitSSHURL:      commitRecord.ProjectGitSSHURL,
-		ProjectGitHTTPURL:     commitRecord.ProjectGitHTTPURL,
-		RepositoryName:        commitRecord.RepositoryName,
-		RepositoryURL:         commitRecord.RepositoryURL,
-		RepositoryDescription: commitRecord.RepositoryDescription,
-		RepositoryHomepage:    commitRecord.RepositoryHomepage,
-		RepositoryGitSSHURL:   commitRecord.RepositoryGitSSHURL,
-		RepositoryGitHTTPURL:  commitRecord.RepositoryGitHTTPURL,
-		RepositoryVisibilityLevel: commitRecord.RepositoryVisibilityLevel,
-		BeforeSHA:             commitRecord.BeforeSHA,
-		AfterSHA:              commitRecord.AfterSHA,
-		CheckoutSHA:           commitRecord.CheckoutSHA,
-		PushMessage:           commitRecord.PushMessage,
-		TotalCommitsCount:     commitRecord.TotalCommitsCount,
-		PushUserID:            commitRecord.PushUserID,
-		PushUserName:          commitRecord.PushUserName,
-		PushUserUsername:      commitRecord.PushUserUsername,
-		PushUserEmail:         commitRecord.PushUserEmail,
 		TotalAddedLines:       0,
 		TotalRemovedLines:      0,
 		TotalChangedFiles:      0,
 	}
 
-	// 处理文件变更
-	var totalAdded, totalRemoved int
-	languageStats := make(map[string]*LanguageFileStats)
+	// 处理文件变更（生成代码、第三方代码和锁文件单独计数，不计入总计和语言统计）
+	classifier := s.classifierFor(commitRecord.ProjectPath)
+	for _, change := range []struct {
+		changeType string
+		paths      []string
+	}{
+		{"added", commitRecord.AddedFiles},
+		{"modified", commitRecord.ModifiedFiles},
+		{"removed", commitRecord.RemovedFiles},
+	} {
+		for _, filePath := range change.paths {
+			stat := s.getFileStats(commitRecord, filePath)
+			file := s.createCommitFile(commit, filePath, change.changeType, stat)
+			file.Classification = classifier.Classify(filePath)
+			commit.Files = append(commit.Files, *file)
+		}
+	}
+	applyFileTotals(commit)
 
-	// 处理新增文件
-	for _, filePath := range commitRecord.AddedFiles {
-		addedLines, removedLines := s.getFileStats(commitRecord, filePath)
-		file := s.createCommitFile(commit, filePath, "added", addedLines, removedLines)
-		commit.Files = append(commit.Files, *file)
-		totalAdded += file.AddedLines
-		s.updateLanguageStats(languageStats, file.Language, file.AddedLines, 0, 1)
-	}
-
-	// 处理修改文件
-	for _, filePath := range commitRecord.ModifiedFiles {
-		addedLines, removedLines := s.getFileStats(commitRecord, filePath)
-		file := s.createCommitFile(commit, filePath, "modified", addedLines, removedLines)
-		commit.Files = append(commit.Files, *file)
-		totalAdded += file.AddedLines
-		totalRemoved += file.RemovedLines
-		s.updateLanguageStats(languageStats, file.Language, file.AddedLines, file.RemovedLines, 1)
-	}
-
-	// 处理删除文件
-	for _, filePath := range commitRecord.RemovedFiles {
-		addedLines, removedLines := s.getFileStats(commitRecord, filePath)
-		file := s.createCommitFile(commit, filePath, "removed", addedLines, removedLines)
-		commit.Files = append(commit.Files, *file)
-		totalRemoved += file.RemovedLines
-		s.updateLanguageStats(languageStats, file.Language, 0, file.RemovedLines, 1)
-	}
-
-	// 更新总计
-	commit.TotalAddedLines = totalAdded
-	commit.TotalRemovedLines = totalRemoved
-	commit.TotalChangedFiles = len(commit.Files)
-
-	// 创建语言统计记录
-	for lang, stats := range languageStats {
-		commit.Languages = append(commit.Languages, model.CommitLanguage{
-			Language:     lang,
-			AddedLines:   stats.AddedLines,
-			RemovedLines: stats.RemovedLines,
-			FileCount:    stats.FileCount,
-		})
+	// 提取 issue / 合并请求 / 外部工单引用
+	commit.References = buildReferences(commitRecord.Message, commitRecord.Branch, commitRecord.ProjectPath)
+	// 提交作者及 Co-authored-by 共同作者，按成员统计时每位作者都计入
+	commit.Authors = buildAuthors(commitRecord.Author, commitRecord.AuthorEmail, commitRecord.Message)
+	commit.AuthorCount = len(commit.Authors)
+	// Revert "..." / This reverts commit <sha>
+	applyRevert(commit)
+	if branch := newCommitBranch(commitRecord); branch != nil {
+		commit.Branches = []model.CommitBranch{*branch}
 	}
 
 	// 使用事务保存
+	var renamedFrom string
 	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 更新项目元数据，提交通过项目记录 ID 关联，项目重命名后历史不会拆分
+		project, from, err := upsertProject(tx, commitRecord, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if project != nil {
+			commit.ProjectRefID = &project.ID
+			renamedFrom = from
+		}
+		// cherry-pick、fork 等重复的逻辑变更只有最早入库的提交计入统计
+		if err := assignChangeGroup(tx, commit); err != nil {
+			return err
+		}
 		if err := tx.Create(commit).Error; err != nil {
 			return fmt.Errorf("保存提交记录失败: %w", err)
 		}
+		if err := linkPush(tx, commit.ID, commitRecord); err != nil {
+			return err
+		}
+		if err := linkReverts(tx, commit); err != nil {
+			return err
+		}
+		// 同一事务内增量更新聚合表
+		if err := s.rollup.Apply(tx, commit); err != nil {
+			return fmt.Errorf("更新聚合统计失败: %w", err)
+		}
 		return nil
 	}); err != nil {
 		s.logger.Error("保存提交记录失败",
@@ -184,6 +210,8 @@ func (s *CommitServiceV2) RecordCommit(commitRecord *model.CommitRecord) error {
 		return err
 	}
 
+	s.logRename(commitRecord, renamedFrom)
+
 	s.logger.Info("📝 提交记录已保存",
 		zap.String("commit_id", commit.CommitID),
 		zap.String("author", commit.Author),
@@ -197,14 +225,42 @@ func (s *CommitServiceV2) RecordCommit(commitRecord *model.CommitRecord) error {
 	return nil
 }
 
+// commitTimeFormats 提交时间戳支持的格式
+var commitTimeFormats = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05 -0700",
+}
+
+// parseCommitTime 解析提交时间戳
+// 依次尝试 Timestamp 字符串的多种格式、CommittedDate、AuthoredDate，全部不可用时返回错误
+// 不再回退到当前时间，以免错误的时间污染按日期统计的结果
+func parseCommitTime(commitRecord *model.CommitRecord) (time.Time, error) {
+	if commitRecord.Timestamp != "" {
+		for _, layout := range commitTimeFormats {
+			if t, err := time.Parse(layout, commitRecord.Timestamp); err == nil {
+				return t, nil
+			}
+		}
+	}
+	if commitRecord.CommittedDate != nil {
+		return *commitRecord.CommittedDate, nil
+	}
+	if commitRecord.AuthoredDate != nil {
+		return *commitRecord.AuthoredDate, nil
+	}
+	return time.Time{}, fmt.Errorf("无法解析提交时间戳: %q", commitRecord.Timestamp)
+}
+
 // createCommitFile 创建文件变更记录
 func (s *CommitServiceV2) createCommitFile(
 	commit *model.Commit,
 	filePath string,
 	changeType string,
-	addedLines, removedLines int,
+	stat *model.FileStat,
 ) *model.CommitFile {
-	language := utils.DetectLanguage(filePath)
+	language := utils.DetectLanguageWithContent(filePath, stat.Head)
 	extension := utils.GetFileExtension(filePath)
 	fileName := utils.GetFileName(filePath)
 
@@ -213,25 +269,101 @@ func (s *CommitServiceV2) createCommitFile(
 		FileName:      fileName,
 		FileExtension: extension,
 		ChangeType:    changeType,
-		AddedLines:    addedLines,
-		RemovedLines:  removedLines,
+		AddedLines:    stat.AddedLines,
+		RemovedLines:  stat.RemovedLines,
 		Language:      language,
+		Category:      utils.CategorizeFile(filePath),
 	}
 }
 
-// updateLanguageStats 更新语言统计
-func (s *CommitServiceV2) updateLanguageStats(
-	stats map[string]*LanguageFileStats,
-	language string,
-	added, removed int,
-	fileCount int,
-) {
-	if stats[language] == nil {
-		stats[language] = &LanguageFileStats{}
-	}
-	stats[language].AddedLines += added
-	stats[language].RemovedLines += removed
-	stats[language].FileCount += fileCount
+// syncProject 更新项目元数据（用于不新增提交的推送）
+func (s *CommitServiceV2) syncProject(record *model.CommitRecord) error {
+	var renamedFrom string
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		_, renamedFrom, err = upsertProject(tx, record, time.Now().UTC())
+		return err
+	}); err != nil {
+		return err
+	}
+	s.logRename(record, renamedFrom)
+	return nil
+}
+
+// logRename 记录项目重命名或命名空间迁移
+func (s *CommitServiceV2) logRename(record *model.CommitRecord, from string) {
+	if from == "" {
+		return
+	}
+	s.logger.Info("项目路径已变更",
+		zap.String("from", from),
+		zap.String("to", record.ProjectPath),
+		zap.Any("project_id", record.ProjectID),
+		zap.String("platform", record.Platform),
+	)
+}
+
+// classifierFor 获取项目的文件分类器（内置规则 + 项目级规则）
+// 项目规则读取失败时退回内置规则，不影响提交入库
+func (s *CommitServiceV2) classifierFor(projectPath string) *utils.FileClassifier {
+	rules, err := s.fileRuleRepo.GetRules(projectPath)
+	if err == nil {
+		if classifier, cerr := utils.NewFileClassifier(rules); cerr == nil {
+			return classifier
+		} else {
+			err = cerr
+		}
+	}
+	s.logger.Warn("加载项目文件分类规则失败，使用内置规则",
+		zap.String("project_path", projectPath),
+		zap.Error(err),
+	)
+	classifier, _ := utils.NewFileClassifier(nil)
+	return classifier
+}
+
+// applyFileTotals 根据 commit.Files 计算总计、排除行数和语言统计
+// 被分类为生成代码、第三方代码或锁文件的文件只计入 Excluded* 字段
+func applyFileTotals(commit *model.Commit) {
+	commit.TotalAddedLines, commit.TotalRemovedLines, commit.TotalChangedFiles = 0, 0, 0
+	commit.ExcludedAddedLines, commit.ExcludedRemovedLines, commit.ExcludedChangedFiles = 0, 0, 0
+	commit.Languages = nil
+
+	languageStats := make(map[string]*LanguageFileStats)
+	var languages []string
+	for i := range commit.Files {
+		file := &commit.Files[i]
+		if file.Excluded() {
+			commit.ExcludedAddedLines += file.AddedLines
+			commit.ExcludedRemovedLines += file.RemovedLines
+			commit.ExcludedChangedFiles++
+			continue
+		}
+
+		commit.TotalAddedLines += file.AddedLines
+		commit.TotalRemovedLines += file.RemovedLines
+		commit.TotalChangedFiles++
+
+		stats, ok := languageStats[file.Language]
+		if !ok {
+			stats = &LanguageFileStats{}
+			languageStats[file.Language] = stats
+			languages = append(languages, file.Language)
+		}
+		stats.AddedLines += file.AddedLines
+		stats.RemovedLines += file.RemovedLines
+		stats.FileCount++
+	}
+
+	for _, lang := range languages {
+		stats := languageStats[lang]
+		commit.Languages = append(commit.Languages, model.CommitLanguage{
+			Language:     lang,
+			AddedLines:   stats.AddedLines,
+			RemovedLines: stats.RemovedLines,
+			FileCount:    stats.FileCount,
+		})
+	}
 }
 
 // LanguageFileStats 语言文件统计
@@ -249,29 +381,96 @@ func (s *CommitServiceV2) GetMemberCommits(
 	return s.repo.GetMemberCommits(authorEmail, startDate, endDate)
 }
 
-// GetMemberStats 获取成员统计信息
-func (s *CommitServiceV2) GetMemberStats(
-	authorEmail string,
-	startDate, endDate *time.Time,
-) (*repository.MemberStats, error) {
-	return s.repo.GetMemberStats(authorEmail, startDate, endDate)
+// SearchCommits 按条件分页搜索提交
+func (s *CommitServiceV2) SearchCommits(search *repository.CommitSearch) (*repository.CommitPage, error) {
+	return s.repo.SearchCommits(search)
 }
 
-// GetLanguageStats 获取语言统计信息
-func (s *CommitServiceV2) GetLanguageStats(
-	authorEmail string,
-	startDate, endDate *time.Time,
-) ([]*repository.LanguageStats, error) {
-	return s.repo.GetLanguageStats(authorEmail, startDate, endDate)
+// rollupPeriod 选择可以读取聚合表的周期
+// 聚合表只按成员和时间汇总，口径与默认过滤条件一致（不含生成代码和合并提交的行数、等价提交只计一次）；
+// 只指定了成员和时间区间、区间与聚合周期对齐且聚合表已就绪（完整重建后没有被批量修改）时返回 true
+func (s *CommitServiceV2) rollupPeriod(filter *repository.StatsFilter) (string, bool) {
+	plain := repository.StatsFilter{AuthorEmail: filter.AuthorEmail, StartDate: filter.StartDate, EndDate: filter.EndDate}
+	if *filter != plain {
+		return "", false
+	}
+	period, ok := rollup.ChoosePeriod(filter.StartDate, filter.EndDate, s.rollup.Location())
+	if !ok {
+		return "", false
+	}
+	ready, err := s.rollup.Ready()
+	if err != nil {
+		s.logger.Warn("查询聚合表状态失败，扫描明细表", zap.Error(err))
+		return "", false
+	}
+	return period, ready
+}
+
+// GetMemberStats 获取成员统计信息（filter.AuthorEmail 为成员邮箱，含作为共同作者的提交）
+// 可以使用聚合表时读取聚合表，否则按过滤条件扫描明细表
+func (s *CommitServiceV2) GetMemberStats(filter *repository.StatsFilter) (*repository.MemberStats, error) {
+	if period, ok := s.rollupPeriod(filter); ok {
+		return s.rollupRepo.GetMemberStats(filter.AuthorEmail, period, filter.StartDate, filter.EndDate)
+	}
+	metrics, err := s.statsRepo.GetMemberMetrics(filter)
+	if err != nil {
+		return nil, err
+	}
+	stats := &repository.MemberStats{}
+	for _, m := range metrics {
+		stats.CommitCount += m.CommitCount
+		stats.TotalAdded += m.TotalAdded
+		stats.TotalRemoved += m.TotalRemoved
+		stats.TotalFiles += m.TotalFiles
+	}
+	return stats, nil
+}
+
+// GetLanguageStats 获取成员的语言统计信息（按新增行数降序）
+// 可以使用聚合表时读取聚合表，否则按过滤条件扫描明细表
+func (s *CommitServiceV2) GetLanguageStats(filter *repository.StatsFilter) ([]*repository.LanguageStats, error) {
+	if period, ok := s.rollupPeriod(filter); ok {
+		return s.rollupRepo.GetLanguageStats(filter.AuthorEmail, period, filter.StartDate, filter.EndDate)
+	}
+	rows, err := s.statsRepo.GetMemberLanguageMix(filter)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*repository.LanguageStats, 0, len(rows))
+	for _, row := range rows {
+		lang := row.LanguageStats
+		stats = append(stats, &lang)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].TotalAdded > stats[j].TotalAdded
+	})
+	return stats, nil
 }
 
 // getFileStats 获取文件统计信息
-func (s *CommitServiceV2) getFileStats(commitRecord *model.CommitRecord, filePath string) (added, removed int) {
+func (s *CommitServiceV2) getFileStats(commitRecord *model.CommitRecord, filePath string) *model.FileStat {
 	if commitRecord.FileStats != nil {
-		if stat, ok := commitRecord.FileStats[filePath]; ok {
-			return stat.AddedLines, stat.RemovedLines
+		if stat, ok := commitRecord.FileStats[filePath]; ok && stat != nil {
+			return stat
 		}
 	}
-	return 0, 0
+	return &model.FileStat{}
 }
 
+
+// buildReferences 从提交说明和分支名提取引用记录
+func buildReferences(message, branch, projectPath string) []model.CommitReference {
+	refs := utils.ExtractReferences(message, branch, projectPath)
+	result := make([]model.CommitReference, 0, len(refs))
+	for _, ref := range refs {
+		result = append(result, model.CommitReference{
+			RefType:    ref.Type,
+			RefKey:     truncateString(ref.Key, 497),
+			RefProject: truncateString(ref.Project, 497),
+			RefNumber:  truncateString(ref.Number, 47),
+			IsClosing:  ref.Closing,
+			Source:     ref.Source,
+		})
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package commit
+
+import (
+	"testing"
+
+	"gitlab-webhook-server/internal/model"
+)
+
+func TestApplyFileTotals(t *testing.T) {
+	commit := &model.Commit{
+		Files: []model.CommitFile{
+			{FilePath: "main.go", Language: "Go", AddedLines: 10, RemovedLines: 2},
+			{FilePath: "go.sum", Language: "Other", AddedLines: 5000, RemovedLines: 300, Classification: "lockfile"},
+			{FilePath: "api/api.pb.go", Language: "Go", AddedLines: 800, Classification: "generated"},
+			{FilePath: "util.go", Language: "Go", AddedLines: 4, RemovedLines: 1},
+		},
+	}
+
+	applyFileTotals(commit)
+
+	if commit.TotalAddedLines != 14 || commit.TotalRemovedLines != 3 || commit.TotalChangedFiles != 2 {
+		t.Errorf("totals = %d/%d/%d, want 14/3/2",
+			commit.TotalAddedLines, commit.TotalRemovedLines, commit.TotalChangedFiles)
+	}
+	if commit.ExcludedAddedLines != 5800 || commit.ExcludedRemovedLines != 300 || commit.ExcludedChangedFiles != 2 {
+		t.Errorf("excluded = %d/%d/%d, want 5800/300/2",
+			commit.ExcludedAddedLines, commit.ExcludedRemovedLines, commit.ExcludedChangedFiles)
+	}
+	if len(commit.Languages) != 1 {
+		t.Fatalf("languages = %+v, want only Go", commit.Languages)
+	}
+	if lang := commit.Languages[0]; lang.Language != "Go" || lang.AddedLines != 14 || lang.FileCount != 2 {
+		t.Errorf("Go stats = %+v", lang)
+	}
+}
@@ -0,0 +1,78 @@
+package commit
+
+import (
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/repository"
+)
+
+// CommitDetail 提交详情：文件变更、语言统计、出现过的分支、引用的 issue / 合并请求 / 工单、作者、项目，
+// 以及包含该提交的推送和回滚关系
+type CommitDetail struct {
+	*model.Commit
+	Pushes        []*model.Push     `json:"pushes"`
+	RevertOfSHA   string            `json:"revert_of_sha,omitempty"`   // 本提交回滚的提交
+	RevertedBySHA string            `json:"reverted_by_sha,omitempty"` // 回滚了本提交的提交
+	OtherProjects []*CommitLocation `json:"other_projects,omitempty"`  // 同一 SHA 在其他项目（如 fork）中的记录
+}
+
+// CommitLocation 同一 SHA（或短 SHA 前缀）的其他提交记录所在的项目
+type CommitLocation struct {
+	ID          uint64 `json:"id"`
+	CommitID    string `json:"commit_id"`
+	Platform    string `json:"platform"`
+	Instance    string `json:"instance"`
+	ProjectID   *int   `json:"project_id"`
+	ProjectPath string `json:"project_path"`
+}
+
+// GetCommitDetail 按 SHA 获取提交详情，scope 限定项目范围（为 nil 时查找所有项目）
+// 匹配到多条记录时（fork、短 SHA 冲突）返回最早入库的一条，其余列在 OtherProjects 中；不存在时返回 nil
+func (s *CommitServiceV2) GetCommitDetail(sha string, scope *repository.StatsFilter) (*CommitDetail, error) {
+	matches, err := s.repo.FindCommitsBySHA(sha, scope)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	commit, err := s.repo.GetCommitDetail(matches[0].ID)
+	if err != nil || commit == nil {
+		return nil, err
+	}
+	pushes, err := s.repo.GetCommitPushes(commit.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &CommitDetail{Commit: commit, Pushes: pushes}
+	var related []uint64
+	if commit.RevertOf != nil {
+		related = append(related, *commit.RevertOf)
+	}
+	if commit.RevertedBy != nil {
+		related = append(related, *commit.RevertedBy)
+	}
+	shas, err := s.repo.GetCommitSHAs(related)
+	if err != nil {
+		return nil, err
+	}
+	if commit.RevertOf != nil {
+		detail.RevertOfSHA = shas[*commit.RevertOf]
+	}
+	if commit.RevertedBy != nil {
+		detail.RevertedBySHA = shas[*commit.RevertedBy]
+	}
+
+	for _, m := range matches[1:] {
+		detail.OtherProjects = append(detail.OtherProjects, &CommitLocation{
+			ID:          m.ID,
+			CommitID:    m.CommitID,
+			Platform:    m.Platform,
+			Instance:    m.Instance,
+			ProjectID:   m.ProjectID,
+			ProjectPath: m.ProjectPath,
+		})
+	}
+	return detail, nil
+}
@@ -0,0 +1,217 @@
+package commit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gitlab-webhook-server/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// fullSHALength 完整 SHA-1 的长度，cherry-pick 来源短于该长度时按前缀匹配
+const fullSHALength = 40
+
+// defaultChangeKey 提交自身的逻辑变更键：有补丁标识时按补丁内容，否则按 SHA
+func defaultChangeKey(commit *model.Commit) string {
+	if commit.PatchID != "" {
+		return "patch:" + commit.PatchID
+	}
+	return "commit:" + commit.CommitID
+}
+
+// assignChangeGroup 查找已入库的等价提交（相同 SHA、cherry-pick 来源或相同补丁标识），
+// 找到时加入其分组并标记为重复，否则以自身作为新分组
+func assignChangeGroup(tx *gorm.DB, commit *model.Commit) error {
+	// 反向匹配：已入库的提交记录的 cherry-pick 来源可能是当前提交 SHA 的前缀（短 SHA）
+	// CONCAT 在 PostgreSQL 和 MySQL 中均可用（PostgreSQL 的 || 在 MySQL 中是逻辑或）
+	conditions := []string{
+		"commit_id = ?",
+		"(cherry_picked_from <> '' AND ? LIKE CONCAT(cherry_picked_from, '%'))",
+	}
+	args := []interface{}{commit.CommitID, commit.CommitID}
+	if from := commit.CherryPickedFrom; from != "" {
+		if len(from) < fullSHALength {
+			conditions = append(conditions, "commit_id LIKE ?")
+			args = append(args, from+"%")
+		} else {
+			conditions = append(conditions, "commit_id = ?")
+			args = append(args, from)
+		}
+	}
+	if commit.PatchID != "" {
+		conditions = append(conditions, "patch_id = ?")
+		args = append(args, commit.PatchID)
+	}
+
+	var existing model.Commit
+	err := tx.Select("id", "commit_id", "patch_id", "change_key", "duplicate_of").
+		Where("("+strings.Join(conditions, " OR ")+")", args...).
+		Order("id").
+		Take(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		commit.ChangeKey = defaultChangeKey(commit)
+		commit.DuplicateOf = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("查询等价提交失败: %w", err)
+	}
+
+	commit.ChangeKey = existing.ChangeKey
+	if commit.ChangeKey == "" {
+		commit.ChangeKey = defaultChangeKey(&existing)
+	}
+	canonical := existing.ID
+	if existing.DuplicateOf != nil {
+		canonical = *existing.DuplicateOf
+	}
+	commit.DuplicateOf = &canonical
+	return nil
+}
+
+// GetEquivalentCommits 获取与指定 SHA 属于同一逻辑变更的所有提交（含自身，按入库顺序）
+// 同一 SHA 出现在多个项目（如 fork）时一并返回
+func (s *CommitServiceV2) GetEquivalentCommits(sha string) ([]*model.Commit, error) {
+	var keys []string
+	if err := s.db.Model(&model.Commit{}).
+		Where("commit_id = ?", sha).
+		Distinct().
+		Pluck("change_key", &keys).Error; err != nil {
+		return nil, fmt.Errorf("查询提交失败: %w", err)
+	}
+
+	var commits []*model.Commit
+	query := s.db.Where("commit_id = ?", sha)
+	if len(keys) > 0 {
+		query = query.Or("change_key IN ?", keys)
+	}
+	if err := query.Preload("Branches").Order("id").Find(&commits).Error; err != nil {
+		return nil, fmt.Errorf("查询等价提交失败: %w", err)
+	}
+	return commits, nil
+}
+
+// RegroupCommits 按 SHA、cherry-pick 来源和补丁标识重新计算所有提交的等价分组
+// 入库时只能关联到已存在的提交，乱序导入或补充历史数据后需要执行一次；
+// 分组变化会影响聚合表，有提交的分组发生变化时将其标记为未就绪，完成后需要重建聚合
+// 返回分组发生变化的提交数
+func (s *CommitServiceV2) RegroupCommits(batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	var entries []*model.Commit
+	var batch []*model.Commit
+	res := s.db.Select("id", "commit_id", "patch_id", "cherry_picked_from", "change_key", "duplicate_of").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			entries = append(entries, batch...)
+			return nil
+		})
+	if res.Error != nil {
+		return 0, fmt.Errorf("加载提交失败: %w", res.Error)
+	}
+
+	// 并查集（FindInBatches 按主键顺序加载），根节点始终是组内最早入库的提交
+	parent := make([]int, len(entries))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		if ra == rb {
+			return
+		}
+		if ra < rb {
+			parent[rb] = ra
+		} else {
+			parent[ra] = rb
+		}
+	}
+
+	bySHA := make(map[string]int, len(entries))
+	byPatch := make(map[string]int)
+	shas := make([]string, 0, len(entries))
+	for i, e := range entries {
+		if first, ok := bySHA[e.CommitID]; ok {
+			union(first, i)
+		} else {
+			bySHA[e.CommitID] = i
+			shas = append(shas, e.CommitID)
+		}
+		if e.PatchID != "" {
+			if first, ok := byPatch[e.PatchID]; ok {
+				union(first, i)
+			} else {
+				byPatch[e.PatchID] = i
+			}
+		}
+	}
+	sort.Strings(shas)
+	for i, e := range entries {
+		if e.CherryPickedFrom == "" {
+			continue
+		}
+		if len(e.CherryPickedFrom) >= fullSHALength {
+			if source, ok := bySHA[e.CherryPickedFrom]; ok {
+				union(source, i)
+			}
+			continue
+		}
+		// 短 SHA 按前缀匹配
+		for k := sort.SearchStrings(shas, e.CherryPickedFrom); k < len(shas) && strings.HasPrefix(shas[k], e.CherryPickedFrom); k++ {
+			union(bySHA[shas[k]], i)
+		}
+	}
+
+	updated := 0
+	for i, e := range entries {
+		root := entries[find(i)]
+		key := defaultChangeKey(root)
+		var duplicateOf *uint64
+		if root.ID != e.ID {
+			duplicateOf = &root.ID
+		}
+		if key == e.ChangeKey && sameID(duplicateOf, e.DuplicateOf) {
+			continue
+		}
+		if updated == 0 {
+			if err := s.rollup.Invalidate("重新计算等价提交分组"); err != nil {
+				return 0, err
+			}
+		}
+		if err := s.db.Model(&model.Commit{}).
+			Where("id = ?", e.ID).
+			Updates(map[string]interface{}{
+				"change_key":   key,
+				"duplicate_of": duplicateOf,
+			}).Error; err != nil {
+			return updated, fmt.Errorf("更新提交 %d 的等价分组失败: %w", e.ID, err)
+		}
+		updated++
+	}
+
+	s.logger.Info("等价提交重新分组完成",
+		zap.Int("commits", len(entries)),
+		zap.Int("updated", updated),
+	)
+	return updated, nil
+}
+
+// sameID 判断两个可空 ID 是否相同
+func sameID(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
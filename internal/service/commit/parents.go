@@ -0,0 +1,130 @@
+package commit
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+// parseGitParents 解析 git log --format='%H %P' 的一行，返回提交 SHA 和父提交 SHA（根提交没有父提交）
+func parseGitParents(line string) (string, []string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+	return fields[0], fields[1:], true
+}
+
+// sameSHAs 判断两个 SHA 列表是否相同（顺序相关，第一个为主线父提交）
+func sameSHAs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ImportGitParents 从本地 git 仓库读取所有引用可达提交的父提交 SHA，更新已入库提交的父提交和合并提交标记
+// webhook 负载不包含父提交，webhook 入库的提交只能按提交说明判断是否为合并提交，本地有仓库克隆时用它补全；
+// 同一 SHA 出现在多个项目（如 fork）时一并更新。返回更新的提交数，有更新时聚合表标记为未就绪，需要重建
+func (s *CommitServiceV2) ImportGitParents(repoDir string, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	cmd := exec.Command("git", "-C", repoDir, "log", "--all", "--format=%H %P")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, fmt.Errorf("读取本地仓库失败: %w", err)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("执行 git log 失败: %w", err)
+	}
+
+	updated, scanned := 0, 0
+	batch := make(map[string][]string, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		shas := make([]string, 0, len(batch))
+		for sha := range batch {
+			shas = append(shas, sha)
+		}
+		var commits []*model.Commit
+		if err := s.db.Select("id", "commit_id", "message", "parent_shas", "is_merge").
+			Where("commit_id IN ?", shas).
+			Find(&commits).Error; err != nil {
+			return fmt.Errorf("查询提交失败: %w", err)
+		}
+		for _, c := range commits {
+			parents := batch[c.CommitID]
+			if sameSHAs(parents, c.ParentSHAs) {
+				continue
+			}
+			if updated == 0 {
+				if err := s.rollup.Invalidate("从本地仓库更新父提交"); err != nil {
+					return err
+				}
+			}
+			if err := s.db.Model(&model.Commit{}).
+				Where("id = ?", c.ID).
+				Updates(map[string]interface{}{
+					"parent_shas": model.SHAList(parents),
+					"is_merge":    utils.IsMergeCommit(parents, c.Message),
+				}).Error; err != nil {
+				return fmt.Errorf("更新提交 %d 的父提交失败: %w", c.ID, err)
+			}
+			updated++
+		}
+		batch = make(map[string][]string, batchSize)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		sha, parents, ok := parseGitParents(scanner.Text())
+		if !ok {
+			continue
+		}
+		batch[sha] = parents
+		scanned++
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+				return updated, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return updated, fmt.Errorf("读取 git log 输出失败: %w", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return updated, fmt.Errorf("执行 git log 失败: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if err := flush(); err != nil {
+		return updated, err
+	}
+
+	s.logger.Info("从本地仓库更新父提交完成",
+		zap.String("repo", repoDir),
+		zap.Int("scanned", scanned),
+		zap.Int("updated", updated),
+	)
+	return updated, nil
+}
@@ -0,0 +1,23 @@
+package commit
+
+import "testing"
+
+func TestParseGitParents(t *testing.T) {
+	tests := []struct {
+		line    string
+		sha     string
+		parents int
+		ok      bool
+	}{
+		{"aaaa bbbb", "aaaa", 1, true},
+		{"aaaa bbbb cccc", "aaaa", 2, true},
+		{"aaaa", "aaaa", 0, true}, // 根提交
+		{"", "", 0, false},
+	}
+	for _, tt := range tests {
+		sha, parents, ok := parseGitParents(tt.line)
+		if sha != tt.sha || len(parents) != tt.parents || ok != tt.ok {
+			t.Errorf("parseGitParents(%q) = %q, %v, %v", tt.line, sha, parents, ok)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package commit
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// upsertProject 按 (platform, instance, 项目 ID) 创建或更新项目，并记录路径历史（tx 须为事务）
+// 推送记录中为空的字段（如导入时没有的描述、地址）不覆盖已有值
+// 返回项目以及重命名前的路径（未重命名时为空）；没有项目 ID 时无法识别项目，返回 nil
+func upsertProject(tx *gorm.DB, record *model.CommitRecord, seenAt time.Time) (*model.Project, string, error) {
+	if record.ProjectID == nil || record.ProjectPath == "" {
+		return nil, "", nil
+	}
+
+	var project model.Project
+	err := tx.Where("platform = ? AND instance = ? AND external_id = ?",
+		record.Platform, record.Instance, *record.ProjectID).
+		Take(&project).Error
+	isNew := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !isNew {
+		return nil, "", fmt.Errorf("查询项目失败: %w", err)
+	}
+
+	previousPath := project.Path
+	project.Platform = record.Platform
+	project.Instance = truncateString(record.Instance, 255)
+	project.ExternalID = *record.ProjectID
+	project.Path = truncateString(record.ProjectPath, 500)
+	project.LastEventAt = seenAt
+	setIfNotEmpty(&project.Name, truncateString(record.ProjectName, 255))
+	setIfNotEmpty(&project.Namespace, truncateString(record.ProjectNamespace, 255))
+	setIfNotEmpty(&project.Description, record.ProjectDescription)
+	setIfNotEmpty(&project.WebURL, record.ProjectWebURL)
+	setIfNotEmpty(&project.DefaultBranch, truncateString(record.ProjectDefaultBranch, 255))
+	setIfNotEmpty(&project.GitSSHURL, record.ProjectGitSSHURL)
+	setIfNotEmpty(&project.GitHTTPURL, record.ProjectGitHTTPURL)
+	setIfNotEmpty(&project.RepositoryName, truncateString(record.RepositoryName, 255))
+	setIfNotEmpty(&project.RepositoryURL, record.RepositoryURL)
+	setIfNotEmpty(&project.RepositoryDescription, record.RepositoryDescription)
+	setIfNotEmpty(&project.RepositoryHomepage, record.RepositoryHomepage)
+	setIfNotEmpty(&project.RepositoryGitSSHURL, record.RepositoryGitSSHURL)
+	setIfNotEmpty(&project.RepositoryGitHTTPURL, record.RepositoryGitHTTPURL)
+	if record.ProjectVisibilityLevel != nil {
+		project.VisibilityLevel = record.ProjectVisibilityLevel
+	}
+	if record.RepositoryVisibilityLevel != nil {
+		project.RepositoryVisibilityLevel = record.RepositoryVisibilityLevel
+	}
+
+	if isNew {
+		// 并发处理同一项目的推送时可能已被其他请求创建，回滚到保存点后改为更新
+		if err := tx.SavePoint("upsert_project").Error; err != nil {
+			return nil, "", fmt.Errorf("创建保存点失败: %w", err)
+		}
+		if err := tx.Create(&project).Error; err != nil {
+			if rbErr := tx.RollbackTo("upsert_project").Error; rbErr != nil {
+				return nil, "", fmt.Errorf("创建项目失败: %w", err)
+			}
+			var existing model.Project
+			if tx.Where("platform = ? AND instance = ? AND external_id = ?",
+				project.Platform, project.Instance, project.ExternalID).
+				Take(&existing).Error != nil {
+				return nil, "", fmt.Errorf("创建项目失败: %w", err)
+			}
+			project.ID = existing.ID
+			project.CreatedAt = existing.CreatedAt
+			isNew = false
+			previousPath = existing.Path
+		}
+	}
+	if !isNew {
+		if err := tx.Save(&project).Error; err != nil {
+			return nil, "", fmt.Errorf("更新项目失败: %w", err)
+		}
+	}
+
+	// 路径历史：已记录的路径只更新最近出现时间
+	path := &model.ProjectPathHistory{
+		ProjectRefID: project.ID,
+		Path:         project.Path,
+		Namespace:    project.Namespace,
+		FirstSeenAt:  seenAt,
+		LastSeenAt:   seenAt,
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_ref_id"}, {Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_seen_at"}),
+	}).Create(path).Error; err != nil {
+		return nil, "", fmt.Errorf("记录项目路径失败: %w", err)
+	}
+
+	if !isNew && previousPath != project.Path {
+		return &project, previousPath, nil
+	}
+	return &project, "", nil
+}
+
+// setIfNotEmpty 非空时覆盖
+func setIfNotEmpty(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
@@ -0,0 +1,86 @@
+package commit
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordPush 记录推送事件（webhook 入库提交前调用），webhook 重投时返回已记录的推送
+// 推送 webhook 不带推送时间，未指定 PushedAt 时记为处理时间
+func (s *CommitServiceV2) RecordPush(record *model.PushRecord) (*model.Push, error) {
+	if record.Platform == "" {
+		record.Platform = model.DefaultPlatform
+	}
+	if record.PushedAt.IsZero() {
+		record.PushedAt = time.Now().UTC()
+	}
+	projectID := 0
+	if record.ProjectID != nil {
+		projectID = *record.ProjectID
+	}
+
+	push := &model.Push{
+		Platform:          record.Platform,
+		Instance:          truncateString(record.Instance, 255),
+		ProjectID:         projectID,
+		ProjectPath:       truncateString(record.ProjectPath, 500),
+		Ref:               truncateString(record.Ref, 255),
+		RefProtected:      record.RefProtected != nil && *record.RefProtected,
+		BeforeSHA:         truncateString(record.BeforeSHA, 64),
+		AfterSHA:          truncateString(record.AfterSHA, 64),
+		CheckoutSHA:       truncateString(record.CheckoutSHA, 64),
+		Message:           record.Message,
+		TotalCommitsCount: record.TotalCommitsCount,
+		PusherID:          record.PusherID,
+		PusherName:        truncateString(record.PusherName, 255),
+		PusherUsername:    truncateString(record.PusherUsername, 255),
+		PusherEmail:       truncateString(record.PusherEmail, 255),
+		PushedAt:          record.PushedAt.UTC(),
+	}
+
+	res := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(push)
+	if res.Error != nil {
+		return nil, fmt.Errorf("保存推送记录失败: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		var existing model.Push
+		if err := s.db.Where("platform = ? AND instance = ? AND project_id = ? AND ref = ? AND before_sha = ? AND after_sha = ?",
+			push.Platform, push.Instance, push.ProjectID, push.Ref, push.BeforeSHA, push.AfterSHA).
+			Take(&existing).Error; err != nil {
+			return nil, fmt.Errorf("查询推送记录失败: %w", err)
+		}
+		s.logger.Info("推送记录已存在（webhook 重投）",
+			zap.Uint64("push_id", existing.ID),
+			zap.String("ref", existing.Ref),
+			zap.String("after", existing.AfterSHA),
+		)
+		return &existing, nil
+	}
+
+	s.logger.Info("推送记录已保存",
+		zap.Uint64("push_id", push.ID),
+		zap.String("project", push.ProjectPath),
+		zap.String("ref", push.Ref),
+		zap.String("pusher", push.PusherUsername),
+		zap.Int("total_commits", push.TotalCommitsCount),
+	)
+	return push, nil
+}
+
+// linkPush 关联提交与推送，同一提交出现在多次推送中时分别关联
+func linkPush(tx *gorm.DB, commitID uint64, record *model.CommitRecord) error {
+	if record.PushID == nil {
+		return nil
+	}
+	link := &model.PushCommit{PushID: *record.PushID, CommitID: commitID}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(link).Error; err != nil {
+		return fmt.Errorf("关联推送记录失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package commit
+
+import (
+	"fmt"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RecomputeFilesOptions 需要重新计算的变更文件字段
+type RecomputeFilesOptions struct {
+	Classification bool // 按当前规则（内置 + 项目级）重新分类生成代码、第三方代码和锁文件
+	Languages      bool // 按当前语言映射重新检测语言
+	Categories     bool // 按当前规则重新判断文件类别（测试 / 生产 / 文档 / 配置 / 构建）
+}
+
+// RecomputeFiles 重新计算历史提交变更文件的分类、语言和类别
+// 并重新计算提交的行数总计、排除行数和语言统计；
+// 分类或语言会影响聚合表，开始前将其标记为未就绪，完成后需要重建聚合（文件类别不参与聚合）
+// 返回处理的提交数
+func (s *CommitServiceV2) RecomputeFiles(batchSize int, opts RecomputeFilesOptions) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if opts.Classification || opts.Languages {
+		if err := s.rollup.Invalidate("重新计算变更文件分类和语言"); err != nil {
+			return 0, err
+		}
+	}
+
+	classifiers := make(map[string]*utils.FileClassifier)
+	detector := utils.GetLanguageDetector()
+	processed, updated := 0, 0
+	var batch []*model.Commit
+	res := s.db.Select("id", "project_path").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			if err := s.loadFiles(batch); err != nil {
+				return err
+			}
+			for _, commit := range batch {
+				classifier, ok := classifiers[commit.ProjectPath]
+				if !ok && opts.Classification {
+					classifier = s.classifierFor(commit.ProjectPath)
+					classifiers[commit.ProjectPath] = classifier
+				}
+				// 分类和语言都未变化时统计结果也不变，跳过写入
+				changed := false
+				for i := range commit.Files {
+					file := &commit.Files[i]
+					if opts.Classification {
+						if class := classifier.Classify(file.FilePath); class != file.Classification {
+							file.Classification = class
+							changed = true
+						}
+					}
+					if opts.Categories {
+						if category := utils.CategorizeFile(file.FilePath); category != file.Category {
+							file.Category = category
+							changed = true
+						}
+					}
+					if opts.Languages {
+						if lang := redetectLanguage(detector, file); lang != file.Language {
+							file.Language = lang
+							changed = true
+						}
+					}
+				}
+				if !changed {
+					continue
+				}
+				if err := s.saveFileTotals(commit); err != nil {
+					return err
+				}
+				updated++
+			}
+			processed += len(batch)
+			s.logger.Info("变更文件重新计算进度",
+				zap.Int("processed", processed),
+				zap.Int("updated", updated),
+			)
+			return nil
+		})
+	if res.Error != nil {
+		return processed, fmt.Errorf("重新计算变更文件失败: %w", res.Error)
+	}
+	return processed, nil
+}
+
+// redetectLanguage 根据路径重新检测语言
+// 历史数据没有保存文件内容，路径无法确定语言时（如 .h、无扩展名脚本）保留入库时基于内容的结果
+func redetectLanguage(detector *utils.LanguageDetector, file *model.CommitFile) string {
+	lang, certain := detector.DetectByPath(file.FilePath)
+	if !certain && file.Language != "" && file.Language != utils.LanguageUnknown {
+		return file.Language
+	}
+	return lang
+}
+
+// loadFiles 批量加载提交的变更文件
+func (s *CommitServiceV2) loadFiles(commits []*model.Commit) error {
+	ids := make([]uint64, 0, len(commits))
+	byID := make(map[uint64]*model.Commit, len(commits))
+	for _, commit := range commits {
+		commit.Files = nil
+		ids = append(ids, commit.ID)
+		byID[commit.ID] = commit
+	}
+
+	var files []model.CommitFile
+	if err := s.db.Where("commit_id IN ?", ids).Order("id").Find(&files).Error; err != nil {
+		return fmt.Errorf("查询变更文件失败: %w", err)
+	}
+	for _, file := range files {
+		if commit, ok := byID[file.CommitID]; ok {
+			commit.Files = append(commit.Files, file)
+		}
+	}
+	return nil
+}
+
+// saveFileTotals 根据 commit.Files 重新计算并保存提交的行数总计、文件分类和语言统计
+func (s *CommitServiceV2) saveFileTotals(commit *model.Commit) error {
+	applyFileTotals(commit)
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, file := range commit.Files {
+			if err := tx.Model(&model.CommitFile{}).
+				Where("id = ?", file.ID).
+				Updates(map[string]interface{}{
+					"classification": file.Classification,
+					"language":       file.Language,
+					"category":       file.Category,
+				}).Error; err != nil {
+				return fmt.Errorf("更新提交 %d 的变更文件失败: %w", commit.ID, err)
+			}
+		}
+
+		if err := tx.Model(&model.Commit{}).
+			Where("id = ?", commit.ID).
+			Updates(map[string]interface{}{
+				"total_added_lines":      commit.TotalAddedLines,
+				"total_removed_lines":    commit.TotalRemovedLines,
+				"total_changed_files":    commit.TotalChangedFiles,
+				"excluded_added_lines":   commit.ExcludedAddedLines,
+				"excluded_removed_lines": commit.ExcludedRemovedLines,
+				"excluded_changed_files": commit.ExcludedChangedFiles,
+			}).Error; err != nil {
+			return fmt.Errorf("更新提交 %d 的行数统计失败: %w", commit.ID, err)
+		}
+
+		if err := tx.Where("commit_id = ?", commit.ID).Delete(&model.CommitLanguage{}).Error; err != nil {
+			return fmt.Errorf("清理提交 %d 的语言统计失败: %w", commit.ID, err)
+		}
+		for i := range commit.Languages {
+			commit.Languages[i].CommitID = commit.ID
+		}
+		if len(commit.Languages) > 0 {
+			if err := tx.Create(&commit.Languages).Error; err != nil {
+				return fmt.Errorf("保存提交 %d 的语言统计失败: %w", commit.ID, err)
+			}
+		}
+		return nil
+	})
+}
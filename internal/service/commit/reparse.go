@@ -0,0 +1,84 @@
+package commit
+
+import (
+	"fmt"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReparseMessages 按当前规则重新解析历史提交说明
+// 包括 Conventional Commits 类型、scope、破坏性变更、trailer、issue / 工单引用、合并提交标记、cherry-pick 来源、回滚声明以及共同作者
+// 回滚声明变化后需要执行 LinkReverts 重新关联回滚关系
+// 合并提交标记和共同作者变化会影响聚合表，开始前将其标记为未就绪，完成后需要重建聚合
+// 返回处理的提交数
+func (s *CommitServiceV2) ReparseMessages(batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if err := s.rollup.Invalidate("重新解析提交说明"); err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	var batch []*model.Commit
+	res := s.db.Select("id", "message", "author", "author_email", "branch", "project_path", "parent_shas").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			for _, commit := range batch {
+				if err := s.reparseCommit(commit); err != nil {
+					return err
+				}
+			}
+			processed += len(batch)
+			s.logger.Info("提交说明重新解析进度", zap.Int("processed", processed))
+			return nil
+		})
+	if res.Error != nil {
+		return processed, fmt.Errorf("重新解析提交说明失败: %w", res.Error)
+	}
+	return processed, nil
+}
+
+// reparseCommit 重新解析单个提交的说明并替换其引用记录和作者列表
+func (s *CommitServiceV2) reparseCommit(commit *model.Commit) error {
+	conventional := utils.ParseConventionalCommit(commit.Message)
+	references := buildReferences(commit.Message, commit.Branch, commit.ProjectPath)
+	applyRevert(commit)
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Commit{}).
+			Where("id = ?", commit.ID).
+			Updates(map[string]interface{}{
+				"commit_type":        truncateString(conventional.Type, 47),
+				"commit_scope":       truncateString(conventional.Scope, 97),
+				"is_breaking":        conventional.Breaking,
+				"trailers":           model.Trailers(conventional.Trailers),
+				"is_merge":           utils.IsMergeCommit(commit.ParentSHAs, commit.Message),
+				"cherry_picked_from": utils.CherryPickSource(commit.Message),
+				"is_revert":          commit.IsRevert,
+				"reverts_sha":        commit.RevertsSHA,
+			}).Error; err != nil {
+			return fmt.Errorf("更新提交 %d 失败: %w", commit.ID, err)
+		}
+
+		if err := replaceAuthors(tx, commit); err != nil {
+			return err
+		}
+
+		if err := tx.Where("commit_id = ?", commit.ID).Delete(&model.CommitReference{}).Error; err != nil {
+			return fmt.Errorf("清理提交 %d 的引用失败: %w", commit.ID, err)
+		}
+		for i := range references {
+			references[i].CommitID = commit.ID
+		}
+		if len(references) > 0 {
+			if err := tx.Create(&references).Error; err != nil {
+				return fmt.Errorf("保存提交 %d 的引用失败: %w", commit.ID, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,267 @@
+package commit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// applyRevert 按提交说明设置回滚标记和声明的被回滚提交
+func applyRevert(commit *model.Commit) {
+	commit.IsRevert, commit.RevertsSHA = false, ""
+	if info := utils.ParseRevert(commit.Message); info != nil {
+		commit.IsRevert = true
+		commit.RevertsSHA = info.SHA
+	}
+}
+
+// sameProject 限定为与提交属于同一项目的提交（回滚只在项目内关联）
+func sameProject(tx *gorm.DB, commit *model.Commit) *gorm.DB {
+	query := tx.Model(&model.Commit{}).
+		Where("platform = ? AND instance = ? AND id <> ?", commit.Platform, commit.Instance, commit.ID)
+	if commit.ProjectRefID != nil {
+		return query.Where("project_ref_id = ?", *commit.ProjectRefID)
+	}
+	return query.Where("project_path = ?", commit.ProjectPath)
+}
+
+// linkReverts 关联新入库提交的回滚关系（需在提交保存之后调用）
+// 回滚提交按声明的 SHA 查找被回滚提交，没有 SHA 时按标题匹配此前最近一次未被回滚的同名提交；
+// 被回滚提交晚于回滚提交入库（如乱序导入）时，反向关联已入库的回滚提交
+func linkReverts(tx *gorm.DB, commit *model.Commit) error {
+	if commit.IsRevert {
+		target, err := findRevertTarget(tx, commit)
+		if err != nil {
+			return err
+		}
+		if target != nil {
+			if err := setRevertPair(tx, target.ID, commit.ID, target.RevertedBy == nil); err != nil {
+				return err
+			}
+			commit.RevertOf = &target.ID
+		}
+	}
+
+	conditions := []string{"reverts_sha IN ?"}
+	args := []interface{}{shaPrefixes(commit.CommitID)}
+	if commit.Title != "" {
+		conditions = append(conditions, "(reverts_sha = '' AND title = ? AND timestamp >= ?)")
+		args = append(args, `Revert "`+commit.Title+`"`, commit.Timestamp)
+	}
+	var revert model.Commit
+	err := sameProject(tx, commit).
+		Select("id").
+		Where("is_revert = ? AND revert_of IS NULL", true).
+		Where("("+strings.Join(conditions, " OR ")+")", args...).
+		Order("timestamp").
+		Take(&revert).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("查询回滚提交失败: %w", err)
+	}
+	if err := setRevertPair(tx, commit.ID, revert.ID, commit.RevertedBy == nil); err != nil {
+		return err
+	}
+	if commit.RevertedBy == nil {
+		commit.RevertedBy = &revert.ID
+	}
+	return nil
+}
+
+// findRevertTarget 查找回滚提交对应的被回滚提交，找不到时返回 nil
+func findRevertTarget(tx *gorm.DB, commit *model.Commit) (*model.Commit, error) {
+	query := sameProject(tx, commit).Select("id", "reverted_by")
+	if commit.RevertsSHA != "" {
+		if len(commit.RevertsSHA) < fullSHALength {
+			query = query.Where("commit_id LIKE ?", commit.RevertsSHA+"%")
+		} else {
+			query = query.Where("commit_id = ?", commit.RevertsSHA)
+		}
+		query = query.Order("id")
+	} else {
+		info := utils.ParseRevert(commit.Message)
+		if info == nil || info.Title == "" {
+			return nil, nil
+		}
+		query = query.
+			Where("title = ? AND reverted_by IS NULL AND timestamp <= ?", info.Title, commit.Timestamp).
+			Order("timestamp DESC")
+	}
+
+	var target model.Commit
+	err := query.Take(&target).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询被回滚提交失败: %w", err)
+	}
+	return &target, nil
+}
+
+// setRevertPair 记录回滚关系；同一提交被多次回滚时 reverted_by 只保留第一次
+func setRevertPair(tx *gorm.DB, targetID, revertID uint64, markTarget bool) error {
+	if err := tx.Model(&model.Commit{}).
+		Where("id = ?", revertID).
+		Update("revert_of", targetID).Error; err != nil {
+		return fmt.Errorf("更新回滚提交 %d 失败: %w", revertID, err)
+	}
+	if !markTarget {
+		return nil
+	}
+	if err := tx.Model(&model.Commit{}).
+		Where("id = ? AND reverted_by IS NULL", targetID).
+		Update("reverted_by", revertID).Error; err != nil {
+		return fmt.Errorf("更新被回滚提交 %d 失败: %w", targetID, err)
+	}
+	return nil
+}
+
+// shaPrefixes 返回 SHA 及其所有可能被引用的短 SHA（至少 7 位）
+func shaPrefixes(sha string) []string {
+	sha = strings.ToLower(sha)
+	if len(sha) < 7 {
+		return []string{sha}
+	}
+	prefixes := make([]string, 0, len(sha)-6)
+	for n := 7; n <= len(sha); n++ {
+		prefixes = append(prefixes, sha[:n])
+	}
+	return prefixes
+}
+
+// revertProjectKey 回滚关联的项目范围
+func revertProjectKey(c *model.Commit) string {
+	if c.ProjectRefID != nil {
+		return fmt.Sprintf("%s\x00%s\x00ref:%d", c.Platform, c.Instance, *c.ProjectRefID)
+	}
+	return c.Platform + "\x00" + c.Instance + "\x00path:" + c.ProjectPath
+}
+
+// LinkReverts 按提交说明中的回滚声明重新计算所有提交的回滚关系
+// 入库时只能关联到已存在的提交，乱序导入、补充历史数据或 -messages 重新解析后需要执行一次
+// 返回回滚关系发生变化的提交数
+func (s *CommitServiceV2) LinkReverts(batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	var entries []*model.Commit
+	var batch []*model.Commit
+	res := s.db.Select("id", "commit_id", "title", "platform", "instance", "project_path", "project_ref_id",
+		"is_revert", "reverts_sha", "revert_of", "reverted_by", "timestamp").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			entries = append(entries, batch...)
+			return nil
+		})
+	if res.Error != nil {
+		return 0, fmt.Errorf("加载提交失败: %w", res.Error)
+	}
+
+	revertOf, revertedBy := resolveReverts(entries)
+
+	updated := 0
+	for i, e := range entries {
+		if sameID(revertOf[i], e.RevertOf) && sameID(revertedBy[i], e.RevertedBy) {
+			continue
+		}
+		if err := s.db.Model(&model.Commit{}).
+			Where("id = ?", e.ID).
+			Updates(map[string]interface{}{
+				"revert_of":   revertOf[i],
+				"reverted_by": revertedBy[i],
+			}).Error; err != nil {
+			return updated, fmt.Errorf("更新提交 %d 的回滚关系失败: %w", e.ID, err)
+		}
+		updated++
+	}
+
+	s.logger.Info("回滚关系重新关联完成",
+		zap.Int("commits", len(entries)),
+		zap.Int("updated", updated),
+	)
+	return updated, nil
+}
+
+// resolveReverts 计算每个提交的 revert_of / reverted_by（与 linkReverts 的匹配规则一致）
+// 回滚提交按时间顺序处理，标题匹配时取此前最近一次尚未被回滚的同名提交
+func resolveReverts(entries []*model.Commit) (revertOf, revertedBy []*uint64) {
+	revertOf = make([]*uint64, len(entries))
+	revertedBy = make([]*uint64, len(entries))
+
+	type projectIndex struct {
+		bySHA   map[string]int
+		shas    []string
+		byTitle map[string][]int
+	}
+	projects := make(map[string]*projectIndex)
+	var reverts []int
+	for i, e := range entries {
+		key := revertProjectKey(e)
+		p, ok := projects[key]
+		if !ok {
+			p = &projectIndex{bySHA: make(map[string]int), byTitle: make(map[string][]int)}
+			projects[key] = p
+		}
+		sha := strings.ToLower(e.CommitID)
+		if _, ok := p.bySHA[sha]; !ok {
+			p.bySHA[sha] = i
+			p.shas = append(p.shas, sha)
+		}
+		if e.Title != "" {
+			p.byTitle[e.Title] = append(p.byTitle[e.Title], i)
+		}
+		if e.IsRevert {
+			reverts = append(reverts, i)
+		}
+	}
+	for _, p := range projects {
+		sort.Strings(p.shas)
+		for _, candidates := range p.byTitle {
+			sort.SliceStable(candidates, func(a, b int) bool {
+				return entries[candidates[a]].Timestamp.Before(entries[candidates[b]].Timestamp)
+			})
+		}
+	}
+	sort.SliceStable(reverts, func(a, b int) bool {
+		return entries[reverts[a]].Timestamp.Before(entries[reverts[b]].Timestamp)
+	})
+
+	for _, r := range reverts {
+		e := entries[r]
+		p := projects[revertProjectKey(e)]
+		target := -1
+		if e.RevertsSHA != "" {
+			// 短 SHA 按前缀匹配，取第一个
+			if k := sort.SearchStrings(p.shas, e.RevertsSHA); k < len(p.shas) && strings.HasPrefix(p.shas[k], e.RevertsSHA) {
+				target = p.bySHA[p.shas[k]]
+			}
+		} else if info := utils.ParseRevert(e.Title); info != nil && info.Title != "" {
+			candidates := p.byTitle[info.Title]
+			for k := len(candidates) - 1; k >= 0; k-- {
+				c := candidates[k]
+				if c != r && revertedBy[c] == nil && !entries[c].Timestamp.After(e.Timestamp) {
+					target = c
+					break
+				}
+			}
+		}
+		if target < 0 || target == r {
+			continue
+		}
+		revertOf[r] = &entries[target].ID
+		if revertedBy[target] == nil {
+			revertedBy[target] = &e.ID
+		}
+	}
+	return revertOf, revertedBy
+}
@@ -0,0 +1,59 @@
+package commit
+
+import (
+	"testing"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+)
+
+func TestResolveReverts(t *testing.T) {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	commit := func(id uint64, sha, title, path string, hours int) *model.Commit {
+		c := &model.Commit{
+			ID:          id,
+			CommitID:    sha,
+			Title:       title,
+			Message:     title,
+			Platform:    "gitlab",
+			ProjectPath: path,
+			Timestamp:   base.Add(time.Duration(hours) * time.Hour),
+		}
+		applyRevert(c)
+		return c
+	}
+
+	entries := []*model.Commit{
+		commit(1, "aaaa1111aaaa1111aaaa1111aaaa1111aaaa1111", "feat: 导出", "g/app", 0),
+		commit(2, "bbbb2222bbbb2222bbbb2222bbbb2222bbbb2222", `Revert "feat: 导出"`, "g/app", 1),
+		commit(3, "cccc3333cccc3333cccc3333cccc3333cccc3333", "fix: 缓存", "g/app", 2),
+		commit(4, "dddd4444dddd4444dddd4444dddd4444dddd4444", `Revert "fix: 缓存"`, "g/app", 3),
+		// 其他项目中的同名提交不会被关联
+		commit(5, "eeee5555eeee5555eeee5555eeee5555eeee5555", "fix: 缓存", "g/other", 1),
+		commit(6, "ffff6666ffff6666ffff6666ffff6666ffff6666", `Revert "fix: 不存在"`, "g/app", 4),
+	}
+	// 2 声明了短 SHA，4 只有标题
+	entries[1].Message += "\n\nThis reverts commit aaaa111."
+	applyRevert(entries[1])
+
+	revertOf, revertedBy := resolveReverts(entries)
+
+	wantRevertOf := map[int]uint64{1: 1, 3: 3}
+	wantRevertedBy := map[int]uint64{0: 2, 2: 4}
+	for i := range entries {
+		if want, ok := wantRevertOf[i]; ok {
+			if revertOf[i] == nil || *revertOf[i] != want {
+				t.Errorf("commit %d revert_of = %v, want %d", entries[i].ID, revertOf[i], want)
+			}
+		} else if revertOf[i] != nil {
+			t.Errorf("commit %d revert_of = %d, want nil", entries[i].ID, *revertOf[i])
+		}
+		if want, ok := wantRevertedBy[i]; ok {
+			if revertedBy[i] == nil || *revertedBy[i] != want {
+				t.Errorf("commit %d reverted_by = %v, want %d", entries[i].ID, revertedBy[i], want)
+			}
+		} else if revertedBy[i] != nil {
+			t.Errorf("commit %d reverted_by = %d, want nil", entries[i].ID, *revertedBy[i])
+		}
+	}
+}
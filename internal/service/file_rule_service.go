@@ -0,0 +1,58 @@
+package service
+
+import (
+	"fmt"
+
+	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// FileRuleService 项目级文件分类规则服务
+type FileRuleService struct {
+	logger *zap.Logger
+	repo   *repository.FileRuleRepository
+}
+
+// NewFileRuleService 创建新的文件分类规则服务
+func NewFileRuleService(db *gorm.DB, logger *zap.Logger) *FileRuleService {
+	return &FileRuleService{
+		logger: logger,
+		repo:   repository.NewFileRuleRepository(db, logger),
+	}
+}
+
+// SaveFileRulesRequest 保存文件分类规则请求
+type SaveFileRulesRequest struct {
+	ProjectPath string                `json:"project_path" binding:"required"`
+	Rules       []utils.FileClassRule `json:"rules"`
+	// GitAttributes .gitattributes 文件内容，其中的 linguist-generated / linguist-vendored 规则追加在 Rules 之后
+	GitAttributes string `json:"gitattributes"`
+}
+
+// GetRules 获取项目的文件分类规则
+func (s *FileRuleService) GetRules(projectPath string) ([]utils.FileClassRule, error) {
+	return s.repo.GetRules(projectPath)
+}
+
+// SaveRules 校验并整体替换项目的文件分类规则
+// 新规则只影响之后入库的提交，历史提交需执行 reclassify -files 重新分类
+func (s *FileRuleService) SaveRules(req *SaveFileRulesRequest) ([]utils.FileClassRule, error) {
+	rules := append([]utils.FileClassRule{}, req.Rules...)
+	rules = append(rules, utils.ParseGitAttributes(req.GitAttributes)...)
+
+	if _, err := utils.NewFileClassifier(rules); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveRules(req.ProjectPath, rules); err != nil {
+		return nil, fmt.Errorf("保存项目 %s 的文件分类规则失败: %w", req.ProjectPath, err)
+	}
+
+	s.logger.Info("项目文件分类规则已更新",
+		zap.String("project_path", req.ProjectPath),
+		zap.Int("rules", len(rules)),
+	)
+	return rules, nil
+}
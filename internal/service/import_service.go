@@ -91,6 +91,13 @@ func (s *ImportService) ImportProjectCommits(
 				result.Failed++
 				continue
 			}
+			// 与 webhook 推送的同一提交使用相同的项目标识，避免重复入库
+			commitRecord.ProjectID = &project.ID
+			commitRecord.Platform = model.DefaultPlatform
+			commitRecord.Instance = utils.InstanceHost(project.WebURL)
+			// 未指定 ref 时 GitLab 返回默认分支的提交
+			commitRecord.Branch = project.DefaultBranch
+			commitRecord.ProjectDefaultBranch = project.DefaultBranch
 
 			// 获取 diff 信息（包含行数统计）
 			if diffs, err := s.gitlabClient.GetCommitDiff(projectID, commit.ID); err == nil {
@@ -143,8 +150,13 @@ func (s *ImportService) convertGitLabCommit(
 	commit *gitlab.Commit,
 	projectName, projectPath, projectID string,
 ) (*model.CommitRecord, error) {
-	// 解析时间
-	timestamp := commit.CommittedDate.Format(time.RFC3339)
+	// 解析时间（保留原始时区偏移）
+	var timestamp string
+	if commit.CommittedDate != nil {
+		timestamp = commit.CommittedDate.Format(time.RFC3339)
+	} else if commit.AuthoredDate != nil {
+		timestamp = commit.AuthoredDate.Format(time.RFC3339)
+	}
 
 	// 获取作者信息
 	authorName := "unknown"
@@ -162,20 +174,29 @@ func (s *ImportService) convertGitLabCommit(
 	removedFiles := make([]string, 0)
 
 	return &model.CommitRecord{
-		CommitID:      commit.ID,
-		Message:       commit.Message,
-		Timestamp:     timestamp,
-		Author:        authorName,
-		AuthorEmail:   authorEmail,
-		URL:           commit.WebURL,
-		ProjectName:   projectName,
-		ProjectPath:   projectPath,
-		AddedFiles:    addedFiles,
-		ModifiedFiles: modifiedFiles,
-		RemovedFiles:  removedFiles,
+		CommitID:       commit.ID,
+		ParentIDs:      commit.ParentIDs,
+		Title:          commit.Title,
+		Message:        commit.Message,
+		Timestamp:      timestamp,
+		Author:         authorName,
+		AuthorEmail:    authorEmail,
+		CommitterName:  commit.CommitterName,
+		CommitterEmail: commit.CommitterEmail,
+		AuthoredDate:   commit.AuthoredDate,
+		CommittedDate:  commit.CommittedDate,
+		URL:            commit.WebURL,
+		ProjectName:    projectName,
+		ProjectPath:    projectPath,
+		AddedFiles:     addedFiles,
+		ModifiedFiles:  modifiedFiles,
+		RemovedFiles:   removedFiles,
 	}, nil
 }
 
+// languageHeadLines 从 diff 还原的文件开头行数，用于语言判断
+const languageHeadLines = 50
+
 // enrichCommitWithDiff 使用 diff 信息丰富提交记录
 func (s *ImportService) enrichCommitWithDiff(commitRecord *model.CommitRecord, diffs []*gitlab.Diff) {
 	// 初始化 FileStats
@@ -183,7 +204,9 @@ func (s *ImportService) enrichCommitWithDiff(commitRecord *model.CommitRecord, d
 		commitRecord.FileStats = make(map[string]*model.FileStat)
 	}
 
+	fileDiffs := make([]utils.FileDiff, 0, len(diffs))
 	for _, diff := range diffs {
+		fileDiffs = append(fileDiffs, utils.FileDiff{OldPath: diff.OldPath, NewPath: diff.NewPath, Diff: diff.Diff})
 		filePath := diff.NewPath
 		if filePath == "" {
 			filePath = diff.OldPath
@@ -196,6 +219,7 @@ func (s *ImportService) enrichCommitWithDiff(commitRecord *model.CommitRecord, d
 		commitRecord.FileStats[filePath] = &model.FileStat{
 			AddedLines:   addedLines,
 			RemovedLines: removedLines,
+			Head:         utils.DiffFileHead(diff.Diff, languageHeadLines),
 		}
 
 		// 分类文件
@@ -207,6 +231,11 @@ func (s *ImportService) enrichCommitWithDiff(commitRecord *model.CommitRecord, d
 			commitRecord.ModifiedFiles = append(commitRecord.ModifiedFiles, filePath)
 		}
 	}
+
+	// 合并提交的 diff 只相对第一个父提交，不用于识别重复变更
+	if !utils.IsMergeCommit(commitRecord.ParentIDs, commitRecord.Message) {
+		commitRecord.PatchID = utils.PatchID(fileDiffs)
+	}
 }
 
 // ImportResult 导入结果
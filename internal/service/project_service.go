@@ -0,0 +1,29 @@
+package service
+
+import (
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ProjectService 项目服务
+// 项目记录在推送和导入时由提交服务自动创建和更新，这里只提供查询
+type ProjectService struct {
+	logger *zap.Logger
+	repo   *repository.ProjectRepository
+}
+
+// NewProjectService 创建新的项目服务
+func NewProjectService(db *gorm.DB, logger *zap.Logger) *ProjectService {
+	return &ProjectService{
+		logger: logger,
+		repo:   repository.NewProjectRepository(db, logger),
+	}
+}
+
+// ListProjects 获取项目列表，按曾用路径查询时返回重命名后的项目
+func (s *ProjectService) ListProjects(filter *repository.ProjectFilter) ([]*model.Project, error) {
+	return s.repo.List(filter)
+}
@@ -0,0 +1,401 @@
+package rollup
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Engine 聚合引擎
+// 负责维护 member_contributions 和 member_language_stats 两张聚合表：
+// 新提交入库时增量累加，历史数据通过 Rebuild 重建
+type Engine struct {
+	db       *gorm.DB
+	logger   *zap.Logger
+	location *time.Location
+}
+
+// NewEngine 创建新的聚合引擎（周期边界使用默认统计时区）
+func NewEngine(db *gorm.DB, logger *zap.Logger) *Engine {
+	return &Engine{
+		db:       db,
+		logger:   logger,
+		location: utils.DefaultLocation(),
+	}
+}
+
+// Location 获取周期边界使用的时区
+func (e *Engine) Location() *time.Location {
+	return e.location
+}
+
+// Ready 判断聚合表是否已完整重建且此后没有被批量修改明细的操作标记为未就绪
+// 未就绪时调用方应扫描明细表
+func (e *Engine) Ready() (bool, error) {
+	var state model.RollupState
+	err := e.db.Where("id = ?", rollupStateID).Limit(1).Find(&state).Error
+	if err != nil {
+		return false, fmt.Errorf("查询聚合表状态失败: %w", err)
+	}
+	return state.Ready, nil
+}
+
+// Invalidate 标记聚合表未就绪（批量修改明细后调用），直到下一次 RebuildAll 完成
+func (e *Engine) Invalidate(reason string) error {
+	if err := saveState(e.db, &model.RollupState{ID: rollupStateID, Reason: reason}); err != nil {
+		return err
+	}
+	e.logger.Warn("聚合表已标记为未就绪，统计查询将扫描明细表，需执行 cmd/rollup -all 重建",
+		zap.String("reason", reason))
+	return nil
+}
+
+// rollupStateID 聚合表状态行的 ID
+const rollupStateID = 1
+
+// saveState 写入聚合表状态行
+func saveState(tx *gorm.DB, state *model.RollupState) error {
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"ready", "reason", "built_at", "updated_at"}),
+	}).Create(state).Error; err != nil {
+		return fmt.Errorf("更新聚合表状态失败: %w", err)
+	}
+	return nil
+}
+
+// Apply 将一条新入库的提交累加到所有周期的聚合中
+// tx 应与保存提交记录使用同一个事务，保证聚合与明细一致
+func (e *Engine) Apply(tx *gorm.DB, commit *model.Commit) error {
+	agg := newAggregator(e.location)
+	agg.add(commit)
+	return agg.apply(tx, true)
+}
+
+// RebuildResult 重建结果
+type RebuildResult struct {
+	From          time.Time
+	To            time.Time
+	Commits       int
+	Contributions int
+	LanguageStats int
+	Duration      time.Duration
+}
+
+// Rebuild 重建 [from, to) 区间内的聚合数据
+// 区间会按每种周期向外扩展到完整周期，避免出现只覆盖一半的周/月；
+// 只重建部分区间不会改变聚合表是否就绪
+func (e *Engine) Rebuild(from, to time.Time) (*RebuildResult, error) {
+	return e.rebuild(from, to, false)
+}
+
+// rebuild 重建 [from, to) 区间内的聚合数据，markReady 为 true 时在同一事务中将聚合表标记为就绪
+func (e *Engine) rebuild(from, to time.Time, markReady bool) (*RebuildResult, error) {
+	begin := time.Now()
+	if !from.Before(to) {
+		return nil, fmt.Errorf("重建区间无效: %s ~ %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	// 每种周期各自对齐后的区间
+	ranges := make(map[string][2]time.Time, len(Periods))
+	scanFrom, scanTo := from, to
+	for _, period := range Periods {
+		start, _ := PeriodBounds(period, from, e.location)
+		_, end := PeriodBounds(period, to.Add(-time.Nanosecond), e.location)
+		ranges[period] = [2]time.Time{start, end}
+		if start.Before(scanFrom) {
+			scanFrom = start
+		}
+		if end.After(scanTo) {
+			scanTo = end
+		}
+	}
+
+	result := &RebuildResult{From: scanFrom, To: scanTo}
+
+	err := e.db.Transaction(func(tx *gorm.DB) error {
+		for _, period := range Periods {
+			r := ranges[period]
+			if err := tx.Where("period_type = ? AND start_date >= ? AND start_date < ?", period, r[0], r[1]).
+				Delete(&model.MemberContribution{}).Error; err != nil {
+				return fmt.Errorf("清理成员贡献聚合失败: %w", err)
+			}
+			if err := tx.Where("period_type = ? AND period_start >= ? AND period_start < ?", period, r[0], r[1]).
+				Delete(&model.MemberLanguageStat{}).Error; err != nil {
+				return fmt.Errorf("清理成员语言聚合失败: %w", err)
+			}
+		}
+
+		agg := newAggregator(e.location)
+		agg.ranges = ranges
+
+		var batch []*model.Commit
+		res := tx.Preload("Languages").Preload("Authors").
+			Where("timestamp >= ? AND timestamp < ?", scanFrom.UTC(), scanTo.UTC()).
+			FindInBatches(&batch, 500, func(_ *gorm.DB, _ int) error {
+				for _, commit := range batch {
+					agg.add(commit)
+				}
+				result.Commits += len(batch)
+				return nil
+			})
+		if res.Error != nil {
+			return fmt.Errorf("扫描提交记录失败: %w", res.Error)
+		}
+
+		result.Contributions = len(agg.contributions)
+		result.LanguageStats = len(agg.languages)
+		if err := agg.apply(tx, false); err != nil {
+			return err
+		}
+		if markReady {
+			return markBuilt(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Duration = time.Since(begin)
+	e.logger.Info("聚合数据重建完成",
+		zap.Time("from", result.From),
+		zap.Time("to", result.To),
+		zap.Int("commits", result.Commits),
+		zap.Int("contributions", result.Contributions),
+		zap.Int("language_stats", result.LanguageStats),
+		zap.Duration("duration", result.Duration),
+	)
+
+	return result, nil
+}
+
+// markBuilt 将聚合表标记为已完整重建
+func markBuilt(tx *gorm.DB) error {
+	now := time.Now()
+	return saveState(tx, &model.RollupState{ID: rollupStateID, Ready: true, BuiltAt: &now})
+}
+
+// RebuildAll 重建全部历史数据的聚合，完成后聚合表标记为就绪
+func (e *Engine) RebuildAll() (*RebuildResult, error) {
+	var bounds struct {
+		MinTS *time.Time
+		MaxTS *time.Time
+	}
+	if err := e.db.Model(&model.Commit{}).
+		Select("MIN(timestamp) as min_ts", "MAX(timestamp) as max_ts").
+		Scan(&bounds).Error; err != nil {
+		return nil, fmt.Errorf("查询提交时间范围失败: %w", err)
+	}
+	if bounds.MinTS == nil || bounds.MaxTS == nil {
+		// 没有提交记录时清空聚合表即为完整状态
+		err := e.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("1 = 1").Delete(&model.MemberContribution{}).Error; err != nil {
+				return fmt.Errorf("清理成员贡献聚合失败: %w", err)
+			}
+			if err := tx.Where("1 = 1").Delete(&model.MemberLanguageStat{}).Error; err != nil {
+				return fmt.Errorf("清理成员语言聚合失败: %w", err)
+			}
+			return markBuilt(tx)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &RebuildResult{}, nil
+	}
+	return e.rebuild(*bounds.MinTS, bounds.MaxTS.Add(time.Second), true)
+}
+
+// contributionKey 成员贡献聚合键
+type contributionKey struct {
+	period    string
+	start     time.Time
+	email     string
+	platform  string
+	instance  string
+	projectID int
+}
+
+// languageKey 成员语言聚合键
+type languageKey struct {
+	contributionKey
+	language string
+}
+
+// aggregator 在内存中累加聚合增量
+type aggregator struct {
+	location      *time.Location
+	ranges        map[string][2]time.Time // 非空时只保留起点落在区间内的周期
+	contributions map[contributionKey]*model.MemberContribution
+	languages     map[languageKey]*model.MemberLanguageStat
+}
+
+func newAggregator(loc *time.Location) *aggregator {
+	return &aggregator{
+		location:      loc,
+		contributions: make(map[contributionKey]*model.MemberContribution),
+		languages:     make(map[languageKey]*model.MemberLanguageStat),
+	}
+}
+
+// add 将一条提交累加到各周期，提交作者和每位共同作者各计一份
+func (a *aggregator) add(commit *model.Commit) {
+	// cherry-pick、fork 等重复的逻辑变更已由最早入库的提交计入
+	if commit.DuplicateOf != nil {
+		return
+	}
+	for _, author := range creditedAuthors(commit) {
+		a.addFor(commit, author)
+	}
+}
+
+// creditedAuthors 获取提交计入的作者（提交作者及共同作者）
+// 尚未回填作者列表的历史提交只计入提交作者
+func creditedAuthors(commit *model.Commit) []model.CommitAuthor {
+	if len(commit.Authors) > 0 {
+		return commit.Authors
+	}
+	return []model.CommitAuthor{{Email: commit.AuthorEmail, Name: commit.Author, IsPrimary: true}}
+}
+
+// addFor 将提交按某位作者分得的部分累加到各周期
+// 提交数和文件数每位作者都完整计入，行数按 CREDIT_MODE 归属
+func (a *aggregator) addFor(commit *model.Commit, author model.CommitAuthor) {
+	authors := commit.AuthorCount
+	if authors < len(commit.Authors) {
+		authors = len(commit.Authors)
+	}
+	// 没有项目信息的提交记入 project_id = 0，保证唯一索引对其同样生效
+	projectID := 0
+	if commit.ProjectID != nil {
+		projectID = *commit.ProjectID
+	}
+	for _, period := range Periods {
+		start, end := PeriodBounds(period, commit.Timestamp, a.location)
+		if r, ok := a.ranges[period]; ok && (start.Before(r[0]) || !start.Before(r[1])) {
+			continue
+		}
+
+		key := contributionKey{
+			period:    period,
+			start:     start,
+			email:     author.Email,
+			platform:  commit.Platform,
+			instance:  commit.Instance,
+			projectID: projectID,
+		}
+
+		contrib, ok := a.contributions[key]
+		if !ok {
+			contrib = &model.MemberContribution{
+				MemberEmail: author.Email,
+				ProjectID:   projectID,
+				Platform:    commit.Platform,
+				Instance:    commit.Instance,
+				PeriodType:  period,
+				StartDate:   start,
+				EndDate:     end,
+			}
+			a.contributions[key] = contrib
+		}
+		contrib.MemberName = author.Name
+		contrib.ProjectName = commit.ProjectName
+		contrib.CommitCount++
+		// 合并提交只计入提交数，行数和语言统计已由被合并的提交计入
+		if commit.IsMerge {
+			continue
+		}
+		contrib.Additions += utils.CreditLines(commit.TotalAddedLines, authors, author.IsPrimary)
+		contrib.Deletions += utils.CreditLines(commit.TotalRemovedLines, authors, author.IsPrimary)
+		contrib.FileCount += commit.TotalChangedFiles
+
+		for _, lang := range commit.Languages {
+			lkey := languageKey{contributionKey: key, language: lang.Language}
+			stat, ok := a.languages[lkey]
+			if !ok {
+				stat = &model.MemberLanguageStat{
+					MemberEmail: author.Email,
+					Language:    lang.Language,
+					ProjectID:   projectID,
+					Platform:    commit.Platform,
+					Instance:    commit.Instance,
+					PeriodType:  period,
+					PeriodStart: start,
+					PeriodEnd:   end,
+				}
+				a.languages[lkey] = stat
+			}
+			stat.LinesAdded += utils.CreditLines(lang.AddedLines, authors, author.IsPrimary)
+			stat.LinesRemoved += utils.CreditLines(lang.RemovedLines, authors, author.IsPrimary)
+			stat.FileCount += lang.FileCount
+			stat.CommitCount++
+		}
+	}
+}
+
+// apply 写入聚合结果
+// incremental 为 true 时与已有行累加（INSERT ... ON CONFLICT DO UPDATE，
+// 并发入库的提交命中同一行时由唯一索引保证只有一行），否则直接插入（调用方需已清理旧数据）
+func (a *aggregator) apply(tx *gorm.DB, incremental bool) error {
+	for _, contrib := range a.contributions {
+		db := tx
+		if incremental {
+			db = tx.Clauses(clause.OnConflict{
+				Columns: columns("member_email", "period_type", "start_date", "project_id", "platform", "instance"),
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"member_name":  contrib.MemberName,
+					"project_name": contrib.ProjectName,
+					"commit_count": accumulate("member_contributions", "commit_count", contrib.CommitCount),
+					"additions":    accumulate("member_contributions", "additions", contrib.Additions),
+					"deletions":    accumulate("member_contributions", "deletions", contrib.Deletions),
+					"file_count":   accumulate("member_contributions", "file_count", contrib.FileCount),
+					"updated_at":   time.Now(),
+				}),
+			})
+		}
+		if err := db.Create(contrib).Error; err != nil {
+			return fmt.Errorf("写入成员贡献聚合失败: %w", err)
+		}
+	}
+
+	for _, stat := range a.languages {
+		db := tx
+		if incremental {
+			db = tx.Clauses(clause.OnConflict{
+				Columns: columns("member_email", "language", "period_type", "period_start", "project_id", "platform", "instance"),
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"lines_added":   accumulate("member_language_stats", "lines_added", stat.LinesAdded),
+					"lines_removed": accumulate("member_language_stats", "lines_removed", stat.LinesRemoved),
+					"file_count":    accumulate("member_language_stats", "file_count", stat.FileCount),
+					"commit_count":  accumulate("member_language_stats", "commit_count", stat.CommitCount),
+					"updated_at":    time.Now(),
+				}),
+			})
+		}
+		if err := db.Create(stat).Error; err != nil {
+			return fmt.Errorf("写入成员语言聚合失败: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// columns 构造唯一索引列（PostgreSQL 的 ON CONFLICT 需要，MySQL 忽略）
+func columns(names ...string) []clause.Column {
+	result := make([]clause.Column, len(names))
+	for i, name := range names {
+		result[i] = clause.Column{Name: name}
+	}
+	return result
+}
+
+// accumulate 冲突时在已有行的值上累加
+func accumulate(table, column string, delta int) clause.Expr {
+	return gorm.Expr(table+"."+column+" + ?", delta)
+}
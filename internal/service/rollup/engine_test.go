@@ -0,0 +1,64 @@
+package rollup
+
+import (
+	"testing"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
+)
+
+func TestAggregatorCoAuthorCredit(t *testing.T) {
+	defer utils.SetCreditMode(utils.CreditModeFull)
+
+	commit := &model.Commit{
+		AuthorEmail:       "alice@example.com",
+		Author:            "Alice",
+		Platform:          model.DefaultPlatform,
+		Timestamp:         time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC),
+		TotalAddedLines:   10,
+		TotalRemovedLines: 3,
+		TotalChangedFiles: 2,
+		AuthorCount:       2,
+		Authors: []model.CommitAuthor{
+			{Email: "alice@example.com", Name: "Alice", IsPrimary: true},
+			{Email: "bob@example.com", Name: "Bob"},
+		},
+		Languages: []model.CommitLanguage{{Language: "go", AddedLines: 10, RemovedLines: 3, FileCount: 2}},
+	}
+
+	dayTotals := func() map[string]*model.MemberContribution {
+		agg := newAggregator(time.UTC)
+		agg.add(commit)
+		result := make(map[string]*model.MemberContribution)
+		for key, contrib := range agg.contributions {
+			if key.period == model.PeriodDay {
+				result[key.email] = contrib
+			}
+		}
+		return result
+	}
+
+	// full：两位作者都计入全部行数
+	full := dayTotals()
+	if len(full) != 2 {
+		t.Fatalf("got %d contributors, want 2", len(full))
+	}
+	for email, contrib := range full {
+		if contrib.CommitCount != 1 || contrib.Additions != 10 || contrib.Deletions != 3 || contrib.FileCount != 2 {
+			t.Errorf("full %s: %+v", email, contrib)
+		}
+	}
+
+	// split：行数均分，余数计入提交作者，提交数和文件数不拆分
+	if err := utils.SetCreditMode(utils.CreditModeSplit); err != nil {
+		t.Fatal(err)
+	}
+	split := dayTotals()
+	if c := split["alice@example.com"]; c.Additions != 5 || c.Deletions != 2 || c.CommitCount != 1 || c.FileCount != 2 {
+		t.Errorf("split alice: %+v", c)
+	}
+	if c := split["bob@example.com"]; c.Additions != 5 || c.Deletions != 1 || c.MemberName != "Bob" {
+		t.Errorf("split bob: %+v", c)
+	}
+}
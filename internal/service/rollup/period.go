@@ -0,0 +1,37 @@
+package rollup
+
+import (
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
+)
+
+// Periods 引擎维护的全部聚合周期，按粒度从细到粗排列
+var Periods = []string{model.PeriodDay, model.PeriodWeek, model.PeriodMonth}
+
+// PeriodBounds 计算 t 所在周期的起止时间（左闭右开）
+// 周以周一为起点，所有边界均按 loc 时区的零点计算
+func PeriodBounds(period string, t time.Time, loc *time.Location) (start, end time.Time) {
+	return utils.BucketBounds(period, t, loc, time.Monday)
+}
+
+// ChoosePeriod 为查询区间选择可以完整覆盖它的最粗粒度
+// startDate/endDate 为 nil 表示不设边界；区间边界与任何周期都对不齐时返回 false
+func ChoosePeriod(startDate, endDate *time.Time, loc *time.Location) (string, bool) {
+	aligned := func(period string, t *time.Time) bool {
+		if t == nil {
+			return true
+		}
+		start, _ := PeriodBounds(period, *t, loc)
+		return start.Equal(*t)
+	}
+
+	for i := len(Periods) - 1; i >= 0; i-- {
+		period := Periods[i]
+		if aligned(period, startDate) && aligned(period, endDate) {
+			return period, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,64 @@
+package rollup
+
+import (
+	"testing"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("缺少时区数据: %v", err)
+	}
+
+	// 2024-03-31 17:30 UTC 在上海已是 2024-04-01（周一）
+	ts := time.Date(2024, 3, 31, 17, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		period string
+		start  time.Time
+		end    time.Time
+	}{
+		{model.PeriodDay, time.Date(2024, 4, 1, 0, 0, 0, 0, loc), time.Date(2024, 4, 2, 0, 0, 0, 0, loc)},
+		{model.PeriodWeek, time.Date(2024, 4, 1, 0, 0, 0, 0, loc), time.Date(2024, 4, 8, 0, 0, 0, 0, loc)},
+		{model.PeriodMonth, time.Date(2024, 4, 1, 0, 0, 0, 0, loc), time.Date(2024, 5, 1, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tc := range cases {
+		start, end := PeriodBounds(tc.period, ts, loc)
+		if !start.Equal(tc.start) || !end.Equal(tc.end) {
+			t.Errorf("%s: 期望 [%s, %s)，得到 [%s, %s)", tc.period, tc.start, tc.end, start, end)
+		}
+	}
+}
+
+func TestChoosePeriod(t *testing.T) {
+	loc := time.UTC
+	date := func(y int, m time.Month, d int) *time.Time {
+		t := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		return &t
+	}
+
+	cases := []struct {
+		name   string
+		start  *time.Time
+		end    *time.Time
+		period string
+		ok     bool
+	}{
+		{"不设边界", nil, nil, model.PeriodMonth, true},
+		{"整月", date(2024, 1, 1), date(2024, 3, 1), model.PeriodMonth, true},
+		{"整周", date(2024, 1, 1), date(2024, 1, 15), model.PeriodWeek, true},
+		{"任意整天", date(2024, 1, 3), date(2024, 1, 5), model.PeriodDay, true},
+		{"非零点", func() *time.Time { t := time.Date(2024, 1, 3, 8, 0, 0, 0, loc); return &t }(), nil, "", false},
+	}
+
+	for _, tc := range cases {
+		period, ok := ChoosePeriod(tc.start, tc.end, loc)
+		if period != tc.period || ok != tc.ok {
+			t.Errorf("%s: 期望 (%q, %v)，得到 (%q, %v)", tc.name, tc.period, tc.ok, period, ok)
+		}
+	}
+}
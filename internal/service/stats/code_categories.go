@@ -0,0 +1,207 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/utils"
+)
+
+// CategoryUnclassified 尚未判断类别的历史文件（需执行 reclassify -categories 回填）
+const CategoryUnclassified = "unclassified"
+
+// ValidCategoryGroupBy 判断文件类别分布的分组维度是否受支持（空字符串表示不分组）
+func ValidCategoryGroupBy(groupBy string) bool {
+	switch groupBy {
+	case "", "member", "project":
+		return true
+	}
+	return false
+}
+
+// CategoryQuery 文件类别分布查询参数
+type CategoryQuery struct {
+	Filter    repository.StatsFilter
+	GroupBy   string // member | project，空字符串表示不分组
+	Period    string // day | week | month，空字符串表示不返回时间序列
+	Location  *time.Location
+	WeekStart time.Weekday
+}
+
+// CategoryStat 单个文件类别的行数
+type CategoryStat struct {
+	Category     string  `json:"category"`
+	AddedLines   int     `json:"added_lines"`
+	RemovedLines int     `json:"removed_lines"`
+	FileCount    int     `json:"file_count"` // 文件变更次数
+	Share        float64 `json:"share"`      // 占新增行数的比例
+}
+
+// CategoryBreakdown 文件类别分布与测试/生产代码比
+type CategoryBreakdown struct {
+	Key          string          `json:"key,omitempty"`
+	AddedLines   int             `json:"added_lines"`
+	RemovedLines int             `json:"removed_lines"`
+	Categories   []*CategoryStat `json:"categories"`
+	// TestRatio 测试代码新增行数 / 生产代码新增行数，没有生产代码变更时为 0
+	TestRatio float64 `json:"test_ratio"`
+}
+
+// CategoryBucket 时间桶内的文件类别分布
+type CategoryBucket struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	CategoryBreakdown
+}
+
+// CategoryReport 文件类别报告
+type CategoryReport struct {
+	GroupBy  string               `json:"group_by,omitempty"`
+	Period   string               `json:"period,omitempty"`
+	Timezone string               `json:"timezone,omitempty"`
+	Overall  *CategoryBreakdown   `json:"overall"`
+	Groups   []*CategoryBreakdown `json:"groups,omitempty"`
+	Series   []*CategoryBucket    `json:"series,omitempty"`
+}
+
+// GetCategoryReport 获取测试 / 生产 / 文档 / 配置 / 构建代码的行数分布
+// GroupBy 为 member / project 时同时返回每个成员或项目的分布，Period 非空时返回按时间分桶的趋势
+func (s *StatsService) GetCategoryReport(q *CategoryQuery) (*CategoryReport, error) {
+	if !ValidCategoryGroupBy(q.GroupBy) {
+		return nil, fmt.Errorf("不支持的分组维度: %s", q.GroupBy)
+	}
+	if q.Period != "" && !ValidGroupBy(q.Period) {
+		return nil, fmt.Errorf("不支持的分桶粒度: %s", q.Period)
+	}
+
+	lines, err := s.repo.GetCategoryLines(&q.Filter, q.GroupBy, q.Period != "")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CategoryReport{
+		GroupBy: q.GroupBy,
+		Period:  q.Period,
+		Overall: buildCategoryBreakdown("", lines),
+	}
+
+	if q.GroupBy != "" {
+		grouped := make(map[string][]*repository.CategoryLines)
+		var keys []string
+		for _, l := range lines {
+			if _, ok := grouped[l.GroupKey]; !ok {
+				keys = append(keys, l.GroupKey)
+			}
+			grouped[l.GroupKey] = append(grouped[l.GroupKey], l)
+		}
+		report.Groups = make([]*CategoryBreakdown, 0, len(keys))
+		for _, key := range keys {
+			report.Groups = append(report.Groups, buildCategoryBreakdown(key, grouped[key]))
+		}
+		sort.SliceStable(report.Groups, func(i, j int) bool {
+			if report.Groups[i].AddedLines != report.Groups[j].AddedLines {
+				return report.Groups[i].AddedLines > report.Groups[j].AddedLines
+			}
+			return report.Groups[i].Key < report.Groups[j].Key
+		})
+	}
+
+	if q.Period != "" {
+		loc := q.Location
+		if loc == nil {
+			loc = utils.DefaultLocation()
+		}
+		report.Timezone = loc.String()
+		series, err := buildCategorySeries(q, lines, loc)
+		if err != nil {
+			return nil, err
+		}
+		report.Series = series
+	}
+
+	return report, nil
+}
+
+// buildCategorySeries 按时间分桶汇总文件类别行数，区间内没有变更的桶也会返回
+func buildCategorySeries(q *CategoryQuery, lines []*repository.CategoryLines, loc *time.Location) ([]*CategoryBucket, error) {
+	var first, last time.Time
+	for i, l := range lines {
+		if i == 0 || l.Timestamp.Before(first) {
+			first = l.Timestamp
+		}
+		if i == 0 || l.Timestamp.After(last) {
+			last = l.Timestamp
+		}
+	}
+	if q.Filter.StartDate != nil {
+		first = *q.Filter.StartDate
+	}
+	if q.Filter.EndDate != nil {
+		last = q.Filter.EndDate.Add(-time.Nanosecond)
+	}
+	if first.IsZero() {
+		return []*CategoryBucket{}, nil
+	}
+
+	bounds, err := bucketRange(q.Period, first, last, loc, q.WeekStart)
+	if err != nil {
+		return nil, err
+	}
+	grouped := make(map[int64][]*repository.CategoryLines, len(bounds))
+	for _, l := range lines {
+		start, _ := utils.BucketBounds(q.Period, l.Timestamp, loc, q.WeekStart)
+		grouped[start.Unix()] = append(grouped[start.Unix()], l)
+	}
+
+	series := make([]*CategoryBucket, 0, len(bounds))
+	for _, b := range bounds {
+		series = append(series, &CategoryBucket{
+			Start:             b[0],
+			End:               b[1],
+			CategoryBreakdown: *buildCategoryBreakdown("", grouped[b[0].Unix()]),
+		})
+	}
+	return series, nil
+}
+
+// buildCategoryBreakdown 汇总文件类别行数（相同类别会合并）
+// 始终按固定顺序返回所有类别，未判断类别的历史文件单独列为 unclassified
+func buildCategoryBreakdown(key string, lines []*repository.CategoryLines) *CategoryBreakdown {
+	b := &CategoryBreakdown{Key: key}
+	stats := make(map[string]*CategoryStat, len(utils.FileCategories)+1)
+	for _, category := range utils.FileCategories {
+		stat := &CategoryStat{Category: category}
+		stats[category] = stat
+		b.Categories = append(b.Categories, stat)
+	}
+
+	for _, l := range lines {
+		category := l.Category
+		if category == "" {
+			category = CategoryUnclassified
+		}
+		stat, ok := stats[category]
+		if !ok {
+			stat = &CategoryStat{Category: category}
+			stats[category] = stat
+			b.Categories = append(b.Categories, stat)
+		}
+		stat.AddedLines += l.AddedLines
+		stat.RemovedLines += l.RemovedLines
+		stat.FileCount += l.FileCount
+		b.AddedLines += l.AddedLines
+		b.RemovedLines += l.RemovedLines
+	}
+
+	if b.AddedLines > 0 {
+		for _, stat := range b.Categories {
+			stat.Share = float64(stat.AddedLines) / float64(b.AddedLines)
+		}
+	}
+	if production := stats[utils.FileCategoryProduction].AddedLines; production > 0 {
+		b.TestRatio = float64(stats[utils.FileCategoryTest].AddedLines) / float64(production)
+	}
+	return b
+}
@@ -0,0 +1,69 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+func TestBuildCategoryBreakdown(t *testing.T) {
+	b := buildCategoryBreakdown("a@example.com", []*repository.CategoryLines{
+		{Category: "production", AddedLines: 100, RemovedLines: 20, FileCount: 3},
+		{Category: "test", AddedLines: 40, RemovedLines: 5, FileCount: 2},
+		{Category: "test", AddedLines: 10, FileCount: 1},
+		{Category: "", AddedLines: 50, FileCount: 1},
+	})
+
+	if b.AddedLines != 200 || b.RemovedLines != 25 {
+		t.Errorf("totals = %d/%d, want 200/25", b.AddedLines, b.RemovedLines)
+	}
+	if b.TestRatio != 0.5 {
+		t.Errorf("TestRatio = %v, want 0.5", b.TestRatio)
+	}
+
+	want := []string{"production", "test", "docs", "config", "build", CategoryUnclassified}
+	if len(b.Categories) != len(want) {
+		t.Fatalf("categories = %d, want %d", len(b.Categories), len(want))
+	}
+	for i, c := range b.Categories {
+		if c.Category != want[i] {
+			t.Errorf("Categories[%d] = %q, want %q", i, c.Category, want[i])
+		}
+	}
+	if test := b.Categories[1]; test.AddedLines != 50 || test.FileCount != 3 || test.Share != 0.25 {
+		t.Errorf("test = %+v", test)
+	}
+
+	if empty := buildCategoryBreakdown("", nil); empty.TestRatio != 0 || len(empty.Categories) != 5 {
+		t.Errorf("empty breakdown = %+v", empty)
+	}
+}
+
+func TestBuildCategorySeries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	q := &CategoryQuery{
+		Filter: repository.StatsFilter{StartDate: &start, EndDate: &end},
+		Period: "day",
+	}
+	lines := []*repository.CategoryLines{
+		{Category: "production", AddedLines: 10, Timestamp: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{Category: "test", AddedLines: 5, Timestamp: time.Date(2024, 1, 3, 18, 0, 0, 0, time.UTC)},
+		{Category: "production", AddedLines: 20, Timestamp: time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)},
+	}
+
+	series, err := buildCategorySeries(q, lines, time.UTC)
+	if err != nil {
+		t.Fatalf("buildCategorySeries() error = %v", err)
+	}
+	if len(series) != 3 {
+		t.Fatalf("buckets = %d, want 3", len(series))
+	}
+	if series[1].AddedLines != 0 {
+		t.Errorf("空桶 AddedLines = %d, want 0", series[1].AddedLines)
+	}
+	if series[2].AddedLines != 25 || series[2].TestRatio != 0.25 {
+		t.Errorf("第三天 = %d / %v, want 25 / 0.25", series[2].AddedLines, series[2].TestRatio)
+	}
+}
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+// ValidCommitTypeGroupBy 判断提交类型分布的分组维度是否受支持（空字符串表示不分组）
+func ValidCommitTypeGroupBy(groupBy string) bool {
+	switch groupBy {
+	case "", "member", "project":
+		return true
+	}
+	return false
+}
+
+// TypeCount 单个提交类型的数量
+type TypeCount struct {
+	Type  string  `json:"type"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"` // 占符合规范提交的比例
+}
+
+// CommitTypeBreakdown 提交类型分布与规范符合率
+type CommitTypeBreakdown struct {
+	Key             string       `json:"key,omitempty"`
+	Total           int          `json:"total"`
+	Conforming      int          `json:"conforming"`
+	ConformanceRate float64      `json:"conformance_rate"`
+	Breaking        int          `json:"breaking"`
+	Types           []*TypeCount `json:"types"`
+}
+
+// CommitTypeReport 提交类型报告
+type CommitTypeReport struct {
+	GroupBy string                 `json:"group_by,omitempty"`
+	Overall *CommitTypeBreakdown   `json:"overall"`
+	Groups  []*CommitTypeBreakdown `json:"groups,omitempty"`
+}
+
+// GetCommitTypes 获取 Conventional Commits 类型分布和规范符合率
+// groupBy 为 member / project 时同时返回每个成员或项目的分布
+func (s *StatsService) GetCommitTypes(filter *repository.StatsFilter, groupBy string) (*CommitTypeReport, error) {
+	if !ValidCommitTypeGroupBy(groupBy) {
+		return nil, fmt.Errorf("不支持的分组维度: %s", groupBy)
+	}
+
+	counts, err := s.repo.GetCommitTypeCounts(filter, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CommitTypeReport{
+		GroupBy: groupBy,
+		Overall: buildCommitTypeBreakdown("", counts),
+	}
+	if groupBy == "" {
+		return report, nil
+	}
+
+	grouped := make(map[string][]*repository.CommitTypeCount)
+	var keys []string
+	for _, c := range counts {
+		if _, ok := grouped[c.GroupKey]; !ok {
+			keys = append(keys, c.GroupKey)
+		}
+		grouped[c.GroupKey] = append(grouped[c.GroupKey], c)
+	}
+	report.Groups = make([]*CommitTypeBreakdown, 0, len(keys))
+	for _, key := range keys {
+		report.Groups = append(report.Groups, buildCommitTypeBreakdown(key, grouped[key]))
+	}
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Total != report.Groups[j].Total {
+			return report.Groups[i].Total > report.Groups[j].Total
+		}
+		return report.Groups[i].Key < report.Groups[j].Key
+	})
+
+	return report, nil
+}
+
+// buildCommitTypeBreakdown 汇总提交类型数量（相同类型会合并）
+func buildCommitTypeBreakdown(key string, counts []*repository.CommitTypeCount) *CommitTypeBreakdown {
+	b := &CommitTypeBreakdown{Key: key, Types: []*TypeCount{}}
+	types := make(map[string]*TypeCount)
+	for _, c := range counts {
+		b.Total += c.CommitCount
+		b.Breaking += c.BreakingCount
+		if c.CommitType == "" {
+			continue
+		}
+		b.Conforming += c.CommitCount
+		t, ok := types[c.CommitType]
+		if !ok {
+			t = &TypeCount{Type: c.CommitType}
+			types[c.CommitType] = t
+			b.Types = append(b.Types, t)
+		}
+		t.Count += c.CommitCount
+	}
+
+	if b.Total > 0 {
+		b.ConformanceRate = float64(b.Conforming) / float64(b.Total)
+	}
+	for _, t := range b.Types {
+		t.Share = float64(t.Count) / float64(b.Conforming)
+	}
+	sort.SliceStable(b.Types, func(i, j int) bool {
+		if b.Types[i].Count != b.Types[j].Count {
+			return b.Types[i].Count > b.Types[j].Count
+		}
+		return b.Types[i].Type < b.Types[j].Type
+	})
+	return b
+}
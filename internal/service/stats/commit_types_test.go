@@ -0,0 +1,26 @@
+package stats
+
+import (
+	"testing"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+func TestBuildCommitTypeBreakdown(t *testing.T) {
+	counts := []*repository.CommitTypeCount{
+		{CommitType: "feat", CommitCount: 6, BreakingCount: 1},
+		{CommitType: "fix", CommitCount: 2},
+		{CommitType: "", CommitCount: 2},
+	}
+
+	b := buildCommitTypeBreakdown("", counts)
+	if b.Total != 10 || b.Conforming != 8 || b.Breaking != 1 {
+		t.Fatalf("breakdown = %+v", b)
+	}
+	if b.ConformanceRate != 0.8 {
+		t.Errorf("ConformanceRate = %v, want 0.8", b.ConformanceRate)
+	}
+	if len(b.Types) != 2 || b.Types[0].Type != "feat" || b.Types[0].Share != 0.75 {
+		t.Errorf("Types = %+v", b.Types)
+	}
+}
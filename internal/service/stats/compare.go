@@ -0,0 +1,182 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/utils"
+)
+
+// 对比的维度
+const (
+	CompareByMember = "member"
+	CompareByTeam   = "team"
+)
+
+// MaxCompareSubjects 单次对比的最大成员 / 团队数
+const MaxCompareSubjects = 10
+
+// CompareQuery 对比查询参数
+type CompareQuery struct {
+	Filter   repository.StatsFilter // email / team 条件会被对比对象覆盖
+	By       string                 // member | team
+	Subjects []string               // 成员邮箱或团队名称
+	Location *time.Location         // 计算活跃天数使用的时区，nil 时使用默认统计时区
+}
+
+// DailyRates 按活跃天数归一化的指标（没有活跃天数时为 0）
+type DailyRates struct {
+	Commits  float64 `json:"commits"`
+	Added    float64 `json:"added"`
+	Removed  float64 `json:"removed"`
+	NetLines float64 `json:"net_lines"`
+	Files    float64 `json:"files"`
+}
+
+// CompareLanguage 对比对象在某种语言上的行数
+type CompareLanguage struct {
+	Language     string  `json:"language"`
+	TotalAdded   int     `json:"total_added"`
+	TotalRemoved int     `json:"total_removed"`
+	TotalFiles   int     `json:"total_files"`
+	Share        float64 `json:"share"` // 占该对象新增行数的比例
+}
+
+// CompareSubject 单个对比对象（成员或团队）的指标
+type CompareSubject struct {
+	Key          string             `json:"key"` // 成员邮箱或团队名称
+	Name         string             `json:"name,omitempty"`
+	Members      int                `json:"members"` // 有提交的成员数
+	CommitCount  int                `json:"commit_count"`
+	TotalAdded   int                `json:"total_added"`
+	TotalRemoved int                `json:"total_removed"`
+	NetLines     int                `json:"net_lines"`
+	TotalFiles   int                `json:"total_files"`
+	ActiveDays   int                `json:"active_days"`
+	PerActiveDay *DailyRates        `json:"per_active_day"`
+	Languages    []*CompareLanguage `json:"languages"`
+}
+
+// CompareReport 对比报告，Subjects 与请求顺序一致
+type CompareReport struct {
+	By       string            `json:"by"`
+	Subjects []*CompareSubject `json:"subjects"`
+}
+
+// Compare 在同一时间段和过滤条件下并排对比多个成员或团队的指标和语言分布
+// 指标包含作为共同作者的提交，行数按 CREDIT_MODE 归属；团队内多名成员共同完成的提交只计一次，
+// 团队活跃天数为任一成员有提交的天数
+func (s *StatsService) Compare(q *CompareQuery) (*CompareReport, error) {
+	if q.By != CompareByMember && q.By != CompareByTeam {
+		return nil, fmt.Errorf("不支持的对比维度: %s", q.By)
+	}
+	loc := q.Location
+	if loc == nil {
+		loc = utils.DefaultLocation()
+	}
+
+	report := &CompareReport{By: q.By, Subjects: make([]*CompareSubject, 0, len(q.Subjects))}
+	for _, key := range q.Subjects {
+		filter := q.Filter
+		filter.AuthorEmail, filter.Team = "", ""
+		if q.By == CompareByMember {
+			filter.AuthorEmail = key
+		} else {
+			filter.Team = key
+		}
+
+		summary, err := s.repo.GetSummary(&filter)
+		if err != nil {
+			return nil, err
+		}
+		points, err := s.repo.GetMemberCommitTimes(&filter)
+		if err != nil {
+			return nil, err
+		}
+		languages, err := s.repo.GetLanguageMix(&filter)
+		if err != nil {
+			return nil, err
+		}
+		subject := buildCompareSubject(key, summary, languages, countActiveDays(points, loc))
+
+		if q.By == CompareByMember && summary.CommitCount > 0 {
+			metrics, err := s.repo.GetMemberMetrics(&filter)
+			if err != nil {
+				return nil, err
+			}
+			if len(metrics) == 1 {
+				subject.Name = metrics[0].Name
+			}
+		}
+		report.Subjects = append(report.Subjects, subject)
+	}
+	return report, nil
+}
+
+// countActiveDays 统计提交分布在 loc 时区的自然日数（多个成员同一天提交只计一天）
+func countActiveDays(points []*repository.CommitPoint, loc *time.Location) int {
+	days := make(map[time.Time]bool)
+	for _, p := range points {
+		days[utils.StartOfDay(p.Timestamp, loc)] = true
+	}
+	return len(days)
+}
+
+// buildCompareSubject 根据对比对象的汇总指标和语言分布构建对比结果，并计算按活跃天数归一化的指标
+func buildCompareSubject(
+	key string,
+	summary *repository.SummaryStats,
+	languages []*repository.LanguageStats,
+	activeDays int,
+) *CompareSubject {
+	subject := &CompareSubject{
+		Key:          key,
+		ActiveDays:   activeDays,
+		PerActiveDay: &DailyRates{},
+		Languages:    []*CompareLanguage{},
+	}
+	if summary != nil {
+		subject.Members = summary.ActiveContributors
+		subject.CommitCount = summary.CommitCount
+		subject.TotalAdded = summary.TotalAdded
+		subject.TotalRemoved = summary.TotalRemoved
+		subject.TotalFiles = summary.TotalFiles
+	}
+	subject.NetLines = subject.TotalAdded - subject.TotalRemoved
+
+	if activeDays > 0 {
+		days := float64(activeDays)
+		subject.PerActiveDay = &DailyRates{
+			Commits:  float64(subject.CommitCount) / days,
+			Added:    float64(subject.TotalAdded) / days,
+			Removed:  float64(subject.TotalRemoved) / days,
+			NetLines: float64(subject.NetLines) / days,
+			Files:    float64(subject.TotalFiles) / days,
+		}
+	}
+
+	totalAdded := 0
+	for _, l := range languages {
+		subject.Languages = append(subject.Languages, &CompareLanguage{
+			Language:     l.Language,
+			TotalAdded:   l.TotalAdded,
+			TotalRemoved: l.TotalRemoved,
+			TotalFiles:   l.TotalFiles,
+		})
+		totalAdded += l.TotalAdded
+	}
+	for _, lang := range subject.Languages {
+		if totalAdded > 0 {
+			lang.Share = float64(lang.TotalAdded) / float64(totalAdded)
+		}
+	}
+	sort.SliceStable(subject.Languages, func(i, j int) bool {
+		if subject.Languages[i].TotalAdded != subject.Languages[j].TotalAdded {
+			return subject.Languages[i].TotalAdded > subject.Languages[j].TotalAdded
+		}
+		return subject.Languages[i].Language < subject.Languages[j].Language
+	})
+	return subject
+}
@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+func TestBuildCompareSubject(t *testing.T) {
+	// 团队汇总中成员共同完成的提交只计一次
+	summary := &repository.SummaryStats{CommitCount: 8, ActiveContributors: 2, TotalAdded: 400, TotalRemoved: 120, TotalFiles: 16}
+	languages := []*repository.LanguageStats{
+		{Language: "TypeScript", TotalAdded: 100, TotalRemoved: 40, TotalFiles: 6},
+		{Language: "Go", TotalAdded: 300, TotalRemoved: 80, TotalFiles: 10},
+	}
+
+	s := buildCompareSubject("backend", summary, languages, 4)
+	if s.Members != 2 || s.CommitCount != 8 || s.TotalAdded != 400 || s.NetLines != 280 || s.TotalFiles != 16 {
+		t.Fatalf("subject = %+v", s)
+	}
+	if s.Name != "" {
+		t.Errorf("团队不应取成员姓名, Name = %q", s.Name)
+	}
+	if r := s.PerActiveDay; r.Commits != 2 || r.Added != 100 || r.NetLines != 70 || r.Files != 4 {
+		t.Errorf("PerActiveDay = %+v", r)
+	}
+	if len(s.Languages) != 2 || s.Languages[0].Language != "Go" || s.Languages[0].TotalAdded != 300 || s.Languages[0].Share != 0.75 {
+		t.Errorf("Languages = %+v", s.Languages)
+	}
+
+	empty := buildCompareSubject("nobody@example.com", nil, nil, 0)
+	if empty.CommitCount != 0 || empty.PerActiveDay == nil || empty.PerActiveDay.Commits != 0 || empty.Languages == nil {
+		t.Errorf("empty subject = %+v", empty)
+	}
+}
+
+func TestCountActiveDays(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	points := []*repository.CommitPoint{
+		{Timestamp: time.Date(2024, 3, 1, 1, 0, 0, 0, time.UTC), AuthorEmail: "a@example.com"},
+		{Timestamp: time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC), AuthorEmail: "b@example.com"},
+		// UTC 3 月 1 日 20:00 在 UTC+8 已是 3 月 2 日
+		{Timestamp: time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC), AuthorEmail: "a@example.com"},
+	}
+	if got := countActiveDays(points, loc); got != 2 {
+		t.Errorf("countActiveDays = %d, want 2", got)
+	}
+}
@@ -0,0 +1,76 @@
+package stats
+
+import (
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/utils"
+)
+
+// 热力图时间口径
+const (
+	HeatmapModeAuthorLocal = "author_local" // 按作者提交时的本地时间
+	HeatmapModeViewer      = "viewer"       // 按查看者指定的时区
+)
+
+// HeatmapQuery 活跃热力图查询参数
+type HeatmapQuery struct {
+	Filter repository.StatsFilter
+	// Location 查看者时区；为 nil 时按每条提交的作者本地时间统计
+	Location *time.Location
+}
+
+// Heatmap 星期 × 小时的提交数矩阵
+// Matrix[weekday][hour]，weekday 与 time.Weekday 一致（0 为周日）
+type Heatmap struct {
+	Mode     string     `json:"mode"`
+	Timezone string     `json:"timezone,omitempty"`
+	Total    int        `json:"total"`
+	Matrix   [7][24]int `json:"matrix"`
+	Weekdays [7]string  `json:"weekdays"`
+	// UnknownOffset 未记录作者时区偏移、改用默认统计时区计算的提交数
+	UnknownOffset int `json:"unknown_offset"`
+}
+
+// GetHeatmap 获取活跃热力图
+func (s *StatsService) GetHeatmap(q *HeatmapQuery) (*Heatmap, error) {
+	points, err := s.repo.GetCommitPoints(&q.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	heatmap := &Heatmap{Mode: HeatmapModeAuthorLocal}
+	if q.Location != nil {
+		heatmap.Mode = HeatmapModeViewer
+		heatmap.Timezone = q.Location.String()
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		heatmap.Weekdays[d] = d.String()
+	}
+
+	for _, p := range points {
+		offset := p.AuthorTZOffset
+		if q.Location == nil && offset == nil {
+			heatmap.UnknownOffset++
+		}
+		weekday, hour := heatmapSlot(p.Timestamp, offset, q.Location)
+		heatmap.Matrix[weekday][hour]++
+		heatmap.Total++
+	}
+
+	return heatmap, nil
+}
+
+// heatmapSlot 计算提交落在热力图中的星期和小时
+// loc 非空时按 loc 计算；否则按作者偏移（分钟）计算，偏移缺失时使用默认统计时区
+func heatmapSlot(t time.Time, authorOffset *int, loc *time.Location) (time.Weekday, int) {
+	switch {
+	case loc != nil:
+		t = t.In(loc)
+	case authorOffset != nil:
+		t = t.In(time.FixedZone("", *authorOffset*60))
+	default:
+		t = t.In(utils.DefaultLocation())
+	}
+	return t.Weekday(), t.Hour()
+}
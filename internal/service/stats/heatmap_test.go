@@ -0,0 +1,34 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeatmapSlot(t *testing.T) {
+	// 2024-01-01 是周一
+	ts := time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC)
+	plus8 := 8 * 60
+	minus5 := -5 * 60
+
+	tests := []struct {
+		name        string
+		offset      *int
+		loc         *time.Location
+		wantWeekday time.Weekday
+		wantHour    int
+	}{
+		{"作者东八区", &plus8, nil, time.Tuesday, 7},
+		{"作者西五区", &minus5, nil, time.Monday, 18},
+		{"查看者时区优先", &plus8, time.UTC, time.Monday, 23},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weekday, hour := heatmapSlot(ts, tt.offset, tt.loc)
+			if weekday != tt.wantWeekday || hour != tt.wantHour {
+				t.Errorf("heatmapSlot() = %s %d, want %s %d", weekday, hour, tt.wantWeekday, tt.wantHour)
+			}
+		})
+	}
+}
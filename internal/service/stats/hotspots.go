@@ -0,0 +1,210 @@
+package stats
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+// 热点分析的统计粒度
+const (
+	HotspotLevelFile      = "file"
+	HotspotLevelDirectory = "directory"
+)
+
+// 热点排序方式
+const (
+	HotspotSortChanges = "changes"
+	HotspotSortChurn   = "churn"
+	HotspotSortAuthors = "authors"
+	HotspotSortRecency = "recency"
+	HotspotSortRework  = "rework"
+)
+
+// DefaultReworkDays 默认的返工窗口（天）
+const DefaultReworkDays = 21
+
+// ValidHotspotSort 判断热点排序方式是否受支持
+func ValidHotspotSort(sortBy string) bool {
+	switch sortBy {
+	case HotspotSortChanges, HotspotSortChurn, HotspotSortAuthors, HotspotSortRecency, HotspotSortRework:
+		return true
+	}
+	return false
+}
+
+// HotspotQuery 热点分析查询参数
+type HotspotQuery struct {
+	Filter     repository.StatsFilter
+	PathPrefix string // 只分析该目录下的文件
+	Level      string // file | directory
+	Depth      int    // Level 为 directory 时截取的目录层级，0 表示文件所在目录
+	Sort       string
+	ReworkDays int // 同一文件在该天数内再次被修改的行数计为返工
+	Limit      int
+}
+
+// Hotspot 文件或目录的变更热度
+type Hotspot struct {
+	Path          string    `json:"path"`
+	Changes       int       `json:"changes"`
+	Added         int       `json:"added"`
+	Removed       int       `json:"removed"`
+	Churn         int       `json:"churn"`
+	Authors       int       `json:"authors"`
+	Files         int       `json:"files,omitempty"`
+	LastChangedAt time.Time `json:"last_changed_at"`
+	// ReworkLines 在窗口期内再次修改的行数，按修改者是否与上一次相同拆分
+	ReworkLines      int     `json:"rework_lines"`
+	ReworkSelfLines  int     `json:"rework_self_lines"`
+	ReworkOtherLines int     `json:"rework_other_lines"`
+	ReworkRatio      float64 `json:"rework_ratio"`
+
+	authors map[string]bool
+	files   map[string]bool
+}
+
+// HotspotReport 热点分析结果
+type HotspotReport struct {
+	Level      string     `json:"level"`
+	Sort       string     `json:"sort"`
+	ReworkDays int        `json:"rework_days"`
+	Total      int        `json:"total"`
+	Items      []*Hotspot `json:"items"`
+}
+
+// GetHotspots 按变更频率、变动行数、作者数、最近修改时间或返工量对文件/目录排序
+func (s *StatsService) GetHotspots(q *HotspotQuery) (*HotspotReport, error) {
+	if q.Sort == "" {
+		q.Sort = HotspotSortChanges
+	}
+	if !ValidHotspotSort(q.Sort) {
+		return nil, fmt.Errorf("不支持的排序方式: %s", q.Sort)
+	}
+	if q.Level != HotspotLevelDirectory {
+		q.Level = HotspotLevelFile
+	}
+	if q.ReworkDays <= 0 {
+		q.ReworkDays = DefaultReworkDays
+	}
+
+	changes, err := s.repo.GetFileChanges(&q.Filter, q.PathPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	items := computeHotspots(changes, q.Level, q.Depth, time.Duration(q.ReworkDays)*24*time.Hour)
+	sortHotspots(items, q.Sort)
+
+	report := &HotspotReport{
+		Level:      q.Level,
+		Sort:       q.Sort,
+		ReworkDays: q.ReworkDays,
+		Total:      len(items),
+		Items:      items,
+	}
+	if q.Limit > 0 && len(items) > q.Limit {
+		report.Items = items[:q.Limit]
+	}
+	return report, nil
+}
+
+// computeHotspots 聚合文件变更明细，changes 需按时间升序
+func computeHotspots(changes []*repository.FileChange, level string, depth int, reworkWindow time.Duration) []*Hotspot {
+	type lastChange struct {
+		at     time.Time
+		author string
+	}
+	last := make(map[string]lastChange)
+	index := make(map[string]*Hotspot)
+	var items []*Hotspot
+
+	for _, c := range changes {
+		key := c.FilePath
+		if level == HotspotLevelDirectory {
+			key = directoryKey(c.FilePath, depth)
+		}
+
+		h, ok := index[key]
+		if !ok {
+			h = &Hotspot{Path: key, authors: make(map[string]bool), files: make(map[string]bool)}
+			index[key] = h
+			items = append(items, h)
+		}
+
+		lines := c.AddedLines + c.RemovedLines
+		h.Changes++
+		h.Added += c.AddedLines
+		h.Removed += c.RemovedLines
+		h.Churn += lines
+		h.authors[c.AuthorEmail] = true
+		h.files[c.FilePath] = true
+		if c.Timestamp.After(h.LastChangedAt) {
+			h.LastChangedAt = c.Timestamp
+		}
+
+		// 返工按文件判断：距离该文件上一次修改不超过窗口期
+		if prev, ok := last[c.FilePath]; ok && c.Timestamp.Sub(prev.at) <= reworkWindow {
+			h.ReworkLines += lines
+			if prev.author == c.AuthorEmail {
+				h.ReworkSelfLines += lines
+			} else {
+				h.ReworkOtherLines += lines
+			}
+		}
+		last[c.FilePath] = lastChange{at: c.Timestamp, author: c.AuthorEmail}
+	}
+
+	for _, h := range items {
+		h.Authors = len(h.authors)
+		if level == HotspotLevelDirectory {
+			h.Files = len(h.files)
+		}
+		if h.Churn > 0 {
+			h.ReworkRatio = float64(h.ReworkLines) / float64(h.Churn)
+		}
+	}
+	return items
+}
+
+// directoryKey 获取文件所属目录，depth > 0 时只保留前 depth 级
+func directoryKey(filePath string, depth int) string {
+	dir := path.Dir(filePath)
+	if depth <= 0 || dir == "." {
+		return dir
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// sortHotspots 按指定指标降序排序，指标相同时按路径排序保证结果稳定
+func sortHotspots(items []*Hotspot, sortBy string) {
+	value := func(h *Hotspot) int64 {
+		switch sortBy {
+		case HotspotSortChurn:
+			return int64(h.Churn)
+		case HotspotSortAuthors:
+			return int64(h.Authors)
+		case HotspotSortRecency:
+			return h.LastChangedAt.Unix()
+		case HotspotSortRework:
+			return int64(h.ReworkLines)
+		default:
+			return int64(h.Changes)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		vi, vj := value(items[i]), value(items[j])
+		if vi != vj {
+			return vi > vj
+		}
+		return items[i].Path < items[j].Path
+	})
+}
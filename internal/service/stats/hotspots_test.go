@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+func TestComputeHotspots(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	changes := []*repository.FileChange{
+		{FilePath: "pkg/a/x.go", AddedLines: 10, AuthorEmail: "a@example.com", Timestamp: day(1)},
+		{FilePath: "pkg/a/x.go", AddedLines: 2, RemovedLines: 3, AuthorEmail: "a@example.com", Timestamp: day(3)},
+		{FilePath: "pkg/a/x.go", AddedLines: 1, RemovedLines: 1, AuthorEmail: "b@example.com", Timestamp: day(5)},
+		{FilePath: "pkg/b/y.go", AddedLines: 4, AuthorEmail: "b@example.com", Timestamp: day(2)},
+		{FilePath: "pkg/b/y.go", AddedLines: 6, AuthorEmail: "b@example.com", Timestamp: day(30)},
+	}
+
+	files := computeHotspots(changes, HotspotLevelFile, 0, 7*24*time.Hour)
+	sortHotspots(files, HotspotSortChanges)
+	x := files[0]
+	if x.Path != "pkg/a/x.go" || x.Changes != 3 || x.Churn != 17 || x.Authors != 2 {
+		t.Fatalf("x.go = %+v", x)
+	}
+	if x.ReworkLines != 7 || x.ReworkSelfLines != 5 || x.ReworkOtherLines != 2 {
+		t.Errorf("x.go rework = %d/%d/%d, want 7/5/2", x.ReworkLines, x.ReworkSelfLines, x.ReworkOtherLines)
+	}
+	if y := files[1]; y.ReworkLines != 0 {
+		t.Errorf("y.go 超出窗口期不应计为返工, got %d", y.ReworkLines)
+	}
+
+	dirs := computeHotspots(changes, HotspotLevelDirectory, 1, 7*24*time.Hour)
+	if len(dirs) != 1 || dirs[0].Path != "pkg" || dirs[0].Files != 2 || dirs[0].Changes != 5 {
+		t.Errorf("directory hotspots = %+v", dirs)
+	}
+}
+
+func TestDirectoryKey(t *testing.T) {
+	tests := []struct {
+		path  string
+		depth int
+		want  string
+	}{
+		{"main.go", 0, "."},
+		{"internal/service/stats/a.go", 0, "internal/service/stats"},
+		{"internal/service/stats/a.go", 2, "internal/service"},
+		{"cmd/a.go", 3, "cmd"},
+	}
+	for _, tt := range tests {
+		if got := directoryKey(tt.path, tt.depth); got != tt.want {
+			t.Errorf("directoryKey(%q, %d) = %q, want %q", tt.path, tt.depth, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/utils"
+)
+
+// 排行榜指标
+const (
+	MetricCommits       = "commits"
+	MetricNetLines      = "net_lines"
+	MetricFiles         = "files"
+	MetricActiveDays    = "active_days"
+	MetricLanguageLines = "language_lines"
+)
+
+// ValidMetric 判断排行榜指标是否受支持
+func ValidMetric(metric string) bool {
+	switch metric {
+	case MetricCommits, MetricNetLines, MetricFiles, MetricActiveDays, MetricLanguageLines:
+		return true
+	}
+	return false
+}
+
+// LeaderboardQuery 排行榜查询参数
+type LeaderboardQuery struct {
+	Filter   repository.StatsFilter
+	Metric   string
+	Language string         // Metric 为 language_lines 时必填
+	Location *time.Location // 计算活跃天数使用的时区，nil 时使用默认统计时区
+	Page     int
+	PageSize int
+}
+
+// LeaderboardEntry 排行榜条目
+type LeaderboardEntry struct {
+	Rank         int    `json:"rank"`
+	PreviousRank *int   `json:"previous_rank"`
+	RankDelta    *int   `json:"rank_delta"` // 正数表示名次上升，新上榜为 null
+	Value        int    `json:"value"`
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	CommitCount  int    `json:"commit_count"`
+	TotalAdded   int    `json:"total_added"`
+	TotalRemoved int    `json:"total_removed"`
+	NetLines     int    `json:"net_lines"`
+	TotalFiles   int    `json:"total_files"`
+	ActiveDays   int    `json:"active_days"`
+}
+
+// Leaderboard 排行榜
+type Leaderboard struct {
+	Metric   string              `json:"metric"`
+	Language string              `json:"language,omitempty"`
+	Total    int                 `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	Entries  []*LeaderboardEntry `json:"entries"`
+}
+
+// GetLeaderboard 获取成员排行榜
+// 指定了起止时间时，会按紧邻的上一个同样长度的周期计算名次变化
+func (s *StatsService) GetLeaderboard(q *LeaderboardQuery) (*Leaderboard, error) {
+	if !ValidMetric(q.Metric) {
+		return nil, fmt.Errorf("不支持的排行指标: %s", q.Metric)
+	}
+	if q.Metric == MetricLanguageLines && q.Language == "" {
+		return nil, fmt.Errorf("language_lines 指标需要指定 language")
+	}
+
+	entries, err := s.rankMembers(&q.Filter, q.Metric, q.Language, q.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	// 上一个等长周期的名次
+	if q.Filter.StartDate != nil && q.Filter.EndDate != nil {
+		loc := q.Location
+		if loc == nil {
+			loc = utils.DefaultLocation()
+		}
+		prevFilter := q.Filter
+		prevStart, prevEnd := previousPeriod(*q.Filter.StartDate, *q.Filter.EndDate, loc)
+		prevFilter.StartDate = &prevStart
+		prevFilter.EndDate = &prevEnd
+
+		prevEntries, err := s.rankMembers(&prevFilter, q.Metric, q.Language, q.Location)
+		if err != nil {
+			return nil, err
+		}
+		prevRanks := make(map[string]int, len(prevEntries))
+		for _, e := range prevEntries {
+			prevRanks[e.Email] = e.Rank
+		}
+		for _, e := range entries {
+			if prev, ok := prevRanks[e.Email]; ok {
+				delta := prev - e.Rank
+				e.PreviousRank = &prev
+				e.RankDelta = &delta
+			}
+		}
+	}
+
+	board := &Leaderboard{
+		Metric:   q.Metric,
+		Language: q.Language,
+		Total:    len(entries),
+		Page:     q.Page,
+		PageSize: q.PageSize,
+		Entries:  []*LeaderboardEntry{},
+	}
+
+	offset := (q.Page - 1) * q.PageSize
+	if offset < len(entries) {
+		end := offset + q.PageSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		board.Entries = entries[offset:end]
+	}
+
+	return board, nil
+}
+
+// previousPeriod 计算 [start, end) 之前紧邻的同样长度的周期
+// 区间按 loc 时区的整月对齐时按月数回退（3 月 → 2 月），按整天对齐时按天数回退（整周仍为整周，不受夏令时影响），
+// 否则按固定时长回退
+func previousPeriod(start, end time.Time, loc *time.Location) (time.Time, time.Time) {
+	s, e := start.In(loc), end.In(loc)
+	monthStart := func(t time.Time) bool {
+		return t.Equal(time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc))
+	}
+	if monthStart(s) && monthStart(e) {
+		months := (e.Year()-s.Year())*12 + int(e.Month()-s.Month())
+		return s.AddDate(0, -months, 0), s
+	}
+	if s.Equal(utils.StartOfDay(s, loc)) && e.Equal(utils.StartOfDay(e, loc)) {
+		civil := func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		days := int(civil(e).Sub(civil(s)).Hours() / 24)
+		return s.AddDate(0, 0, -days), s
+	}
+	return start.Add(-end.Sub(start)), start
+}
+
+// rankMembers 计算成员排名（并列同名次，下一名次顺延）
+func (s *StatsService) rankMembers(
+	filter *repository.StatsFilter,
+	metric, language string,
+	loc *time.Location,
+) ([]*LeaderboardEntry, error) {
+	metrics, err := s.repo.GetMemberMetrics(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	activeDays, err := s.activeDays(filter, loc)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range metrics {
+		m.ActiveDays = activeDays[m.Email]
+	}
+
+	var languageLines map[string]*repository.LanguageStats
+	if metric == MetricLanguageLines {
+		languageLines, err = s.repo.GetMemberLanguageLines(filter, language)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]*LeaderboardEntry, 0, len(metrics))
+	for _, m := range metrics {
+		entry := &LeaderboardEntry{
+			Email:        m.Email,
+			Name:         m.Name,
+			CommitCount:  m.CommitCount,
+			TotalAdded:   m.TotalAdded,
+			TotalRemoved: m.TotalRemoved,
+			NetLines:     m.TotalAdded - m.TotalRemoved,
+			TotalFiles:   m.TotalFiles,
+			ActiveDays:   m.ActiveDays,
+		}
+
+		switch metric {
+		case MetricCommits:
+			entry.Value = m.CommitCount
+		case MetricNetLines:
+			entry.Value = entry.NetLines
+		case MetricFiles:
+			entry.Value = m.TotalFiles
+		case MetricActiveDays:
+			entry.Value = m.ActiveDays
+		case MetricLanguageLines:
+			stat, ok := languageLines[m.Email]
+			if !ok {
+				continue // 没有该语言的提交，不参与排名
+			}
+			entry.Value = stat.TotalAdded
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value > entries[j].Value
+		}
+		return entries[i].Email < entries[j].Email
+	})
+
+	for i, e := range entries {
+		if i > 0 && e.Value == entries[i-1].Value {
+			e.Rank = entries[i-1].Rank
+		} else {
+			e.Rank = i + 1
+		}
+	}
+
+	return entries, nil
+}
+
+// activeDays 按 loc 时区的自然日统计每个成员的活跃天数（作为共同作者的提交也算活跃）
+func (s *StatsService) activeDays(filter *repository.StatsFilter, loc *time.Location) (map[string]int, error) {
+	if loc == nil {
+		loc = utils.DefaultLocation()
+	}
+
+	points, err := s.repo.GetMemberCommitTimes(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]map[time.Time]bool)
+	for _, p := range points {
+		if days[p.AuthorEmail] == nil {
+			days[p.AuthorEmail] = make(map[time.Time]bool)
+		}
+		days[p.AuthorEmail][utils.StartOfDay(p.Timestamp, loc)] = true
+	}
+
+	result := make(map[string]int, len(days))
+	for email, set := range days {
+		result[email] = len(set)
+	}
+	return result, nil
+}
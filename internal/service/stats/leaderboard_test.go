@@ -0,0 +1,38 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreviousPeriod(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name               string
+		start, end         time.Time
+		wantStart, wantEnd time.Time
+	}{
+		{"整月", date(2024, 3, 1), date(2024, 4, 1), date(2024, 2, 1), date(2024, 3, 1)},
+		{"季度", date(2024, 4, 1), date(2024, 7, 1), date(2024, 1, 1), date(2024, 4, 1)},
+		{"整周", date(2024, 3, 4), date(2024, 3, 11), date(2024, 2, 26), date(2024, 3, 4)},
+		{"跨月的天数", date(2024, 3, 1), date(2024, 3, 15), date(2024, 2, 16), date(2024, 3, 1)},
+		{
+			"非整天",
+			time.Date(2024, 3, 1, 12, 0, 0, 0, loc), time.Date(2024, 3, 2, 0, 0, 0, 0, loc),
+			time.Date(2024, 3, 1, 0, 0, 0, 0, loc), time.Date(2024, 3, 1, 12, 0, 0, 0, loc),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 以 UTC 传入，按 loc 判断对齐
+			start, end := previousPeriod(tt.start.UTC(), tt.end.UTC(), loc)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("previousPeriod = %s ~ %s, want %s ~ %s", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
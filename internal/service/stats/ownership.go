@@ -0,0 +1,175 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+// DefaultInactiveDays 超过该天数没有任何提交的成员视为不活跃
+const DefaultInactiveDays = 90
+
+// OwnershipQuery 代码归属查询参数
+type OwnershipQuery struct {
+	Filter     repository.StatsFilter
+	PathPrefix string
+	Depth      int // 目录层级，含义同 HotspotQuery.Depth
+	// HalfLifeDays 行数权重的半衰期（天），0 表示不衰减
+	HalfLifeDays int
+	// Now 衰减与不活跃判断的参照时间，零值使用当前时间
+	Now time.Time
+}
+
+// OwnerShare 成员在目录中的归属份额
+type OwnerShare struct {
+	Email        string     `json:"email"`
+	Lines        float64    `json:"lines"`
+	Share        float64    `json:"share"`
+	LastCommitAt *time.Time `json:"last_commit_at,omitempty"`
+	Inactive     bool       `json:"inactive,omitempty"`
+}
+
+// DirectoryOwnership 目录的代码归属
+type DirectoryOwnership struct {
+	Path      string        `json:"path"`
+	Lines     float64       `json:"lines"`
+	BusFactor int           `json:"bus_factor"`
+	Owners    []*OwnerShare `json:"owners"`
+	// InactiveShare 不活跃成员持有的份额之和（仅在不活跃报告中填充）
+	InactiveShare float64 `json:"inactive_share,omitempty"`
+}
+
+// GetOwnership 按目录计算各成员贡献行数的份额与 bus factor
+func (s *StatsService) GetOwnership(q *OwnershipQuery) ([]*DirectoryOwnership, error) {
+	changes, err := s.repo.GetFileChanges(&q.Filter, q.PathPrefix)
+	if err != nil {
+		return nil, err
+	}
+	now := q.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return computeOwnership(changes, q.Depth, q.HalfLifeDays, now), nil
+}
+
+// GetInactiveOwnership 列出不活跃成员合计持有超过一半份额的目录
+// 成员是否活跃按其在所有项目中的最后一次提交判断
+func (s *StatsService) GetInactiveOwnership(q *OwnershipQuery, inactiveDays int) ([]*DirectoryOwnership, error) {
+	dirs, err := s.GetOwnership(q)
+	if err != nil {
+		return nil, err
+	}
+	if inactiveDays <= 0 {
+		inactiveDays = DefaultInactiveDays
+	}
+	now := q.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	emailSet := make(map[string]bool)
+	for _, d := range dirs {
+		for _, o := range d.Owners {
+			emailSet[o.Email] = true
+		}
+	}
+	emails := make([]string, 0, len(emailSet))
+	for email := range emailSet {
+		emails = append(emails, email)
+	}
+	lastCommits, err := s.repo.GetLastCommitTimes(emails)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := now.AddDate(0, 0, -inactiveDays)
+	result := []*DirectoryOwnership{}
+	for _, d := range dirs {
+		d.InactiveShare = 0
+		for _, o := range d.Owners {
+			if last, ok := lastCommits[o.Email]; ok {
+				t := last
+				o.LastCommitAt = &t
+				o.Inactive = last.Before(cutoff)
+			} else {
+				o.Inactive = true
+			}
+			if o.Inactive {
+				d.InactiveShare += o.Share
+			}
+		}
+		if d.InactiveShare > 0.5 {
+			result = append(result, d)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].InactiveShare > result[j].InactiveShare
+	})
+	return result, nil
+}
+
+// computeOwnership 按目录汇总各成员新增行数（可按时间衰减）并计算份额
+func computeOwnership(changes []*repository.FileChange, depth, halfLifeDays int, now time.Time) []*DirectoryOwnership {
+	lines := make(map[string]map[string]float64)
+	var dirs []string
+
+	for _, c := range changes {
+		if c.AddedLines <= 0 {
+			continue
+		}
+		weight := float64(c.AddedLines)
+		if halfLifeDays > 0 {
+			age := now.Sub(c.Timestamp).Hours() / 24
+			if age > 0 {
+				weight *= math.Pow(0.5, age/float64(halfLifeDays))
+			}
+		}
+
+		dir := directoryKey(c.FilePath, depth)
+		if lines[dir] == nil {
+			lines[dir] = make(map[string]float64)
+			dirs = append(dirs, dir)
+		}
+		lines[dir][c.AuthorEmail] += weight
+	}
+
+	result := make([]*DirectoryOwnership, 0, len(dirs))
+	for _, dir := range dirs {
+		d := &DirectoryOwnership{Path: dir}
+		for email, n := range lines[dir] {
+			d.Lines += n
+			d.Owners = append(d.Owners, &OwnerShare{Email: email, Lines: n})
+		}
+		sort.Slice(d.Owners, func(i, j int) bool {
+			if d.Owners[i].Lines != d.Owners[j].Lines {
+				return d.Owners[i].Lines > d.Owners[j].Lines
+			}
+			return d.Owners[i].Email < d.Owners[j].Email
+		})
+		for _, o := range d.Owners {
+			if d.Lines > 0 {
+				o.Share = o.Lines / d.Lines
+			}
+		}
+		d.BusFactor = busFactor(d.Owners)
+		result = append(result, d)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result
+}
+
+// busFactor 份额合计超过 50% 所需的最少人数，owners 需按份额降序
+func busFactor(owners []*OwnerShare) int {
+	var covered float64
+	for i, o := range owners {
+		covered += o.Share
+		if covered > 0.5 {
+			return i + 1
+		}
+	}
+	return len(owners)
+}
@@ -0,0 +1,43 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+func TestComputeOwnership(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	changes := []*repository.FileChange{
+		{FilePath: "api/a.go", AddedLines: 60, AuthorEmail: "a@example.com", Timestamp: now.AddDate(0, 0, -10)},
+		{FilePath: "api/b.go", AddedLines: 30, AuthorEmail: "b@example.com", Timestamp: now.AddDate(0, 0, -10)},
+		{FilePath: "api/c.go", AddedLines: 10, AuthorEmail: "c@example.com", Timestamp: now.AddDate(0, 0, -10)},
+		{FilePath: "web/x.ts", AddedLines: 40, AuthorEmail: "a@example.com", Timestamp: now.AddDate(0, 0, -100)},
+		{FilePath: "web/y.ts", AddedLines: 40, AuthorEmail: "b@example.com", Timestamp: now},
+		{FilePath: "web/y.ts", RemovedLines: 5, AuthorEmail: "c@example.com", Timestamp: now},
+	}
+
+	dirs := computeOwnership(changes, 0, 0, now)
+	if len(dirs) != 2 {
+		t.Fatalf("len(dirs) = %d, want 2", len(dirs))
+	}
+	api, web := dirs[0], dirs[1]
+	if api.Path != "api" || api.BusFactor != 1 || api.Owners[0].Email != "a@example.com" {
+		t.Errorf("api = %+v", api)
+	}
+	if web.BusFactor != 2 || len(web.Owners) != 2 {
+		t.Errorf("web bus factor = %d owners = %d, want 2/2", web.BusFactor, len(web.Owners))
+	}
+
+	// 半衰期 100 天：a 的 40 行衰减为 20 行
+	decayed := computeOwnership(changes, 0, 100, now)
+	web = decayed[1]
+	if web.Owners[0].Email != "b@example.com" || math.Abs(web.Owners[1].Lines-20) > 1e-9 {
+		t.Errorf("decayed web owners = %+v %+v", web.Owners[0], web.Owners[1])
+	}
+	if web.BusFactor != 1 {
+		t.Errorf("decayed web bus factor = %d, want 1", web.BusFactor)
+	}
+}
@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+// PusherStats 推送者统计（推送者可能与提交作者不同，如发布负责人推送他人的提交）
+type PusherStats struct {
+	Email             string    `json:"email,omitempty"`
+	Username          string    `json:"username,omitempty"`
+	Name              string    `json:"name,omitempty"`
+	Pushes            int       `json:"pushes"`
+	EmptyPushes       int       `json:"empty_pushes"`
+	ProtectedPushes   int       `json:"protected_pushes"`
+	Commits           int       `json:"commits"`
+	AvgCommitsPerPush float64   `json:"avg_commits_per_push"`
+	PushesPerWeek     float64   `json:"pushes_per_week"`
+	OthersPushes      int       `json:"others_pushes"`      // 包含他人提交的推送数
+	OthersCommits     int       `json:"others_commits"`     // 推送的他人提交数
+	OthersCommitRate  float64   `json:"others_commit_rate"` // 推送的提交中他人提交的占比
+	FirstPushAt       time.Time `json:"first_push_at"`
+	LastPushAt        time.Time `json:"last_push_at"`
+}
+
+// PusherReport 推送者统计报告
+type PusherReport struct {
+	Total   int            `json:"total"`
+	Pushers []*PusherStats `json:"pushers"`
+}
+
+// GetPusherStats 按推送者统计推送频率、每次推送的平均提交数、受保护分支推送和推送他人提交的情况
+// filter 的 email / team 匹配推送者，branch 匹配推送的分支；limit <= 0 时返回全部
+func (s *StatsService) GetPusherStats(filter *repository.StatsFilter, limit int) (*PusherReport, error) {
+	rows, err := s.repo.GetPusherActivity(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PusherReport{Total: len(rows), Pushers: make([]*PusherStats, 0, len(rows))}
+	for _, row := range rows {
+		if limit > 0 && len(report.Pushers) >= limit {
+			break
+		}
+		report.Pushers = append(report.Pushers, newPusherStats(row, filter.StartDate, filter.EndDate))
+	}
+	return report, nil
+}
+
+// newPusherStats 计算推送者的派生指标
+// 推送频率按查询区间计算，未指定起止日期时使用该推送者首次到最近一次推送的区间（不足一周按一周计）
+func newPusherStats(row *repository.PusherActivity, startDate, endDate *time.Time) *PusherStats {
+	stats := &PusherStats{
+		Email:           row.PusherEmail,
+		Username:        row.PusherUsername,
+		Name:            row.PusherName,
+		Pushes:          row.PushCount,
+		EmptyPushes:     row.EmptyPushCount,
+		ProtectedPushes: row.ProtectedPushCount,
+		Commits:         row.CommitCount,
+		OthersPushes:    row.OthersPushCount,
+		OthersCommits:   row.OthersCommitCount,
+		FirstPushAt:     row.FirstPushAt,
+		LastPushAt:      row.LastPushAt,
+	}
+	if stats.Pushes > 0 {
+		stats.AvgCommitsPerPush = float64(stats.Commits) / float64(stats.Pushes)
+	}
+	if stats.Commits > 0 {
+		stats.OthersCommitRate = float64(stats.OthersCommits) / float64(stats.Commits)
+	}
+
+	from, to := row.FirstPushAt, row.LastPushAt
+	if startDate != nil {
+		from = *startDate
+	}
+	if endDate != nil {
+		to = *endDate
+	}
+	weeks := to.Sub(from).Hours() / (24 * 7)
+	if weeks < 1 {
+		weeks = 1
+	}
+	stats.PushesPerWeek = float64(stats.Pushes) / weeks
+	return stats
+}
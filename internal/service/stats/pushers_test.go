@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+func TestNewPusherStats(t *testing.T) {
+	first := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	row := &repository.PusherActivity{
+		PusherEmail:       "release@example.com",
+		PushCount:         8,
+		CommitCount:       20,
+		OthersPushCount:   5,
+		OthersCommitCount: 15,
+		FirstPushAt:       first,
+		LastPushAt:        first.Add(3 * 24 * time.Hour),
+	}
+
+	// 未指定区间：首次到最近一次推送不足一周，按一周计
+	stats := newPusherStats(row, nil, nil)
+	if stats.AvgCommitsPerPush != 2.5 {
+		t.Errorf("AvgCommitsPerPush = %v, want 2.5", stats.AvgCommitsPerPush)
+	}
+	if stats.OthersCommitRate != 0.75 {
+		t.Errorf("OthersCommitRate = %v, want 0.75", stats.OthersCommitRate)
+	}
+	if stats.PushesPerWeek != 8 {
+		t.Errorf("PushesPerWeek = %v, want 8", stats.PushesPerWeek)
+	}
+
+	// 指定四周的查询区间
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 28)
+	stats = newPusherStats(row, &start, &end)
+	if math.Abs(stats.PushesPerWeek-2) > 1e-9 {
+		t.Errorf("PushesPerWeek = %v, want 2", stats.PushesPerWeek)
+	}
+}
+
+func TestNewPusherStatsEmpty(t *testing.T) {
+	stats := newPusherStats(&repository.PusherActivity{PushCount: 2, EmptyPushCount: 2}, nil, nil)
+	if stats.AvgCommitsPerPush != 0 || stats.OthersCommitRate != 0 {
+		t.Errorf("empty pushes: got avg %v rate %v, want 0", stats.AvgCommitsPerPush, stats.OthersCommitRate)
+	}
+}
@@ -0,0 +1,150 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/repository"
+)
+
+// IssueContributor 参与某个工单的成员
+type IssueContributor struct {
+	Email         string    `json:"email"`
+	Name          string    `json:"name"`
+	CommitCount   int       `json:"commit_count"`
+	TotalAdded    int       `json:"total_added"`
+	TotalRemoved  int       `json:"total_removed"`
+	FirstCommitAt time.Time `json:"first_commit_at"`
+	LastCommitAt  time.Time `json:"last_commit_at"`
+}
+
+// IssueReport 工单关联的提交与参与者
+type IssueReport struct {
+	Key           string              `json:"key"`
+	CommitCount   int                 `json:"commit_count"` // 同一逻辑变更只计一次
+	Closed        bool                `json:"closed"`       // 是否有提交使用了关闭关键字
+	FirstCommitAt *time.Time          `json:"first_commit_at,omitempty"`
+	LastCommitAt  *time.Time          `json:"last_commit_at,omitempty"`
+	Contributors  []*IssueContributor `json:"contributors"`
+	Commits       []*model.Commit     `json:"commits"`
+}
+
+// GetIssueCommits 获取引用了指定 issue / 合并请求 / 工单的提交及参与者
+func (s *StatsService) GetIssueCommits(refKey string) (*IssueReport, error) {
+	commits, err := s.repo.GetCommitsByReference(refKey)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &IssueReport{
+		Key:          refKey,
+		Contributors: []*IssueContributor{},
+		Commits:      commits,
+	}
+	if len(commits) == 0 {
+		return report, nil
+	}
+	report.FirstCommitAt = &commits[0].Timestamp
+	report.LastCommitAt = &commits[len(commits)-1].Timestamp
+
+	index := make(map[string]*IssueContributor)
+	// cherry-pick 到其他分支的提交仍然列出，但同一逻辑变更只计一次
+	counted := make(map[string]bool, len(commits))
+	for _, c := range commits {
+		for _, ref := range c.References {
+			report.Closed = report.Closed || ref.IsClosing
+		}
+
+		changeKey := c.ChangeKey
+		if changeKey == "" {
+			changeKey = c.CommitID
+		}
+		if counted[changeKey] {
+			continue
+		}
+		counted[changeKey] = true
+		report.CommitCount++
+
+		contributor, ok := index[c.AuthorEmail]
+		if !ok {
+			contributor = &IssueContributor{Email: c.AuthorEmail, FirstCommitAt: c.Timestamp}
+			index[c.AuthorEmail] = contributor
+			report.Contributors = append(report.Contributors, contributor)
+		}
+		contributor.Name = c.Author
+		contributor.CommitCount++
+		if !c.IsMerge {
+			contributor.TotalAdded += c.TotalAddedLines
+			contributor.TotalRemoved += c.TotalRemovedLines
+		}
+		contributor.LastCommitAt = c.Timestamp
+	}
+	sort.SliceStable(report.Contributors, func(i, j int) bool {
+		return report.Contributors[i].CommitCount > report.Contributors[j].CommitCount
+	})
+
+	return report, nil
+}
+
+// ReferenceCoverageStats 工单引用覆盖情况
+type ReferenceCoverageStats struct {
+	Key          string  `json:"key,omitempty"`
+	Total        int     `json:"total"`
+	Linked       int     `json:"linked"`
+	Unlinked     int     `json:"unlinked"`
+	UnlinkedRate float64 `json:"unlinked_rate"`
+}
+
+// ReferenceCoverageReport 工单引用覆盖报告
+type ReferenceCoverageReport struct {
+	Overall *ReferenceCoverageStats   `json:"overall"`
+	Members []*ReferenceCoverageStats `json:"members,omitempty"`
+}
+
+// GetReferenceCoverage 统计没有关联任何 issue / 合并请求 / 工单的提交占比
+// byMember 为 true 时同时返回每个成员的覆盖情况（按未关联比例降序）
+func (s *StatsService) GetReferenceCoverage(filter *repository.StatsFilter, byMember bool) (*ReferenceCoverageReport, error) {
+	overall, err := s.repo.GetReferenceCoverage(filter, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(overall) != 1 {
+		return nil, fmt.Errorf("工单引用覆盖率查询结果异常")
+	}
+
+	report := &ReferenceCoverageReport{Overall: newReferenceCoverageStats(overall[0])}
+	if !byMember {
+		return report, nil
+	}
+
+	rows, err := s.repo.GetReferenceCoverage(filter, "member")
+	if err != nil {
+		return nil, err
+	}
+	report.Members = make([]*ReferenceCoverageStats, 0, len(rows))
+	for _, row := range rows {
+		report.Members = append(report.Members, newReferenceCoverageStats(row))
+	}
+	sort.SliceStable(report.Members, func(i, j int) bool {
+		if report.Members[i].UnlinkedRate != report.Members[j].UnlinkedRate {
+			return report.Members[i].UnlinkedRate > report.Members[j].UnlinkedRate
+		}
+		return report.Members[i].Key < report.Members[j].Key
+	})
+	return report, nil
+}
+
+func newReferenceCoverageStats(row *repository.ReferenceCoverage) *ReferenceCoverageStats {
+	stats := &ReferenceCoverageStats{
+		Key:      row.GroupKey,
+		Total:    row.CommitCount,
+		Linked:   row.LinkedCount,
+		Unlinked: row.CommitCount - row.LinkedCount,
+	}
+	if stats.Total > 0 {
+		stats.UnlinkedRate = float64(stats.Unlinked) / float64(stats.Total)
+	}
+	return stats
+}
@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+
+	"gitlab-webhook-server/internal/repository"
+)
+
+// ValidRevertGroupBy 判断回滚统计的分组维度是否受支持（空字符串表示不分组）
+func ValidRevertGroupBy(groupBy string) bool {
+	switch groupBy {
+	case "", "member", "project":
+		return true
+	}
+	return false
+}
+
+// RevertBreakdown 回滚统计
+type RevertBreakdown struct {
+	Key                string  `json:"key,omitempty"`
+	Commits            int     `json:"commits"`
+	Reverts            int     `json:"reverts"`              // 回滚提交数
+	Reverted           int     `json:"reverted"`             // 已被回滚的提交数
+	RevertedAddedLines int     `json:"reverted_added_lines"` // 已被回滚的提交的新增行数
+	RevertRate         float64 `json:"revert_rate"`          // 提交中已被回滚的比例
+}
+
+// RevertReport 回滚统计报告
+type RevertReport struct {
+	GroupBy string             `json:"group_by,omitempty"`
+	Overall *RevertBreakdown   `json:"overall"`
+	Groups  []*RevertBreakdown `json:"groups,omitempty"`
+}
+
+// GetRevertStats 获取回滚率：提交中已被回滚的比例，以及回滚提交数
+// groupBy 为 member / project 时同时返回每个成员或项目的回滚率，按被回滚提交数降序
+func (s *StatsService) GetRevertStats(filter *repository.StatsFilter, groupBy string) (*RevertReport, error) {
+	if !ValidRevertGroupBy(groupBy) {
+		return nil, fmt.Errorf("不支持的分组维度: %s", groupBy)
+	}
+
+	counts, err := s.repo.GetRevertCounts(filter, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RevertReport{GroupBy: groupBy, Overall: &RevertBreakdown{}}
+	for _, c := range counts {
+		b := newRevertBreakdown(c)
+		report.Overall.Commits += b.Commits
+		report.Overall.Reverts += b.Reverts
+		report.Overall.Reverted += b.Reverted
+		report.Overall.RevertedAddedLines += b.RevertedAddedLines
+		if groupBy != "" {
+			report.Groups = append(report.Groups, b)
+		}
+	}
+	if report.Overall.Commits > 0 {
+		report.Overall.RevertRate = float64(report.Overall.Reverted) / float64(report.Overall.Commits)
+	}
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Reverted != report.Groups[j].Reverted {
+			return report.Groups[i].Reverted > report.Groups[j].Reverted
+		}
+		return report.Groups[i].Key < report.Groups[j].Key
+	})
+	return report, nil
+}
+
+// newRevertBreakdown 计算分组的回滚率
+func newRevertBreakdown(c *repository.RevertCount) *RevertBreakdown {
+	b := &RevertBreakdown{
+		Key:                c.GroupKey,
+		Commits:            c.CommitCount,
+		Reverts:            c.RevertCount,
+		Reverted:           c.RevertedCount,
+		RevertedAddedLines: c.RevertedAddedLines,
+	}
+	if b.Commits > 0 {
+		b.RevertRate = float64(b.Reverted) / float64(b.Commits)
+	}
+	return b
+}
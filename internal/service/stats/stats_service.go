@@ -0,0 +1,63 @@
+package stats
+
+import (
+	"gitlab-webhook-server/internal/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// StatsService 多维统计服务（项目、命名空间等维度）
+type StatsService struct {
+	logger *zap.Logger
+	repo   *repository.StatsRepository
+	db     *gorm.DB
+}
+
+// NewStatsService 创建新的多维统计服务
+func NewStatsService(db *gorm.DB, logger *zap.Logger) *StatsService {
+	return &StatsService{
+		logger: logger,
+		repo:   repository.NewStatsRepository(db, logger),
+		db:     db,
+	}
+}
+
+// ProjectReport 项目（或命名空间）统计报告
+type ProjectReport struct {
+	Summary         *repository.SummaryStats       `json:"summary"`
+	Languages       []*repository.LanguageStats    `json:"languages"`
+	TopContributors []*repository.ContributorStats `json:"top_contributors"`
+	Branches        []*repository.BranchStats      `json:"branches"`
+}
+
+// GetProjectReport 获取项目统计报告
+// limit 控制贡献者和分支列表的最大长度
+func (s *StatsService) GetProjectReport(filter *repository.StatsFilter, limit int) (*ProjectReport, error) {
+	summary, err := s.repo.GetSummary(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	languages, err := s.repo.GetLanguageMix(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	contributors, err := s.repo.GetTopContributors(filter, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := s.repo.GetBranchActivity(filter, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProjectReport{
+		Summary:         summary,
+		Languages:       languages,
+		TopContributors: contributors,
+		Branches:        branches,
+	}, nil
+}
@@ -0,0 +1,155 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab-webhook-server/internal/repository"
+	"gitlab-webhook-server/internal/utils"
+)
+
+// MaxBuckets 单次时间序列查询允许的最大时间桶数量
+const MaxBuckets = 1000
+
+// ValidGroupBy 判断分桶粒度是否受支持
+func ValidGroupBy(groupBy string) bool {
+	switch groupBy {
+	case "day", "week", "month":
+		return true
+	}
+	return false
+}
+
+// TimeSeriesQuery 时间序列查询参数
+type TimeSeriesQuery struct {
+	Filter    repository.StatsFilter
+	GroupBy   string // day | week | month
+	Location  *time.Location
+	WeekStart time.Weekday
+}
+
+// Bucket 时间桶
+type Bucket struct {
+	Start              time.Time `json:"start"`
+	End                time.Time `json:"end"`
+	CommitCount        int       `json:"commit_count"`
+	TotalAdded         int       `json:"total_added"`
+	TotalRemoved       int       `json:"total_removed"`
+	NetLines           int       `json:"net_lines"`
+	TotalFiles         int       `json:"total_files"`
+	ActiveContributors int       `json:"active_contributors"`
+}
+
+// TimeSeries 时间序列
+type TimeSeries struct {
+	GroupBy   string    `json:"group_by"`
+	Timezone  string    `json:"timezone"`
+	WeekStart string    `json:"week_start,omitempty"`
+	Buckets   []*Bucket `json:"buckets"`
+}
+
+// GetTimeSeries 获取按时间分桶的提交与行数指标
+// 分桶在 Go 中按 q.Location 计算，区间内没有提交的桶也会返回（值为 0），便于绘制趋势线
+func (s *StatsService) GetTimeSeries(q *TimeSeriesQuery) (*TimeSeries, error) {
+	if !ValidGroupBy(q.GroupBy) {
+		return nil, fmt.Errorf("不支持的分桶粒度: %s", q.GroupBy)
+	}
+	loc := q.Location
+	if loc == nil {
+		loc = utils.DefaultLocation()
+	}
+
+	points, err := s.repo.GetCommitPoints(&q.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &TimeSeries{
+		GroupBy:  q.GroupBy,
+		Timezone: loc.String(),
+		Buckets:  []*Bucket{},
+	}
+	if q.GroupBy == "week" {
+		series.WeekStart = q.WeekStart.String()
+	}
+
+	// 确定序列范围：优先使用查询区间，否则使用数据的首尾时间
+	var first, last time.Time
+	switch {
+	case q.Filter.StartDate != nil:
+		first = *q.Filter.StartDate
+	case len(points) > 0:
+		first = points[0].Timestamp
+	default:
+		return series, nil
+	}
+	switch {
+	case q.Filter.EndDate != nil:
+		last = q.Filter.EndDate.Add(-time.Nanosecond)
+	case len(points) > 0:
+		last = points[len(points)-1].Timestamp
+	default:
+		last = first
+	}
+
+	bounds, err := bucketRange(q.GroupBy, first, last, loc, q.WeekStart)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[int64]*Bucket)
+	for _, b := range bounds {
+		bucket := &Bucket{Start: b[0], End: b[1]}
+		series.Buckets = append(series.Buckets, bucket)
+		index[b[0].Unix()] = bucket
+	}
+
+	for _, p := range points {
+		start, _ := utils.BucketBounds(q.GroupBy, p.Timestamp, loc, q.WeekStart)
+		bucket, ok := index[start.Unix()]
+		if !ok {
+			continue
+		}
+		bucket.CommitCount++
+		bucket.TotalAdded += p.TotalAddedLines
+		bucket.TotalRemoved += p.TotalRemovedLines
+		bucket.NetLines += p.TotalAddedLines - p.TotalRemovedLines
+		bucket.TotalFiles += p.TotalChangedFiles
+	}
+
+	// 活跃贡献者包含共同作者（按成员或团队过滤时只计匹配的作者）
+	authors, err := s.repo.GetMemberCommitTimes(&q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	contributors := make(map[int64]map[string]bool)
+	for _, a := range authors {
+		start, _ := utils.BucketBounds(q.GroupBy, a.Timestamp, loc, q.WeekStart)
+		if _, ok := index[start.Unix()]; !ok {
+			continue
+		}
+		if contributors[start.Unix()] == nil {
+			contributors[start.Unix()] = make(map[string]bool)
+		}
+		contributors[start.Unix()][a.AuthorEmail] = true
+	}
+	for key, emails := range contributors {
+		index[key].ActiveContributors = len(emails)
+	}
+
+	return series, nil
+}
+
+// bucketRange 生成覆盖 [first, last] 的连续时间桶边界（起始, 结束）
+func bucketRange(groupBy string, first, last time.Time, loc *time.Location, weekStart time.Weekday) ([][2]time.Time, error) {
+	var bounds [][2]time.Time
+	bucketStart, _ := utils.BucketBounds(groupBy, first, loc, weekStart)
+	for !bucketStart.After(last) {
+		if len(bounds) >= MaxBuckets {
+			return nil, fmt.Errorf("时间桶数量超过上限 %d，请缩小时间范围或使用更粗的粒度", MaxBuckets)
+		}
+		start, end := utils.BucketBounds(groupBy, bucketStart, loc, weekStart)
+		bounds = append(bounds, [2]time.Time{start, end})
+		bucketStart = end
+	}
+	return bounds, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TeamService 团队服务
+type TeamService struct {
+	logger *zap.Logger
+	repo   *repository.TeamRepository
+}
+
+// NewTeamService 创建新的团队服务
+func NewTeamService(db *gorm.DB, logger *zap.Logger) *TeamService {
+	return &TeamService{
+		logger: logger,
+		repo:   repository.NewTeamRepository(db, logger),
+	}
+}
+
+// ListTeams 获取所有团队
+func (s *TeamService) ListTeams() ([]*model.Team, error) {
+	return s.repo.ListTeams()
+}
+
+// GetTeam 获取团队，不存在时返回 nil
+func (s *TeamService) GetTeam(name string) (*model.Team, error) {
+	return s.repo.GetTeamByName(name)
+}
+
+// SaveTeam 创建或更新团队，成员列表整体替换
+// 成员邮箱需与提交记录中的作者邮箱一致，重复的邮箱只保留一个
+func (s *TeamService) SaveTeam(name, description string, members []model.TeamMember) (*model.Team, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("团队名称不能为空")
+	}
+
+	seen := make(map[string]bool, len(members))
+	normalized := make([]model.TeamMember, 0, len(members))
+	for _, m := range members {
+		email := strings.TrimSpace(m.MemberEmail)
+		if email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+		normalized = append(normalized, model.TeamMember{
+			MemberEmail: email,
+			MemberName:  m.MemberName,
+		})
+	}
+
+	team, err := s.repo.SaveTeam(name, description, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("团队已保存",
+		zap.String("team", team.Name),
+		zap.Int("members", len(team.Members)),
+	)
+	return team, nil
+}
+
+// DeleteTeam 删除团队
+func (s *TeamService) DeleteTeam(name string) error {
+	return s.repo.DeleteTeam(name)
+}
@@ -77,6 +77,9 @@ func (s *WebhookService) handlePushEvent(platform webhook.Platform, payload map[
 		return err
 	}
 
+	// 推送事件单独记录，没有新提交的推送（如删除分支）也保留推送者信息
+	s.recordPush(platform, payload, commitRecords)
+
 	if len(commitRecords) == 0 {
 		s.logger.Info("Push 事件中没有提交记录",
 			zap.String("platform", platform.GetPlatformName()),
@@ -130,6 +133,9 @@ func (s *WebhookService) handleTagPushEvent(platform webhook.Platform, payload m
 		return err
 	}
 
+	// 推送事件单独记录，没有新提交的推送（如删除分支）也保留推送者信息
+	s.recordPush(platform, payload, commitRecords)
+
 	if len(commitRecords) == 0 {
 		s.logger.Info("Tag Push 事件中没有提交记录",
 			zap.String("platform", platform.GetPlatformName()),
@@ -165,4 +171,18 @@ func (s *WebhookService) handleTagPushEvent(platform webhook.Platform, payload m
 	return nil
 }
 
-
+// recordPush 记录推送事件并将本次推送的提交关联到该推送
+// 记录失败只影响推送者统计，不阻止提交入库
+func (s *WebhookService) recordPush(platform webhook.Platform, payload map[string]interface{}, commitRecords []*model.CommitRecord) {
+	push, err := s.commitService.RecordPush(platform.ParsePush(payload))
+	if err != nil {
+		s.logger.Error("记录推送事件失败",
+			zap.String("platform", platform.GetPlatformName()),
+			zap.Error(err),
+		)
+		return
+	}
+	for _, commitRecord := range commitRecords {
+		commitRecord.PushID = &push.ID
+	}
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 共同作者的行数归属方式
+const (
+	CreditModeFull  = "full"  // 提交作者和每位共同作者都计入全部行数
+	CreditModeSplit = "split" // 行数在作者之间均分
+)
+
+var (
+	creditMode   = CreditModeFull
+	creditModeMu sync.RWMutex
+)
+
+// SetCreditMode 设置共同作者的行数归属方式（通常来自 CREDIT_MODE 配置），为空时使用 full
+func SetCreditMode(mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = CreditModeFull
+	}
+	if mode != CreditModeFull && mode != CreditModeSplit {
+		return fmt.Errorf("不支持的行数归属方式: %s（可选 full / split）", mode)
+	}
+	creditModeMu.Lock()
+	defer creditModeMu.Unlock()
+	creditMode = mode
+	return nil
+}
+
+// CreditMode 获取共同作者的行数归属方式
+func CreditMode() string {
+	creditModeMu.RLock()
+	defer creditModeMu.RUnlock()
+	return creditMode
+}
+
+// CreditLines 计算某位作者分得的行数
+// split 模式下共同作者各得整除部分，余数计入提交作者，各作者之和等于原行数
+func CreditLines(lines, authors int, primary bool) int {
+	if CreditMode() != CreditModeSplit || authors <= 1 {
+		return lines
+	}
+	share := lines / authors
+	if primary {
+		return lines - share*(authors-1)
+	}
+	return share
+}
+
+// CoAuthor 提交说明中声明的共同作者
+type CoAuthor struct {
+	Name  string
+	Email string
+}
+
+// coAuthorPattern Co-authored-by trailer（GitHub 结对提交和 GitLab 合并请求 squash 生成的格式）
+// 不要求位于最后一段，squash 提交说明中常与其他内容混排
+var coAuthorPattern = regexp.MustCompile(`(?im)^[ \t]*co-authored-by:[ \t]*(.*?)[ \t]*<([^<>\s]+@[^<>\s]+)>[ \t]*$`)
+
+// ParseCoAuthors 解析提交说明中的 Co-authored-by 共同作者
+// 按邮箱去重（不区分大小写），与提交作者相同的邮箱会被忽略
+func ParseCoAuthors(message, authorEmail string) []CoAuthor {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(authorEmail)): true}
+	var result []CoAuthor
+	for _, m := range coAuthorPattern.FindAllStringSubmatch(strings.ReplaceAll(message, "\r\n", "\n"), -1) {
+		email := strings.TrimSpace(m[2])
+		key := strings.ToLower(email)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, CoAuthor{Name: strings.TrimSpace(m[1]), Email: email})
+	}
+	return result
+}
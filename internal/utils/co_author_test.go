@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCoAuthors(t *testing.T) {
+	message := "feat: 结对完成登录页\n\n" +
+		"Co-authored-by: Bob Li <bob@example.com>\n" +
+		"co-authored-by: Carol <Carol@Example.com>\r\n" +
+		"Co-Authored-By: Bob Li <BOB@example.com>\n" +
+		"Co-authored-by: Alice <alice@example.com>\n" +
+		"Co-authored-by: 无邮箱\n"
+
+	got := ParseCoAuthors(message, "alice@example.com")
+	want := []CoAuthor{
+		{Name: "Bob Li", Email: "bob@example.com"},
+		{Name: "Carol", Email: "Carol@Example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCoAuthors() = %+v, want %+v", got, want)
+	}
+
+	// GitLab squash 提交：共同作者行与合并请求链接混排
+	squash := "Resolve \"登录失败\"\n\nCloses #12\nCo-authored-by: Dave <dave@example.com>\nSee merge request group/app!34"
+	if got := ParseCoAuthors(squash, "eve@example.com"); len(got) != 1 || got[0].Email != "dave@example.com" {
+		t.Errorf("ParseCoAuthors(squash) = %+v", got)
+	}
+
+	if got := ParseCoAuthors("fix: 没有共同作者", "a@example.com"); got != nil {
+		t.Errorf("ParseCoAuthors(no trailer) = %+v, want nil", got)
+	}
+}
+
+func TestCreditLines(t *testing.T) {
+	defer SetCreditMode(CreditModeFull)
+
+	if got := CreditLines(10, 3, false); got != 10 {
+		t.Errorf("full: CreditLines(10, 3, false) = %d, want 10", got)
+	}
+
+	if err := SetCreditMode("split"); err != nil {
+		t.Fatal(err)
+	}
+	if got := CreditLines(10, 3, true); got != 4 {
+		t.Errorf("split: CreditLines(10, 3, true) = %d, want 4", got)
+	}
+	if got := CreditLines(10, 3, false); got != 3 {
+		t.Errorf("split: CreditLines(10, 3, false) = %d, want 3", got)
+	}
+	if got := CreditLines(7, 1, true); got != 7 {
+		t.Errorf("split: CreditLines(7, 1, true) = %d, want 7", got)
+	}
+
+	if err := SetCreditMode("half"); err == nil {
+		t.Error("SetCreditMode(half) should fail")
+	}
+}
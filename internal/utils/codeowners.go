@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CodeOwnersLocations 仓库中 CODEOWNERS 文件的常见位置（按查找顺序）
+var CodeOwnersLocations = []string{
+	"CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	".github/CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// CodeOwnersRule CODEOWNERS 中的一条规则
+type CodeOwnersRule struct {
+	Section string   `json:"section,omitempty"` // GitLab 分节名称，GitHub 语法为空
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"` // @用户名、@组 或邮箱；为空表示显式声明无负责人
+	Line    int      `json:"line"`
+
+	re *regexp.Regexp
+}
+
+// Key 规则的唯一标识（分节 + 路径模式）
+func (r *CodeOwnersRule) Key() string {
+	if r.Section == "" {
+		return r.Pattern
+	}
+	return "[" + r.Section + "] " + r.Pattern
+}
+
+// CodeOwners 解析后的 CODEOWNERS 文件
+type CodeOwners struct {
+	Rules []*CodeOwnersRule `json:"rules"`
+}
+
+var codeOwnersSectionPattern = regexp.MustCompile(`^\^?\[([^\]]+)\](?:\[\d+\])?\s*(.*)$`)
+
+// ParseCodeOwners 解析 CODEOWNERS 文件内容
+// 支持 GitHub 语法以及 GitLab 的分节语法（[Section]、^[Optional]、[Section][2] 和分节默认负责人）
+func ParseCodeOwners(content string) (*CodeOwners, error) {
+	result := &CodeOwners{}
+	section := ""
+	var sectionOwners []string
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if m := codeOwnersSectionPattern.FindStringSubmatch(line); m != nil {
+			section = strings.TrimSpace(m[1])
+			sectionOwners = strings.Fields(stripCodeOwnersComment(m[2]))
+			continue
+		}
+
+		fields := strings.Fields(stripCodeOwnersComment(line))
+		if len(fields) == 0 {
+			continue
+		}
+		pattern := strings.ReplaceAll(fields[0], `\#`, "#")
+		owners := fields[1:]
+		if len(owners) == 0 {
+			owners = sectionOwners
+		}
+
+		re, err := compileGitPattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("CODEOWNERS 第 %d 行路径模式无效: %w", lineNo, err)
+		}
+		result.Rules = append(result.Rules, &CodeOwnersRule{
+			Section: section,
+			Pattern: pattern,
+			Owners:  append([]string{}, owners...),
+			Line:    lineNo,
+			re:      re,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 CODEOWNERS 失败: %w", err)
+	}
+
+	return result, nil
+}
+
+// Match 获取文件路径命中的规则
+// 每个分节中最后一条匹配的规则生效，因此一个文件可能同时属于多个分节
+func (c *CodeOwners) Match(filePath string) []*CodeOwnersRule {
+	filePath = strings.TrimPrefix(filePath, "/")
+
+	var sections []string
+	matched := make(map[string]*CodeOwnersRule)
+	for _, rule := range c.Rules {
+		if !rule.re.MatchString(filePath) {
+			continue
+		}
+		if _, ok := matched[rule.Section]; !ok {
+			sections = append(sections, rule.Section)
+		}
+		matched[rule.Section] = rule
+	}
+
+	result := make([]*CodeOwnersRule, 0, len(sections))
+	for _, section := range sections {
+		result = append(result, matched[section])
+	}
+	return result
+}
+
+// stripCodeOwnersComment 去掉行尾注释（未转义的 #）
+func stripCodeOwnersComment(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && (i == 0 || s[i-1] != '\\') {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCodeOwnersGitHub(t *testing.T) {
+	content := `# 默认负责人
+*       @org/core
+*.go    @gopher dev@example.com  # Go 代码
+/docs/  @writer
+apps/**/test/ @qa
+/build/logs
+`
+	co, err := ParseCodeOwners(content)
+	if err != nil {
+		t.Fatalf("ParseCodeOwners() error = %v", err)
+	}
+	if len(co.Rules) != 5 {
+		t.Fatalf("len(Rules) = %d, want 5", len(co.Rules))
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"README.md", []string{"@org/core"}},
+		{"internal/app/main.go", []string{"@gopher", "dev@example.com"}},
+		{"docs/guide/intro.go", []string{"@writer"}},
+		{"sub/docs/readme.md", []string{"@org/core"}},
+		{"apps/web/test/a.ts", []string{"@qa"}},
+		{"apps/test/a.ts", []string{"@qa"}},
+		{"build/logs/out.txt", []string{}},
+	}
+	for _, tt := range tests {
+		rules := co.Match(tt.path)
+		if len(rules) != 1 {
+			t.Errorf("Match(%q) 命中 %d 条规则, want 1", tt.path, len(rules))
+			continue
+		}
+		if got := rules[0].Owners; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q) owners = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseCodeOwnersGitLabSections(t *testing.T) {
+	content := `[Backend] @backend-team
+internal/
+*.sql @dba
+
+^[Docs][2] @docs-team
+*.md
+`
+	co, err := ParseCodeOwners(content)
+	if err != nil {
+		t.Fatalf("ParseCodeOwners() error = %v", err)
+	}
+
+	rules := co.Match("internal/README.md")
+	if len(rules) != 2 {
+		t.Fatalf("Match() 命中 %d 条规则, want 2", len(rules))
+	}
+	if rules[0].Key() != "[Backend] internal/" || !reflect.DeepEqual(rules[0].Owners, []string{"@backend-team"}) {
+		t.Errorf("Backend 规则 = %s %v", rules[0].Key(), rules[0].Owners)
+	}
+	if rules[1].Section != "Docs" || !reflect.DeepEqual(rules[1].Owners, []string{"@docs-team"}) {
+		t.Errorf("Docs 规则 = %s %v", rules[1].Key(), rules[1].Owners)
+	}
+
+	if rules := co.Match("migrations/001.sql"); len(rules) != 1 || rules[0].Owners[0] != "@dba" {
+		t.Errorf("Match(migrations/001.sql) = %v", rules)
+	}
+	if rules := co.Match("cmd/main.go"); len(rules) != 0 {
+		t.Errorf("Match(cmd/main.go) 不应命中规则, got %d", len(rules))
+	}
+}
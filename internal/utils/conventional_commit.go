@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ConventionalCommit Conventional Commits 解析结果
+type ConventionalCommit struct {
+	Type        string // 为空表示标题不符合规范
+	Scope       string
+	Breaking    bool // 标题带 ! 或包含 BREAKING CHANGE 脚注
+	Description string
+	Trailers    map[string][]string // 最后一段中的 git trailer / 脚注，如 Signed-off-by、Refs
+}
+
+// Conforming 标题是否符合 Conventional Commits 规范
+func (c *ConventionalCommit) Conforming() bool {
+	return c.Type != ""
+}
+
+var (
+	conventionalHeaderPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()\r\n]*)\))?(!)?: +(\S.*)$`)
+	trailerPattern            = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[A-Za-z][A-Za-z0-9-]*)(: +| #)(.*)$`)
+)
+
+// ParseConventionalCommit 解析提交说明的 Conventional Commits 标题和末尾的 trailer
+// 标题不符合规范时仍会解析 trailer（如 Signed-off-by）
+func ParseConventionalCommit(message string) *ConventionalCommit {
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	result := &ConventionalCommit{Trailers: map[string][]string{}}
+
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	if m := conventionalHeaderPattern.FindStringSubmatch(strings.TrimSpace(lines[0])); m != nil {
+		result.Type = strings.ToLower(m[1])
+		result.Scope = strings.TrimSpace(m[2])
+		result.Breaking = m[3] == "!"
+		result.Description = strings.TrimSpace(m[4])
+	}
+
+	// 只有正文之后的最后一段才可能是 trailer 段
+	if len(lines) > 1 {
+		paragraphs := strings.Split(strings.Join(lines[1:], "\n"), "\n\n")
+		last := strings.TrimSpace(paragraphs[len(paragraphs)-1])
+		if trailers, ok := parseTrailers(last); ok {
+			result.Trailers = trailers
+		}
+	}
+
+	for key := range result.Trailers {
+		if key == "BREAKING CHANGE" || key == "BREAKING-CHANGE" {
+			result.Breaking = true
+		}
+	}
+	return result
+}
+
+// parseTrailers 解析 trailer 段，段内任意一行不符合 trailer 格式（续行除外）时返回 false
+func parseTrailers(paragraph string) (map[string][]string, bool) {
+	trailers := map[string][]string{}
+	if paragraph == "" {
+		return trailers, false
+	}
+
+	var lastKey string
+	for _, line := range strings.Split(paragraph, "\n") {
+		// 以空白开头的续行追加到上一个 trailer
+		if lastKey != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			values := trailers[lastKey]
+			values[len(values)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+		m := trailerPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			return map[string][]string{}, false
+		}
+		key := m[1]
+		value := strings.TrimSpace(m[3])
+		if m[2] == " #" {
+			value = "#" + value // "Refs #42" 形式保留 # 号
+		}
+		trailers[key] = append(trailers[key], value)
+		lastKey = key
+	}
+	return trailers, true
+}
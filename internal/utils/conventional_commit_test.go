@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		name         string
+		message      string
+		wantType     string
+		wantScope    string
+		wantBreaking bool
+		wantTrailers map[string][]string
+	}{
+		{
+			name:         "带 scope",
+			message:      "feat(api): add leaderboard",
+			wantType:     "feat",
+			wantScope:    "api",
+			wantTrailers: map[string][]string{},
+		},
+		{
+			name:         "感叹号表示破坏性变更",
+			message:      "Refactor!: drop v1 endpoints",
+			wantType:     "refactor",
+			wantBreaking: true,
+			wantTrailers: map[string][]string{},
+		},
+		{
+			name:         "BREAKING CHANGE 脚注",
+			message:      "fix: handle nil\n\nLonger body.\n\nBREAKING CHANGE: config key renamed\nRefs #42",
+			wantType:     "fix",
+			wantBreaking: true,
+			wantTrailers: map[string][]string{
+				"BREAKING CHANGE": {"config key renamed"},
+				"Refs":            {"#42"},
+			},
+		},
+		{
+			name:     "不符合规范但有 trailer",
+			message:  "Update README\n\nSigned-off-by: A <a@example.com>\nSigned-off-by: B <b@example.com>",
+			wantType: "",
+			wantTrailers: map[string][]string{
+				"Signed-off-by": {"A <a@example.com>", "B <b@example.com>"},
+			},
+		},
+		{
+			name:         "最后一段是正文",
+			message:      "docs: typo\n\nThis fixes: a typo in the guide\nand more text",
+			wantType:     "docs",
+			wantTrailers: map[string][]string{},
+		},
+		{
+			name:         "缺少冒号后的空格",
+			message:      "feat:missing space",
+			wantTrailers: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseConventionalCommit(tt.message)
+			if got.Type != tt.wantType || got.Scope != tt.wantScope || got.Breaking != tt.wantBreaking {
+				t.Errorf("ParseConventionalCommit() = %q/%q/%v, want %q/%q/%v",
+					got.Type, got.Scope, got.Breaking, tt.wantType, tt.wantScope, tt.wantBreaking)
+			}
+			if !reflect.DeepEqual(got.Trailers, tt.wantTrailers) {
+				t.Errorf("Trailers = %v, want %v", got.Trailers, tt.wantTrailers)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"regexp"
 	"strings"
 )
 
@@ -42,3 +43,39 @@ func ParseDiffStats(diff string) (added, removed int) {
 	return added, removed
 }
 
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// DiffFileHead 从 diff 中还原文件开头的内容（最多 maxLines 行），用于 shebang 等基于内容的语言判断
+// 只有第一个 hunk 从第 1 行开始时才能还原；删除的文件还原删除前的内容
+func DiffFileHead(diff string, maxLines int) string {
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		m := hunkHeaderPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		// 新文件从第 1 行开始时取新内容，整个文件被删除时取旧内容
+		keep := byte('+')
+		switch {
+		case m[2] == "1":
+		case m[2] == "0" && m[1] == "1":
+			keep = '-'
+		default:
+			return ""
+		}
+
+		var head []string
+		for _, l := range lines[i+1:] {
+			if len(head) >= maxLines || strings.HasPrefix(l, "@@") {
+				break
+			}
+			if l != "" && (l[0] == keep || l[0] == ' ') {
+				head = append(head, l[1:])
+			}
+		}
+		return strings.Join(head, "\n")
+	}
+	return ""
+}
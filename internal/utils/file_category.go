@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 文件类别
+const (
+	FileCategoryProduction = "production"
+	FileCategoryTest       = "test"
+	FileCategoryDocs       = "docs"
+	FileCategoryConfig     = "config"
+	FileCategoryBuild      = "build"
+)
+
+// FileCategories 所有文件类别（按展示顺序）
+var FileCategories = []string{
+	FileCategoryProduction,
+	FileCategoryTest,
+	FileCategoryDocs,
+	FileCategoryConfig,
+	FileCategoryBuild,
+}
+
+// ValidFileCategory 判断文件类别是否受支持
+func ValidFileCategory(category string) bool {
+	for _, c := range FileCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// FileCategoryRule 文件类别规则（gitignore 风格的路径模式）
+type FileCategoryRule struct {
+	Pattern  string `json:"pattern"`
+	Category string `json:"category"`
+}
+
+// defaultFileCategoryRules 内置类别规则，最后一条匹配的规则生效，都不匹配时为生产代码
+// 测试规则放在最后，使 testdata/config.json、tests/fixtures/*.yaml 等归为测试
+var defaultFileCategoryRules = []FileCategoryRule{
+	// 文档
+	{"*.md", FileCategoryDocs},
+	{"*.markdown", FileCategoryDocs},
+	{"*.rst", FileCategoryDocs},
+	{"*.adoc", FileCategoryDocs},
+	{"*.txt", FileCategoryDocs},
+	{"docs/", FileCategoryDocs},
+	{"doc/", FileCategoryDocs},
+	{"LICENSE*", FileCategoryDocs},
+	{"CHANGELOG*", FileCategoryDocs},
+	{"AUTHORS", FileCategoryDocs},
+	// 配置
+	{"*.yaml", FileCategoryConfig},
+	{"*.yml", FileCategoryConfig},
+	{"*.json", FileCategoryConfig},
+	{"*.toml", FileCategoryConfig},
+	{"*.ini", FileCategoryConfig},
+	{"*.cfg", FileCategoryConfig},
+	{"*.conf", FileCategoryConfig},
+	{"*.properties", FileCategoryConfig},
+	{"*.env", FileCategoryConfig},
+	{".env*", FileCategoryConfig},
+	{"env.example", FileCategoryConfig},
+	{".editorconfig", FileCategoryConfig},
+	{".gitignore", FileCategoryConfig},
+	{".gitattributes", FileCategoryConfig},
+	{"CODEOWNERS", FileCategoryConfig},
+	{"*.lock", FileCategoryConfig},
+	{"go.sum", FileCategoryConfig},
+	// 构建与 CI
+	{"Makefile", FileCategoryBuild},
+	{"GNUmakefile", FileCategoryBuild},
+	{"*.mk", FileCategoryBuild},
+	{"Dockerfile*", FileCategoryBuild},
+	{"*.dockerfile", FileCategoryBuild},
+	{"docker-compose*.yml", FileCategoryBuild},
+	{"docker-compose*.yaml", FileCategoryBuild},
+	{".dockerignore", FileCategoryBuild},
+	{"CMakeLists.txt", FileCategoryBuild},
+	{"*.cmake", FileCategoryBuild},
+	{"BUILD", FileCategoryBuild},
+	{"BUILD.bazel", FileCategoryBuild},
+	{"WORKSPACE*", FileCategoryBuild},
+	{"*.bzl", FileCategoryBuild},
+	{"go.mod", FileCategoryBuild},
+	{"package.json", FileCategoryBuild},
+	{"pom.xml", FileCategoryBuild},
+	{"build.gradle*", FileCategoryBuild},
+	{"settings.gradle*", FileCategoryBuild},
+	{"Cargo.toml", FileCategoryBuild},
+	{"setup.py", FileCategoryBuild},
+	{"setup.cfg", FileCategoryBuild},
+	{"pyproject.toml", FileCategoryBuild},
+	{"requirements*.txt", FileCategoryBuild},
+	{"Gemfile", FileCategoryBuild},
+	{"*.gemspec", FileCategoryBuild},
+	{"*.csproj", FileCategoryBuild},
+	{"*.sln", FileCategoryBuild},
+	{"Jenkinsfile", FileCategoryBuild},
+	{".gitlab-ci.yml", FileCategoryBuild},
+	{".gitlab-ci/", FileCategoryBuild},
+	{".github/workflows/", FileCategoryBuild},
+	{".circleci/", FileCategoryBuild},
+	// 测试
+	{"test/", FileCategoryTest},
+	{"tests/", FileCategoryTest},
+	{"__tests__/", FileCategoryTest},
+	{"__mocks__/", FileCategoryTest},
+	{"spec/", FileCategoryTest},
+	{"testdata/", FileCategoryTest},
+	{"e2e/", FileCategoryTest},
+	{"**/src/test/", FileCategoryTest},
+	{"**/src/androidTest/", FileCategoryTest},
+	{"*_test.go", FileCategoryTest},
+	{"test_*.py", FileCategoryTest},
+	{"*_test.py", FileCategoryTest},
+	{"conftest.py", FileCategoryTest},
+	{"*_spec.rb", FileCategoryTest},
+	{"*_test.rb", FileCategoryTest},
+	{"*.test.js", FileCategoryTest},
+	{"*.test.jsx", FileCategoryTest},
+	{"*.test.ts", FileCategoryTest},
+	{"*.test.tsx", FileCategoryTest},
+	{"*.spec.js", FileCategoryTest},
+	{"*.spec.jsx", FileCategoryTest},
+	{"*.spec.ts", FileCategoryTest},
+	{"*.spec.tsx", FileCategoryTest},
+	{"*Test.java", FileCategoryTest},
+	{"*Tests.java", FileCategoryTest},
+	{"*Test.kt", FileCategoryTest},
+	{"*Tests.cs", FileCategoryTest},
+	{"*Test.cs", FileCategoryTest},
+	{"*Test.php", FileCategoryTest},
+	{"*_test.exs", FileCategoryTest},
+	{"*_test.dart", FileCategoryTest},
+	{"*Tests.swift", FileCategoryTest},
+	{"*_test.cc", FileCategoryTest},
+	{"*_test.cpp", FileCategoryTest},
+	{"*_unittest.cc", FileCategoryTest},
+}
+
+type compiledFileCategoryRule struct {
+	re       *regexp.Regexp
+	category string
+}
+
+// FileCategorizer 文件类别判断器，后添加的规则优先
+type FileCategorizer struct {
+	rules []compiledFileCategoryRule
+}
+
+// NewFileCategorizer 创建包含内置规则的类别判断器，extra（如配置文件中的规则）优先于内置规则
+func NewFileCategorizer(extra []FileCategoryRule) (*FileCategorizer, error) {
+	c := &FileCategorizer{}
+	for _, rules := range [][]FileCategoryRule{defaultFileCategoryRules, extra} {
+		for _, rule := range rules {
+			if !ValidFileCategory(rule.Category) {
+				return nil, fmt.Errorf("不支持的文件类别: %s", rule.Category)
+			}
+			re, err := compileGitPattern(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("文件类别规则 %q 无效: %w", rule.Pattern, err)
+			}
+			c.rules = append(c.rules, compiledFileCategoryRule{re: re, category: rule.Category})
+		}
+	}
+	return c, nil
+}
+
+// Categorize 获取文件类别，最后一条匹配的规则生效
+func (c *FileCategorizer) Categorize(filePath string) string {
+	filePath = strings.TrimPrefix(filePath, "/")
+	category := FileCategoryProduction
+	for _, rule := range c.rules {
+		if rule.re.MatchString(filePath) {
+			category = rule.category
+		}
+	}
+	return category
+}
+
+var (
+	defaultCategorizer, _ = NewFileCategorizer(nil)
+	defaultCategorizerMu  sync.RWMutex
+)
+
+// SetFileCategoryRules 设置全局的额外文件类别规则（优先于内置规则）
+func SetFileCategoryRules(rules []FileCategoryRule) error {
+	categorizer, err := NewFileCategorizer(rules)
+	if err != nil {
+		return err
+	}
+
+	defaultCategorizerMu.Lock()
+	defer defaultCategorizerMu.Unlock()
+	defaultCategorizer = categorizer
+	return nil
+}
+
+// LoadFileCategoryRules 从 JSON 文件加载文件类别规则
+// 文件内容为规则数组，如 [{"pattern": "e2e/", "category": "test"}]
+func LoadFileCategoryRules(file string) ([]FileCategoryRule, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件类别规则失败: %w", err)
+	}
+	var rules []FileCategoryRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("解析文件类别规则 %s 失败: %w", file, err)
+	}
+	return rules, nil
+}
+
+// CategorizeFile 使用全局规则获取文件类别
+func CategorizeFile(filePath string) string {
+	defaultCategorizerMu.RLock()
+	defer defaultCategorizerMu.RUnlock()
+	return defaultCategorizer.Categorize(filePath)
+}
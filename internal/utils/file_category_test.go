@@ -0,0 +1,46 @@
+package utils
+
+import "testing"
+
+func TestFileCategorizer(t *testing.T) {
+	c, err := NewFileCategorizer([]FileCategoryRule{
+		{Pattern: "qa/", Category: FileCategoryTest},
+		{Pattern: "deploy/*.sh", Category: FileCategoryBuild},
+	})
+	if err != nil {
+		t.Fatalf("NewFileCategorizer() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"internal/service/commit.go", FileCategoryProduction},
+		{"internal/service/commit_test.go", FileCategoryTest},
+		{"internal/utils/testdata/config.json", FileCategoryTest},
+		{"spec/models/user_spec.rb", FileCategoryTest},
+		{"web/src/__tests__/App.tsx", FileCategoryTest},
+		{"web/src/App.test.tsx", FileCategoryTest},
+		{"module-a/src/test/java/com/x/FooTest.java", FileCategoryTest},
+		{"module-a/src/main/java/com/x/Foo.java", FileCategoryProduction},
+		{"tests/test_api.py", FileCategoryTest},
+		{"README.md", FileCategoryDocs},
+		{"docs/architecture.png", FileCategoryDocs},
+		{"config/app.yaml", FileCategoryConfig},
+		{"package.json", FileCategoryBuild},
+		{"Makefile", FileCategoryBuild},
+		{"deploy/Dockerfile.prod", FileCategoryBuild},
+		{".gitlab-ci.yml", FileCategoryBuild},
+		{"qa/smoke.go", FileCategoryTest},
+		{"deploy/release.sh", FileCategoryBuild},
+	}
+	for _, tt := range tests {
+		if got := c.Categorize(tt.path); got != tt.want {
+			t.Errorf("Categorize(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := NewFileCategorizer([]FileCategoryRule{{Pattern: "*.x", Category: "misc"}}); err == nil {
+		t.Error("NewFileCategorizer() 应拒绝不支持的类别")
+	}
+}
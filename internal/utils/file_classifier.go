@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 文件分类（空字符串表示普通文件，计入行数统计）
+const (
+	FileClassNone      = ""
+	FileClassGenerated = "generated" // 生成代码，如 *.pb.go、压缩后的 js
+	FileClassVendored  = "vendored"  // 第三方代码，如 vendor/、node_modules/
+	FileClassLockfile  = "lockfile"  // 依赖锁文件，如 go.sum、package-lock.json
+)
+
+// ValidFileClass 判断文件分类是否受支持
+func ValidFileClass(class string) bool {
+	switch class {
+	case FileClassNone, FileClassGenerated, FileClassVendored, FileClassLockfile:
+		return true
+	}
+	return false
+}
+
+// FileClassRule 文件分类规则，Class 为空表示强制视为普通文件
+type FileClassRule struct {
+	Pattern string `json:"pattern"`
+	Class   string `json:"class"`
+}
+
+// defaultFileClassRules 内置分类规则（参考 GitHub linguist）
+var defaultFileClassRules = []FileClassRule{
+	// 依赖锁文件
+	{"go.sum", FileClassLockfile},
+	{"package-lock.json", FileClassLockfile},
+	{"npm-shrinkwrap.json", FileClassLockfile},
+	{"yarn.lock", FileClassLockfile},
+	{"pnpm-lock.yaml", FileClassLockfile},
+	{"bun.lockb", FileClassLockfile},
+	{"Cargo.lock", FileClassLockfile},
+	{"Gemfile.lock", FileClassLockfile},
+	{"composer.lock", FileClassLockfile},
+	{"poetry.lock", FileClassLockfile},
+	{"Pipfile.lock", FileClassLockfile},
+	{"uv.lock", FileClassLockfile},
+	{"Podfile.lock", FileClassLockfile},
+	{"mix.lock", FileClassLockfile},
+	{"flake.lock", FileClassLockfile},
+	{"packages.lock.json", FileClassLockfile},
+	{"gradle.lockfile", FileClassLockfile},
+	{"pubspec.lock", FileClassLockfile},
+	// 第三方代码
+	{"vendor/", FileClassVendored},
+	{"node_modules/", FileClassVendored},
+	{"bower_components/", FileClassVendored},
+	{"third_party/", FileClassVendored},
+	{"Pods/", FileClassVendored},
+	// 生成代码
+	{"*.pb.go", FileClassGenerated},
+	{"*.pb.gw.go", FileClassGenerated},
+	{"*_pb2.py", FileClassGenerated},
+	{"*_pb2_grpc.py", FileClassGenerated},
+	{"*.pb.cc", FileClassGenerated},
+	{"*.pb.h", FileClassGenerated},
+	{"*_generated.go", FileClassGenerated},
+	{"zz_generated.*", FileClassGenerated},
+	{"*.generated.*", FileClassGenerated},
+	{"*.designer.cs", FileClassGenerated},
+	{"*.min.js", FileClassGenerated},
+	{"*.min.css", FileClassGenerated},
+	{"*.js.map", FileClassGenerated},
+	{"*.css.map", FileClassGenerated},
+}
+
+// DefaultFileClassRules 获取内置分类规则（副本）
+func DefaultFileClassRules() []FileClassRule {
+	return append([]FileClassRule(nil), defaultFileClassRules...)
+}
+
+type compiledFileClassRule struct {
+	re    *regexp.Regexp
+	class string
+}
+
+// FileClassifier 文件分类器，后添加的规则优先
+type FileClassifier struct {
+	rules []compiledFileClassRule
+}
+
+// NewFileClassifier 创建包含内置规则的分类器，overrides（如项目级规则）优先于内置规则
+func NewFileClassifier(overrides []FileClassRule) (*FileClassifier, error) {
+	c := &FileClassifier{}
+	for _, rules := range [][]FileClassRule{defaultFileClassRules, overrides} {
+		for _, rule := range rules {
+			if !ValidFileClass(rule.Class) {
+				return nil, fmt.Errorf("不支持的文件分类: %s", rule.Class)
+			}
+			re, err := compileGitPattern(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("文件分类规则 %q 无效: %w", rule.Pattern, err)
+			}
+			c.rules = append(c.rules, compiledFileClassRule{re: re, class: rule.Class})
+		}
+	}
+	return c, nil
+}
+
+// Classify 获取文件分类，最后一条匹配的规则生效
+func (c *FileClassifier) Classify(filePath string) string {
+	filePath = strings.TrimPrefix(filePath, "/")
+	class := FileClassNone
+	for _, rule := range c.rules {
+		if rule.re.MatchString(filePath) {
+			class = rule.class
+		}
+	}
+	return class
+}
+
+// ParseGitAttributes 从 .gitattributes 中提取 linguist-generated / linguist-vendored 规则
+// linguist-generated、linguist-generated=true 标记为生成代码；-linguist-generated、=false 强制视为普通文件
+func ParseGitAttributes(content string) []FileClassRule {
+	var rules []FileClassRule
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			for name, class := range map[string]string{
+				"linguist-generated": FileClassGenerated,
+				"linguist-vendored":  FileClassVendored,
+			} {
+				switch attr {
+				case name, name + "=true":
+					rules = append(rules, FileClassRule{Pattern: fields[0], Class: class})
+				case "-" + name, "!" + name, name + "=false":
+					rules = append(rules, FileClassRule{Pattern: fields[0], Class: FileClassNone})
+				}
+			}
+		}
+	}
+	return rules
+}
@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestFileClassifier(t *testing.T) {
+	overrides := append([]FileClassRule{{Pattern: "/third_party/ours/", Class: FileClassNone}},
+		ParseGitAttributes("# 生成的 API 客户端\napi/client/** linguist-generated\n*.snap linguist-generated=true\nvendor/patched.go -linguist-vendored\n")...)
+	c, err := NewFileClassifier(overrides)
+	if err != nil {
+		t.Fatalf("NewFileClassifier() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"go.sum", FileClassLockfile},
+		{"web/package-lock.json", FileClassLockfile},
+		{"vendor/github.com/x/y.go", FileClassVendored},
+		{"web/node_modules/a/index.js", FileClassVendored},
+		{"api/v1/user.pb.go", FileClassGenerated},
+		{"static/app.min.js", FileClassGenerated},
+		{"api/client/users.ts", FileClassGenerated},
+		{"ui/__snapshots__/a.snap", FileClassGenerated},
+		{"third_party/ours/lib.go", FileClassNone},
+		{"third_party/theirs/lib.go", FileClassVendored},
+		{"vendor/patched.go", FileClassNone},
+		{"internal/service/commit.go", FileClassNone},
+	}
+	for _, tt := range tests {
+		if got := c.Classify(tt.path); got != tt.want {
+			t.Errorf("Classify(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := NewFileClassifier([]FileClassRule{{Pattern: "*.x", Class: "unknown"}}); err == nil {
+		t.Error("NewFileClassifier() 应拒绝不支持的分类")
+	}
+}
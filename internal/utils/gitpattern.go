@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// compileGitPattern 将 gitignore 风格的路径模式（CODEOWNERS、.gitattributes 共用）转换为正则表达式
+// 以 / 开头或中间包含 / 的模式相对仓库根目录，否则匹配任意层级；以 / 结尾的模式匹配整个目录
+func compileGitPattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.Trim(pattern, "/")
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(p, "/")
+	if p == "" {
+		return regexp.Compile(`^.*$`)
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		}
+	}
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
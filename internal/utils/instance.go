@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// InstanceHost 从项目地址中提取平台实例（主机名，含非默认端口，统一小写）
+// 依次尝试传入的地址，支持 http(s) 地址和 git@host:group/project.git 形式的 SSH 地址，都无法解析时返回空字符串
+func InstanceHost(rawURLs ...string) string {
+	for _, raw := range rawURLs {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if !strings.Contains(raw, "://") {
+			// scp 风格的 SSH 地址：user@host:path
+			if at := strings.Index(raw, "@"); at >= 0 {
+				if colon := strings.Index(raw[at:], ":"); colon > 0 {
+					return strings.ToLower(raw[at+1 : at+colon])
+				}
+			}
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		return strings.ToLower(u.Host)
+	}
+	return ""
+}
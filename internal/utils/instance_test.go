@@ -0,0 +1,22 @@
+package utils
+
+import "testing"
+
+func TestInstanceHost(t *testing.T) {
+	tests := []struct {
+		urls []string
+		want string
+	}{
+		{[]string{"https://GitLab.Example.com/group/project"}, "gitlab.example.com"},
+		{[]string{"http://gitlab.local:8080/group/project"}, "gitlab.local:8080"},
+		{[]string{"", "git@github.com:org/repo.git"}, "github.com"},
+		{[]string{"ssh://git@gitee.com/org/repo.git"}, "gitee.com"},
+		{[]string{"not a url", "https://gitlab.com/a/b"}, "gitlab.com"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := InstanceHost(tt.urls...); got != tt.want {
+			t.Errorf("InstanceHost(%q) = %q, want %q", tt.urls, got, tt.want)
+		}
+	}
+}
@@ -1,85 +1,418 @@
 package utils
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 )
 
-// DetectLanguage 根据文件扩展名检测编程语言
-func DetectLanguage(filePath string) string {
-	ext := strings.ToLower(filepath.Ext(filePath))
-	if ext == "" {
-		return "unknown"
-	}
-
-	// 移除点号
-	ext = strings.TrimPrefix(ext, ".")
-
-	// 语言映射表
-	languageMap := map[string]string{
-		// Go
-		"go": "go",
-		// Java
-		"java": "java",
-		// JavaScript/TypeScript
-		"js":   "javascript",
-		"jsx":  "javascript",
-		"ts":   "typescript",
-		"tsx":  "typescript",
-		"vue":  "vue",
-		// Python
-		"py":   "python",
-		"pyw":  "python",
-		"pyc":  "python",
-		// C/C++
-		"c":    "c",
-		"cpp":  "cpp",
-		"cc":   "cpp",
-		"cxx":  "cpp",
-		"h":    "c",
-		"hpp":  "cpp",
-		// C#
-		"cs":   "csharp",
-		// PHP
-		"php":  "php",
-		// Ruby
-		"rb":   "ruby",
-		// Swift
-		"swift": "swift",
-		// Kotlin
-		"kt":   "kotlin",
-		// Rust
-		"rs":   "rust",
-		// Shell
-		"sh":   "shell",
-		"bash": "shell",
-		"zsh":  "shell",
-		// SQL
-		"sql":  "sql",
-		// HTML/CSS
-		"html": "html",
-		"htm":  "html",
-		"css":  "css",
-		"scss": "css",
-		"sass": "css",
-		// JSON/YAML
-		"json": "json",
-		"yaml": "yaml",
-		"yml":  "yaml",
-		// Markdown
-		"md":   "markdown",
-		// Docker
-		"dockerfile": "dockerfile",
-		// Makefile
-		"makefile": "makefile",
-		"mk":       "makefile",
-	}
-
-	if lang, ok := languageMap[ext]; ok {
+// LanguageUnknown 无法识别的语言
+const LanguageUnknown = "unknown"
+
+// LanguageMappings 语言映射配置，用于覆盖或补充内置规则
+// 键不区分大小写，扩展名可带或不带点号，支持多段扩展名（如 d.ts）
+type LanguageMappings struct {
+	Extensions   map[string]string `json:"extensions"`   // 扩展名 -> 语言
+	Filenames    map[string]string `json:"filenames"`    // 完整文件名 -> 语言，如 Dockerfile、BUILD.bazel
+	Interpreters map[string]string `json:"interpreters"` // shebang 解释器 -> 语言，如 node、python3
+}
+
+// defaultExtensionLanguages 内置扩展名映射
+var defaultExtensionLanguages = map[string]string{
+	// Go
+	"go": "go",
+	// Java / JVM
+	"java":       "java",
+	"kt":         "kotlin",
+	"kts":        "kotlin",
+	"gradle.kts": "kotlin",
+	"scala":      "scala",
+	"sc":         "scala",
+	"groovy":     "groovy",
+	"gradle":     "groovy",
+	"clj":        "clojure",
+	"cljs":       "clojure",
+	// JavaScript/TypeScript
+	"js":     "javascript",
+	"jsx":    "javascript",
+	"mjs":    "javascript",
+	"cjs":    "javascript",
+	"ts":     "typescript",
+	"tsx":    "typescript",
+	"mts":    "typescript",
+	"cts":    "typescript",
+	"d.ts":   "typescript",
+	"vue":    "vue",
+	"svelte": "svelte",
+	// Python
+	"py":  "python",
+	"pyw": "python",
+	"pyi": "python",
+	"pyx": "python",
+	// C/C++（.h 根据内容判断，无内容时视为 C）
+	"c":   "c",
+	"h":   "c",
+	"cpp": "cpp",
+	"cc":  "cpp",
+	"cxx": "cpp",
+	"c++": "cpp",
+	"hpp": "cpp",
+	"hh":  "cpp",
+	"hxx": "cpp",
+	"ipp": "cpp",
+	"m":   "objective-c",
+	"mm":  "objective-c",
+	// C# / .NET
+	"cs":     "csharp",
+	"csx":    "csharp",
+	"fs":     "fsharp",
+	"vb":     "vb",
+	"csproj": "xml",
+	// PHP
+	"php":       "php",
+	"blade.php": "blade",
+	// Ruby
+	"rb":       "ruby",
+	"rake":     "ruby",
+	"gemspec":  "ruby",
+	"erb":      "erb",
+	"html.erb": "erb",
+	// Swift / Dart
+	"swift": "swift",
+	"dart":  "dart",
+	// Rust
+	"rs": "rust",
+	// 其他语言
+	"lua":    "lua",
+	"pl":     "perl",
+	"pm":     "perl",
+	"r":      "r",
+	"ex":     "elixir",
+	"exs":    "elixir",
+	"erl":    "erlang",
+	"hs":     "haskell",
+	"ml":     "ocaml",
+	"zig":    "zig",
+	"nim":    "nim",
+	"jl":     "julia",
+	"sol":    "solidity",
+	"proto":  "protobuf",
+	"tf":     "terraform",
+	"tfvars": "terraform",
+	"hcl":    "hcl",
+	"bzl":    "starlark",
+	"cmake":  "cmake",
+	"nix":    "nix",
+	// Shell
+	"sh":   "shell",
+	"bash": "shell",
+	"zsh":  "shell",
+	"ksh":  "shell",
+	"fish": "shell",
+	"ps1":  "powershell",
+	"psm1": "powershell",
+	"bat":  "batch",
+	"cmd":  "batch",
+	// SQL
+	"sql": "sql",
+	// HTML/CSS
+	"html": "html",
+	"htm":  "html",
+	"css":  "css",
+	"scss": "css",
+	"sass": "css",
+	"less": "css",
+	// 数据与配置
+	"json":    "json",
+	"jsonc":   "json",
+	"yaml":    "yaml",
+	"yml":     "yaml",
+	"toml":    "toml",
+	"xml":     "xml",
+	"ini":     "ini",
+	"graphql": "graphql",
+	"gql":     "graphql",
+	// 文档
+	"md":       "markdown",
+	"markdown": "markdown",
+	"rst":      "restructuredtext",
+	"adoc":     "asciidoc",
+	"tex":      "tex",
+	// Docker / Makefile
+	"dockerfile": "dockerfile",
+	"mk":         "makefile",
+	"mak":        "makefile",
+	// 压缩包
+	"zip":     "archive",
+	"jar":     "archive",
+	"tar":     "archive",
+	"tgz":     "archive",
+	"tar.gz":  "archive",
+	"tar.bz2": "archive",
+	"tar.xz":  "archive",
+}
+
+// defaultFilenameLanguages 内置文件名映射（不区分大小写）
+var defaultFilenameLanguages = map[string]string{
+	"dockerfile":      "dockerfile",
+	"containerfile":   "dockerfile",
+	"makefile":        "makefile",
+	"gnumakefile":     "makefile",
+	"cmakelists.txt":  "cmake",
+	"jenkinsfile":     "groovy",
+	"rakefile":        "ruby",
+	"gemfile":         "ruby",
+	"podfile":         "ruby",
+	"vagrantfile":     "ruby",
+	"brewfile":        "ruby",
+	"build.bazel":     "starlark",
+	"workspace.bazel": "starlark",
+	"tiltfile":        "starlark",
+	"go.mod":          "go",
+	"go.work":         "go",
+	".bashrc":         "shell",
+	".bash_profile":   "shell",
+	".profile":        "shell",
+	".zshrc":          "shell",
+}
+
+// defaultInterpreterLanguages 内置 shebang 解释器映射（版本号已去除，如 python3 -> python）
+var defaultInterpreterLanguages = map[string]string{
+	"sh":      "shell",
+	"bash":    "shell",
+	"zsh":     "shell",
+	"ksh":     "shell",
+	"dash":    "shell",
+	"ash":     "shell",
+	"fish":    "shell",
+	"python":  "python",
+	"pypy":    "python",
+	"node":    "javascript",
+	"nodejs":  "javascript",
+	"deno":    "typescript",
+	"ts-node": "typescript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+	"lua":     "lua",
+	"rscript": "r",
+	"pwsh":    "powershell",
+	"elixir":  "elixir",
+	"escript": "erlang",
+	"groovy":  "groovy",
+}
+
+// defaultFilenamePrefixes 带后缀变体的文件名，如 Dockerfile.prod、Makefile.linux
+var defaultFilenamePrefixes = map[string]string{
+	"dockerfile.":    "dockerfile",
+	"containerfile.": "dockerfile",
+	"makefile.":      "makefile",
+}
+
+var (
+	cppHeaderPattern  = regexp.MustCompile(`(?m)^\s*(?:class\s+\w+|namespace\s+\w*|template\s*<|using\s+namespace\b|(?:public|private|protected)\s*:|#include\s*<(?:iostream|string|vector|map|memory|algorithm|cstdint|cstdio|cstdlib)>)`)
+	objcHeaderPattern = regexp.MustCompile(`(?m)^\s*(?:@interface|@protocol|@property|#import\b)`)
+)
+
+// ambiguousExtensions 需要根据内容判断语言的扩展名
+var ambiguousExtensions = map[string]func(content string) string{
+	"h": func(content string) string {
+		switch {
+		case objcHeaderPattern.MatchString(content):
+			return "objective-c"
+		case cppHeaderPattern.MatchString(content):
+			return "cpp"
+		}
+		return ""
+	},
+}
+
+// LanguageDetector 语言检测器
+// 依次按完整文件名、扩展名（最长的多段扩展名优先）、文件名前缀判断，内容可用时再检查 shebang
+type LanguageDetector struct {
+	extensions   map[string]string
+	filenames    map[string]string
+	interpreters map[string]string
+	// overridden 配置中显式指定的扩展名，不再根据内容判断
+	overridden map[string]bool
+}
+
+// NewLanguageDetector 创建语言检测器，overrides 优先于内置规则（可以为 nil）
+func NewLanguageDetector(overrides *LanguageMappings) *LanguageDetector {
+	d := &LanguageDetector{
+		extensions:   make(map[string]string, len(defaultExtensionLanguages)),
+		filenames:    make(map[string]string, len(defaultFilenameLanguages)),
+		interpreters: make(map[string]string, len(defaultInterpreterLanguages)),
+		overridden:   make(map[string]bool),
+	}
+	for k, v := range defaultExtensionLanguages {
+		d.extensions[k] = v
+	}
+	for k, v := range defaultFilenameLanguages {
+		d.filenames[k] = v
+	}
+	for k, v := range defaultInterpreterLanguages {
+		d.interpreters[k] = v
+	}
+
+	if overrides != nil {
+		for k, v := range overrides.Extensions {
+			ext := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(k), "."))
+			d.extensions[ext] = strings.ToLower(strings.TrimSpace(v))
+			d.overridden[ext] = true
+		}
+		for k, v := range overrides.Filenames {
+			d.filenames[strings.ToLower(strings.TrimSpace(k))] = strings.ToLower(strings.TrimSpace(v))
+		}
+		for k, v := range overrides.Interpreters {
+			d.interpreters[strings.ToLower(strings.TrimSpace(k))] = strings.ToLower(strings.TrimSpace(v))
+		}
+	}
+	return d
+}
+
+// DetectByPath 仅根据路径检测语言
+// 第二个返回值为 false 表示结果不确定（未识别，或扩展名需要结合内容判断，如 .h）
+func (d *LanguageDetector) DetectByPath(filePath string) (string, bool) {
+	name := strings.ToLower(path.Base(filepath.ToSlash(filePath)))
+	if name == "" || name == "." || name == "/" {
+		return LanguageUnknown, false
+	}
+
+	if lang, ok := d.filenames[name]; ok {
+		return lang, true
+	}
+
+	// 多段扩展名：index.d.ts 依次尝试 d.ts、ts；以点号开头的文件名不把首段当作扩展名
+	for i := 1; i < len(name); i++ {
+		if name[i] != '.' {
+			continue
+		}
+		ext := name[i+1:]
+		if lang, ok := d.extensions[ext]; ok {
+			_, ambiguous := ambiguousExtensions[ext]
+			return lang, !ambiguous || d.overridden[ext]
+		}
+	}
+
+	for prefix, lang := range defaultFilenamePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return lang, true
+		}
+	}
+
+	return LanguageUnknown, false
+}
+
+// Detect 检测语言，content 为文件开头内容（可为空）
+// 内容可用时用于判断 .h 等歧义扩展名，以及无扩展名脚本的 shebang
+func (d *LanguageDetector) Detect(filePath, content string) string {
+	lang, certain := d.DetectByPath(filePath)
+	if certain || content == "" {
 		return lang
 	}
 
-	return "unknown"
+	if lang != LanguageUnknown {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filePath), "."))
+		if resolve, ok := ambiguousExtensions[ext]; ok {
+			if resolved := resolve(content); resolved != "" {
+				return resolved
+			}
+		}
+		return lang
+	}
+
+	if interpreter := shebangInterpreter(content); interpreter != "" {
+		if lang, ok := d.interpreters[interpreter]; ok {
+			return lang
+		}
+	}
+	return LanguageUnknown
+}
+
+var interpreterVersionPattern = regexp.MustCompile(`[\d.]+$`)
+
+// shebangInterpreter 解析首行 shebang 中的解释器名称（去除路径和版本号）
+// 支持 #!/usr/bin/env python3、#!/usr/bin/env -S node --flag 和 #!/bin/bash 等形式
+func shebangInterpreter(content string) string {
+	line := content
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "-") || strings.Contains(f, "=") {
+				continue
+			}
+			interpreter = path.Base(f)
+			break
+		}
+	}
+	interpreter = strings.ToLower(interpreter)
+	if trimmed := interpreterVersionPattern.ReplaceAllString(interpreter, ""); trimmed != "" {
+		interpreter = trimmed
+	}
+	return interpreter
+}
+
+var (
+	defaultDetector   = NewLanguageDetector(nil)
+	defaultDetectorMu sync.RWMutex
+)
+
+// SetLanguageMappings 设置全局语言映射覆盖配置
+func SetLanguageMappings(mappings *LanguageMappings) {
+	detector := NewLanguageDetector(mappings)
+
+	defaultDetectorMu.Lock()
+	defer defaultDetectorMu.Unlock()
+	defaultDetector = detector
+}
+
+// LoadLanguageMappings 从 JSON 文件加载语言映射配置
+func LoadLanguageMappings(file string) (*LanguageMappings, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取语言映射配置失败: %w", err)
+	}
+	var mappings LanguageMappings
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		return nil, fmt.Errorf("解析语言映射配置 %s 失败: %w", file, err)
+	}
+	return &mappings, nil
+}
+
+// GetLanguageDetector 获取全局语言检测器
+func GetLanguageDetector() *LanguageDetector {
+	defaultDetectorMu.RLock()
+	defer defaultDetectorMu.RUnlock()
+	return defaultDetector
+}
+
+// DetectLanguage 根据文件路径检测编程语言
+func DetectLanguage(filePath string) string {
+	lang, _ := GetLanguageDetector().DetectByPath(filePath)
+	return lang
+}
+
+// DetectLanguageWithContent 根据文件路径和文件开头内容检测编程语言
+func DetectLanguageWithContent(filePath, content string) string {
+	return GetLanguageDetector().Detect(filePath, content)
 }
 
 // GetFileExtension 获取文件扩展名（不含点号）
@@ -92,4 +425,3 @@ func GetFileExtension(filePath string) string {
 func GetFileName(filePath string) string {
 	return filepath.Base(filePath)
 }
-
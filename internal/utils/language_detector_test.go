@@ -0,0 +1,68 @@
+package utils
+
+import "testing"
+
+func TestLanguageDetector(t *testing.T) {
+	d := NewLanguageDetector(&LanguageMappings{
+		Extensions:   map[string]string{".tpl": "HTML"},
+		Filenames:    map[string]string{"BUILD": "starlark"},
+		Interpreters: map[string]string{"bun": "typescript"},
+	})
+
+	tests := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{"Dockerfile", "", "dockerfile"},
+		{"deploy/Dockerfile.prod", "", "dockerfile"},
+		{"Makefile", "", "makefile"},
+		{"CMakeLists.txt", "", "cmake"},
+		{"web/src/types/index.d.ts", "", "typescript"},
+		{"dist/release.tar.gz", "", "archive"},
+		{"views/home.blade.php", "", "blade"},
+		{"cmd/main.go", "", "go"},
+		{"include/util.h", "", "c"},
+		{"include/util.h", "#pragma once\nnamespace util {\n", "cpp"},
+		{"include/View.h", "#import <UIKit/UIKit.h>\n@interface View : UIView\n", "objective-c"},
+		{"include/plain.h", "#include <stdio.h>\nint add(int, int);\n", "c"},
+		{"scripts/deploy", "#!/usr/bin/env bash\nset -e\n", "shell"},
+		{"bin/tool", "#!/usr/bin/env -S python3 -u\n", "python"},
+		{"bin/run", "#!/usr/local/bin/bun\n", "typescript"},
+		{"bin/data", "hello", LanguageUnknown},
+		{"templates/page.tpl", "", "html"},
+		{"BUILD", "", "starlark"},
+		{".zshrc", "", "shell"},
+		{"README", "", LanguageUnknown},
+	}
+	for _, tt := range tests {
+		if got := d.Detect(tt.path, tt.content); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if _, certain := d.DetectByPath("include/util.h"); certain {
+		t.Error("DetectByPath(.h) 应返回不确定")
+	}
+}
+
+func TestDiffFileHead(t *testing.T) {
+	added := "@@ -0,0 +1,3 @@\n+#!/bin/sh\n+echo hi\n+exit 0\n"
+	if got := DiffFileHead(added, 2); got != "#!/bin/sh\necho hi" {
+		t.Errorf("DiffFileHead(added) = %q", got)
+	}
+
+	removed := "@@ -1,2 +0,0 @@\n-#!/usr/bin/env ruby\n-puts 1\n"
+	if got := DiffFileHead(removed, 10); got != "#!/usr/bin/env ruby\nputs 1" {
+		t.Errorf("DiffFileHead(removed) = %q", got)
+	}
+
+	modified := "@@ -1,3 +1,3 @@\n #!/usr/bin/env node\n-a()\n+b()\n c()\n@@ -20,1 +20,1 @@\n-x\n+y\n"
+	if got := DiffFileHead(modified, 10); got != "#!/usr/bin/env node\nb()\nc()" {
+		t.Errorf("DiffFileHead(modified) = %q", got)
+	}
+
+	if got := DiffFileHead("@@ -10,2 +10,3 @@\n+x\n", 10); got != "" {
+		t.Errorf("DiffFileHead(不从第 1 行开始) = %q, want empty", got)
+	}
+}
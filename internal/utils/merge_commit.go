@@ -0,0 +1,15 @@
+package utils
+
+import "regexp"
+
+// mergeMessagePattern git、GitLab、GitHub 和 Gitee 默认生成的合并提交说明
+var mergeMessagePattern = regexp.MustCompile(`^Merge (?:branch|branches|remote-tracking branch|tag|commit|pull request) `)
+
+// IsMergeCommit 判断是否为合并提交
+// 父提交已知时以父提交数量为准；未知时（如 webhook 推送）根据提交说明是否为默认的合并格式判断
+func IsMergeCommit(parentIDs []string, message string) bool {
+	if len(parentIDs) > 0 {
+		return len(parentIDs) > 1
+	}
+	return mergeMessagePattern.MatchString(message)
+}
@@ -0,0 +1,25 @@
+package utils
+
+import "testing"
+
+func TestIsMergeCommit(t *testing.T) {
+	tests := []struct {
+		parents []string
+		message string
+		want    bool
+	}{
+		{[]string{"a", "b"}, "feat: 新功能", true},
+		{[]string{"a"}, "Merge branch 'main' into feature", false},
+		{nil, "Merge branch 'feature/login' into 'main'", true},
+		{nil, "Merge remote-tracking branch 'origin/main'", true},
+		{nil, "Merge pull request #12 from alice/fix", true},
+		{nil, "Merge tag 'v1.2.0'", true},
+		{nil, "Merged the two config loaders", false},
+		{nil, "fix: Merge branch handling in importer", false},
+	}
+	for _, tt := range tests {
+		if got := IsMergeCommit(tt.parents, tt.message); got != tt.want {
+			t.Errorf("IsMergeCommit(%v, %q) = %v, want %v", tt.parents, tt.message, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// FileDiff 单个文件的 diff（GitLab API 返回的 unified diff，不含 diff --git 头）
+type FileDiff struct {
+	OldPath string
+	NewPath string
+	Diff    string
+}
+
+// PatchID 计算补丁标识，类似 git patch-id --stable
+// 只取新增和删除行（去除空白），忽略行号、上下文和文件顺序，
+// 因此 cherry-pick、rebase 或推送到 fork 后内容相同的变更得到相同的标识。
+// 没有文件变更，或存在内容未知的文件（二进制、diff 过大被截断）时返回空字符串
+func PatchID(diffs []FileDiff) string {
+	if len(diffs) == 0 {
+		return ""
+	}
+
+	fileHashes := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		if strings.TrimSpace(d.Diff) == "" && d.OldPath == d.NewPath {
+			return ""
+		}
+
+		h := sha1.New()
+		h.Write([]byte("a/" + d.OldPath + "\x00b/" + d.NewPath + "\n"))
+		for _, line := range strings.Split(d.Diff, "\n") {
+			if line == "" || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+				continue
+			}
+			if line[0] != '+' && line[0] != '-' {
+				continue
+			}
+			h.Write([]byte{line[0]})
+			h.Write([]byte(stripSpaces(line[1:])))
+			h.Write([]byte{'\n'})
+		}
+		fileHashes = append(fileHashes, d.NewPath+"\x00"+hex.EncodeToString(h.Sum(nil)))
+	}
+
+	sort.Strings(fileHashes)
+	sum := sha1.Sum([]byte(strings.Join(fileHashes, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// stripSpaces 去除所有空白字符
+func stripSpaces(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// cherryPickPattern git cherry-pick -x 追加的来源说明
+var cherryPickPattern = regexp.MustCompile(`\(cherry picked from commit ([0-9a-fA-F]{7,64})\)`)
+
+// CherryPickSource 从提交说明中解析 cherry-pick 的来源提交，多次 cherry-pick 时取最早的来源
+func CherryPickSource(message string) string {
+	match := cherryPickPattern.FindStringSubmatch(message)
+	if match == nil {
+		return ""
+	}
+	return strings.ToLower(match[1])
+}
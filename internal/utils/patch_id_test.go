@@ -0,0 +1,57 @@
+package utils
+
+import "testing"
+
+func TestPatchID(t *testing.T) {
+	original := []FileDiff{
+		{OldPath: "main.go", NewPath: "main.go", Diff: "@@ -10,3 +10,3 @@ func main() {\n \tx := 1\n-\tfmt.Println(x)\n+\tfmt.Println(x + 1)\n }\n"},
+		{OldPath: "README.md", NewPath: "README.md", Diff: "@@ -1 +1,2 @@\n # demo\n+usage\n"},
+	}
+	// cherry-pick 到另一个分支后行号、上下文、缩进和文件顺序都可能变化
+	picked := []FileDiff{
+		{OldPath: "README.md", NewPath: "README.md", Diff: "@@ -5 +5,2 @@\n # demo project\n+usage\n\\ No newline at end of file\n"},
+		{OldPath: "main.go", NewPath: "main.go", Diff: "@@ -42,3 +42,3 @@\n \ty := 2\n-    fmt.Println(x)\n+    fmt.Println(x+1)\n"},
+	}
+	changed := []FileDiff{
+		{OldPath: "main.go", NewPath: "main.go", Diff: "@@ -10,3 +10,3 @@\n-\tfmt.Println(x)\n+\tfmt.Println(x + 2)\n"},
+		{OldPath: "README.md", NewPath: "README.md", Diff: "@@ -1 +1,2 @@\n+usage\n"},
+	}
+
+	id := PatchID(original)
+	if len(id) != 40 {
+		t.Fatalf("PatchID 长度 = %d, want 40", len(id))
+	}
+	if got := PatchID(picked); got != id {
+		t.Errorf("cherry-pick 后 PatchID = %s, want %s", got, id)
+	}
+	if got := PatchID(changed); got == id {
+		t.Errorf("内容不同的补丁 PatchID 相同: %s", got)
+	}
+
+	if got := PatchID(nil); got != "" {
+		t.Errorf("PatchID(nil) = %q, want empty", got)
+	}
+	binary := append([]FileDiff{{OldPath: "logo.png", NewPath: "logo.png"}}, original...)
+	if got := PatchID(binary); got != "" {
+		t.Errorf("包含内容未知的文件时 PatchID = %q, want empty", got)
+	}
+	if got := PatchID([]FileDiff{{OldPath: "a.go", NewPath: "b.go"}}); got == "" {
+		t.Error("纯重命名应有 PatchID")
+	}
+}
+
+func TestCherryPickSource(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"fix: 修复登录\n\n(cherry picked from commit 4F2a9c1e0b7d3e5f6a8b9c0d1e2f3a4b5c6d7e8f)", "4f2a9c1e0b7d3e5f6a8b9c0d1e2f3a4b5c6d7e8f"},
+		{"fix\n\n(cherry picked from commit abc1234)\n(cherry picked from commit def5678)", "abc1234"},
+		{"fix: cherry picked from upstream", ""},
+	}
+	for _, tt := range tests {
+		if got := CherryPickSource(tt.message); got != tt.want {
+			t.Errorf("CherryPickSource(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 引用类型
+const (
+	ReferenceIssue        = "issue"
+	ReferenceMergeRequest = "merge_request"
+	ReferenceExternal     = "external" // JIRA 等外部系统的工单号，如 ABC-123
+)
+
+// 引用来源
+const (
+	ReferenceSourceMessage = "message"
+	ReferenceSourceBranch  = "branch"
+)
+
+// Reference 提交说明或分支名中的工单/合并请求引用
+type Reference struct {
+	Type    string
+	Project string // 引用中显式指定的项目（group/project 或 owner/repo），为空表示当前项目
+	Number  string // issue / MR 编号，外部工单为空
+	Key     string // 规范化后的键：group/project#123、group/project!45、ABC-123
+	Closing bool   // 是否带有 Closes / Fixes / Resolves 等关闭关键字
+	Source  string
+}
+
+var (
+	// 引用前必须是行首、空白或常见标点，避免匹配 URL 片段；project#1 视为同一命名空间下的其他项目
+	issueRefPattern = regexp.MustCompile(`(?:^|[\s(\[{,;:])((?:[A-Za-z0-9_.-]+/)*[A-Za-z0-9_.-]+)?([#!])(\d+)\b`)
+	closingPattern  = regexp.MustCompile(`(?i)\b(?:close[sd]?|closing|fix(?:e[sd]|ing)?|resolve[sd]?|resolving|implement(?:s|ed|ing)?)\b:?\s*$`)
+	// GitLab 由 issue 创建的分支名以编号开头，如 123-fix-login
+	branchIssuePattern = regexp.MustCompile(`^(\d+)-`)
+
+	issueKeyPatterns   []*regexp.Regexp
+	issueKeyPatternsMu sync.RWMutex
+)
+
+// SetIssueKeyPatterns 设置外部工单号的匹配规则（正则，如 [A-Z][A-Z0-9]+-\d+）
+// 未设置时不识别外部工单号，避免把 UTF-8 之类的文本误判为工单
+func SetIssueKeyPatterns(patterns []string) error {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		re, err := regexp.Compile(`\b(?:` + p + `)\b`)
+		if err != nil {
+			return fmt.Errorf("工单号匹配规则无效 %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	issueKeyPatternsMu.Lock()
+	defer issueKeyPatternsMu.Unlock()
+	issueKeyPatterns = compiled
+	return nil
+}
+
+func getIssueKeyPatterns() []*regexp.Regexp {
+	issueKeyPatternsMu.RLock()
+	defer issueKeyPatternsMu.RUnlock()
+	return issueKeyPatterns
+}
+
+// ExtractReferences 从提交说明和分支名中提取引用
+// projectPath 用于补全未指定项目的 #123 / !45 引用；同一个键只返回一次（关闭关键字取并集）
+func ExtractReferences(message, branch, projectPath string) []*Reference {
+	var refs []*Reference
+	index := make(map[string]*Reference)
+	add := func(ref *Reference) {
+		if existing, ok := index[ref.Key]; ok {
+			existing.Closing = existing.Closing || ref.Closing
+			return
+		}
+		index[ref.Key] = ref
+		refs = append(refs, ref)
+	}
+
+	for _, m := range issueRefPattern.FindAllStringSubmatchIndex(message, -1) {
+		project := ""
+		if m[2] >= 0 {
+			project = message[m[2]:m[3]]
+		}
+		sigil := message[m[4]:m[5]]
+		number := message[m[6]:m[7]]
+
+		ref := &Reference{
+			Type:    ReferenceIssue,
+			Project: project,
+			Number:  number,
+			Source:  ReferenceSourceMessage,
+		}
+		if sigil == "!" {
+			ref.Type = ReferenceMergeRequest
+		}
+		ref.Key = ReferenceKey(ref.Type, qualifyProject(project, projectPath), number)
+
+		// 关闭关键字需紧挨在引用之前（允许中间是逗号分隔的其他引用）
+		prefix := message[:m[4]]
+		if project != "" {
+			prefix = message[:m[2]]
+		}
+		ref.Closing = closingPattern.MatchString(trimReferenceList(prefix))
+		add(ref)
+	}
+
+	for _, re := range getIssueKeyPatterns() {
+		for _, key := range re.FindAllString(message, -1) {
+			add(&Reference{Type: ReferenceExternal, Key: key, Source: ReferenceSourceMessage})
+		}
+		for _, key := range re.FindAllString(branch, -1) {
+			add(&Reference{Type: ReferenceExternal, Key: key, Source: ReferenceSourceBranch})
+		}
+	}
+
+	if m := branchIssuePattern.FindStringSubmatch(path.Base(branch)); m != nil && projectPath != "" {
+		add(&Reference{
+			Type:   ReferenceIssue,
+			Number: m[1],
+			Key:    ReferenceKey(ReferenceIssue, projectPath, m[1]),
+			Source: ReferenceSourceBranch,
+		})
+	}
+
+	return refs
+}
+
+// ReferenceKey 生成引用的规范化键
+func ReferenceKey(refType, project, number string) string {
+	switch refType {
+	case ReferenceIssue:
+		return project + "#" + number
+	case ReferenceMergeRequest:
+		return project + "!" + number
+	default:
+		return number
+	}
+}
+
+// qualifyProject 补全引用中的项目路径
+// 只写了项目名（如 other#1）时视为与当前项目同一命名空间
+func qualifyProject(project, current string) string {
+	switch {
+	case project == "":
+		return current
+	case !strings.Contains(project, "/") && current != "" && strings.Contains(current, "/"):
+		return path.Dir(current) + "/" + project
+	default:
+		return project
+	}
+}
+
+// trimReferenceList 去掉关闭关键字之后、当前引用之前的其他引用，如 "Closes #1, #2" 中的 "#1, "
+func trimReferenceList(prefix string) string {
+	for {
+		trimmed := strings.TrimRight(prefix, " \t,")
+		trimmed = strings.TrimSuffix(trimmed, " and")
+		loc := issueRefPattern.FindAllStringIndex(trimmed, -1)
+		if len(loc) == 0 || loc[len(loc)-1][1] != len(trimmed) {
+			return trimmed
+		}
+		prefix = trimmed[:loc[len(loc)-1][0]]
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestExtractReferences(t *testing.T) {
+	if err := SetIssueKeyPatterns([]string{`[A-Z][A-Z0-9]+-\d+`}); err != nil {
+		t.Fatalf("SetIssueKeyPatterns() error = %v", err)
+	}
+	defer func() { _ = SetIssueKeyPatterns(nil) }()
+
+	message := "fix: login loop (ABC-12)\n\nCloses #12, #13 and relates to other#7.\nSee group/lib!45 and owner/repo#1.\nhttps://example.com/page#99"
+	refs := ExtractReferences(message, "feature/ABC-12-login", "team/app")
+
+	want := map[string]struct {
+		refType string
+		closing bool
+		source  string
+	}{
+		"team/app#12":  {ReferenceIssue, true, ReferenceSourceMessage},
+		"team/app#13":  {ReferenceIssue, true, ReferenceSourceMessage},
+		"team/other#7": {ReferenceIssue, false, ReferenceSourceMessage},
+		"group/lib!45": {ReferenceMergeRequest, false, ReferenceSourceMessage},
+		"owner/repo#1": {ReferenceIssue, false, ReferenceSourceMessage},
+		"ABC-12":       {ReferenceExternal, false, ReferenceSourceMessage},
+	}
+	if len(refs) != len(want) {
+		for _, r := range refs {
+			t.Logf("ref: %+v", r)
+		}
+		t.Fatalf("len(refs) = %d, want %d", len(refs), len(want))
+	}
+	for _, r := range refs {
+		w, ok := want[r.Key]
+		if !ok {
+			t.Errorf("unexpected ref %q", r.Key)
+			continue
+		}
+		if r.Type != w.refType || r.Closing != w.closing || r.Source != w.source {
+			t.Errorf("ref %q = %s/%v/%s, want %s/%v/%s", r.Key, r.Type, r.Closing, r.Source, w.refType, w.closing, w.source)
+		}
+	}
+}
+
+func TestExtractReferencesFromBranch(t *testing.T) {
+	refs := ExtractReferences("update docs", "42-update-readme", "team/app")
+	if len(refs) != 1 || refs[0].Key != "team/app#42" || refs[0].Source != ReferenceSourceBranch {
+		t.Errorf("refs = %+v", refs)
+	}
+
+	// 未配置外部工单规则时不识别 UTF-8 之类的文本
+	if refs := ExtractReferences("Convert files to UTF-8", "main", "team/app"); len(refs) != 0 {
+		t.Errorf("refs = %+v, want none", refs)
+	}
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// revertTitlePattern git revert 生成的标题：Revert "原提交标题"
+var revertTitlePattern = regexp.MustCompile(`^Revert "(.+)"\s*$`)
+
+// revertTrailerPattern git revert 生成的正文：This reverts commit <sha>.
+var revertTrailerPattern = regexp.MustCompile(`(?i)\bThis reverts commit ([0-9a-f]{7,64})\b`)
+
+// RevertInfo 回滚提交的解析结果
+type RevertInfo struct {
+	SHA   string // 被回滚提交的 SHA（可能是短 SHA），提交说明中没有时为空
+	Title string // 被回滚提交的标题，标题不是 Revert "..." 格式时为空
+}
+
+// ParseRevert 解析提交说明中的回滚信息，不是回滚提交时返回 nil
+// 标题为 Revert "..." 或正文包含 This reverts commit <sha> 时视为回滚提交；
+// 回滚的回滚（Revert "Revert "..."")取最外层，即被回滚的是上一次回滚提交
+func ParseRevert(message string) *RevertInfo {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil
+	}
+
+	info := &RevertInfo{}
+	title := message
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		title = message[:i]
+	}
+	if match := revertTitlePattern.FindStringSubmatch(strings.TrimSpace(title)); match != nil {
+		info.Title = match[1]
+	}
+	if match := revertTrailerPattern.FindStringSubmatch(message); match != nil {
+		info.SHA = strings.ToLower(match[1])
+	}
+	if info.SHA == "" && info.Title == "" {
+		return nil
+	}
+	return info
+}
@@ -0,0 +1,30 @@
+package utils
+
+import "testing"
+
+func TestParseRevert(t *testing.T) {
+	tests := []struct {
+		message string
+		want    *RevertInfo
+	}{
+		{
+			"Revert \"feat: 新增导出\"\n\nThis reverts commit 4F2a9c1e0b7d3e5f6a8b9c0d1e2f3a4b5c6d7e8f.",
+			&RevertInfo{SHA: "4f2a9c1e0b7d3e5f6a8b9c0d1e2f3a4b5c6d7e8f", Title: "feat: 新增导出"},
+		},
+		{
+			"Revert \"Revert \"feat: 新增导出\"\"\n\nThis reverts commit abc1234.",
+			&RevertInfo{SHA: "abc1234", Title: "Revert \"feat: 新增导出\""},
+		},
+		{"Revert \"fix: 登录\"", &RevertInfo{Title: "fix: 登录"}},
+		{"fix: 撤销错误的配置\n\nThis reverts commit deadbeef0.", &RevertInfo{SHA: "deadbeef0"}},
+		{"fix: revert the cache change", nil},
+		{"Revert cache change", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got := ParseRevert(tt.message)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("ParseRevert(%q) = %+v, want %+v", tt.message, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	defaultLocation   = time.Local
+	defaultLocationMu sync.RWMutex
+)
+
+// SetDefaultLocation 设置统计使用的默认时区（通常来自 DB_TIMEZONE 配置）
+func SetDefaultLocation(loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	defaultLocationMu.Lock()
+	defer defaultLocationMu.Unlock()
+	defaultLocation = loc
+}
+
+// DefaultLocation 获取统计使用的默认时区
+func DefaultLocation() *time.Location {
+	defaultLocationMu.RLock()
+	defer defaultLocationMu.RUnlock()
+	return defaultLocation
+}
+
+// StartOfDay 获取 t 在 loc 时区下当天的零点
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// BucketBounds 计算 t 在 loc 时区下所属时间桶的起止时间（左闭右开）
+// groupBy 为 day / week / month，周以 weekStart 为第一天
+func BucketBounds(groupBy string, t time.Time, loc *time.Location, weekStart time.Weekday) (start, end time.Time) {
+	day := StartOfDay(t, loc)
+	switch groupBy {
+	case "week":
+		offset := (int(day.Weekday()) - int(weekStart) + 7) % 7
+		start = day.AddDate(0, 0, -offset)
+		end = start.AddDate(0, 0, 7)
+	case "month":
+		start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, 0)
+	default:
+		start = day
+		end = day.AddDate(0, 0, 1)
+	}
+	return start, end
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketBoundsWeekStart(t *testing.T) {
+	loc := time.UTC
+	// 2024-01-03 是周三
+	ts := time.Date(2024, 1, 3, 15, 0, 0, 0, loc)
+
+	cases := []struct {
+		weekStart time.Weekday
+		start     time.Time
+	}{
+		{time.Monday, time.Date(2024, 1, 1, 0, 0, 0, 0, loc)},
+		{time.Sunday, time.Date(2023, 12, 31, 0, 0, 0, 0, loc)},
+		{time.Wednesday, time.Date(2024, 1, 3, 0, 0, 0, 0, loc)},
+		{time.Thursday, time.Date(2023, 12, 28, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tc := range cases {
+		start, end := BucketBounds("week", ts, loc, tc.weekStart)
+		if !start.Equal(tc.start) || !end.Equal(tc.start.AddDate(0, 0, 7)) {
+			t.Errorf("周起始 %s: 期望起点 %s，得到 [%s, %s)", tc.weekStart, tc.start, start, end)
+		}
+	}
+}
+
+func TestBucketBoundsTimezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("缺少时区数据: %v", err)
+	}
+
+	// 2024-01-31 23:30 UTC 在柏林已是 2 月 1 日
+	ts := time.Date(2024, 1, 31, 23, 30, 0, 0, time.UTC)
+	start, _ := BucketBounds("month", ts, berlin, time.Monday)
+	if want := time.Date(2024, 2, 1, 0, 0, 0, 0, berlin); !start.Equal(want) {
+		t.Errorf("期望 %s，得到 %s", want, start)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
 )
 
 // GiteePlatform Gitee 平台解析器
@@ -58,6 +59,28 @@ func (p *GiteePlatform) ParsePushEvent(payload map[string]interface{}) ([]*model
 	return commitRecords, nil
 }
 
+// ParsePush 解析 Gitee 推送事件本身（推送者、引用和前后 SHA），没有提交的推送也会返回
+func (p *GiteePlatform) ParsePush(payload map[string]interface{}) *model.PushRecord {
+	pushInfo := p.parsePushInfo(payload)
+	return &model.PushRecord{
+		Platform:          p.GetPlatformName(),
+		Instance:          utils.InstanceHost(pushInfo.ProjectWebURL, pushInfo.RepositoryHomepage),
+		ProjectID:         pushInfo.ProjectID,
+		ProjectPath:       pushInfo.ProjectPath,
+		Ref:               pushInfo.Branch,
+		RefProtected:      pushInfo.RefProtected,
+		BeforeSHA:         pushInfo.BeforeSHA,
+		AfterSHA:          pushInfo.AfterSHA,
+		CheckoutSHA:       pushInfo.CheckoutSHA,
+		Message:           pushInfo.PushMessage,
+		TotalCommitsCount: pushInfo.TotalCommitsCount,
+		PusherID:          pushInfo.PushUserID,
+		PusherName:        pushInfo.PushUserName,
+		PusherUsername:    pushInfo.PushUserUsername,
+		PusherEmail:       pushInfo.PushUserEmail,
+	}
+}
+
 // PushInfo 推送级别信息（所有提交共享）
 type GiteePushInfo struct {
 	ProjectName              string
@@ -297,6 +320,8 @@ func (p *GiteePlatform) parseCommit(
 		Branch:                   pushInfo.Branch,
 		RefProtected:             pushInfo.RefProtected,
 		ProjectID:                pushInfo.ProjectID,
+		Platform:                 p.GetPlatformName(),
+		Instance:                 utils.InstanceHost(pushInfo.ProjectWebURL, pushInfo.RepositoryHomepage, url),
 		URL:                      url,
 		ProjectName:              pushInfo.ProjectName,
 		ProjectPath:              pushInfo.ProjectPath,
@@ -314,15 +339,6 @@ func (p *GiteePlatform) parseCommit(
 		RepositoryGitSSHURL:      pushInfo.RepositoryGitSSHURL,
 		RepositoryGitHTTPURL:     pushInfo.RepositoryGitHTTPURL,
 		RepositoryVisibilityLevel: pushInfo.RepositoryVisibilityLevel,
-		BeforeSHA:                pushInfo.BeforeSHA,
-		AfterSHA:                 pushInfo.AfterSHA,
-		CheckoutSHA:              pushInfo.CheckoutSHA,
-		PushMessage:              pushInfo.PushMessage,
-		TotalCommitsCount:        pushInfo.TotalCommitsCount,
-		PushUserID:               pushInfo.PushUserID,
-		PushUserName:             pushInfo.PushUserName,
-		PushUserUsername:         pushInfo.PushUserUsername,
-		PushUserEmail:            pushInfo.PushUserEmail,
 		AddedFiles:               addedFiles,
 		ModifiedFiles:            modifiedFiles,
 		RemovedFiles:             removedFiles,
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"gitlab-webhook-server/internal/model"
+	"gitlab-webhook-server/internal/utils"
 )
 
 // GitHubPlatform GitHub 平台解析器
@@ -58,6 +59,28 @@ func (p *GitHubPlatform) ParsePushEvent(payload map[string]interface{}) ([]*mode
 	return commitRecords, nil
 }
 
+// ParsePush 解析 GitHub 推送事件本身（推送者、引用和前后 SHA），没有提交的推送也会返回
+func (p *GitHubPlatform) ParsePush(payload map[string]interface{}) *model.PushRecord {
+	pushInfo := p.parsePushInfo(payload)
+	return &model.PushRecord{
+		Platform:          p.GetPlatformName(),
+		Instance:          utils.InstanceHost(pushInfo.ProjectWebURL, pushInfo.RepositoryHomepage),
+		ProjectID:         pushInfo.ProjectID,
+		ProjectPath:       pushInfo.ProjectPath,
+		Ref:               pushInfo.Branch,
+		RefProtected:      pushInfo.RefProtected,
+		BeforeSHA:         pushInfo.BeforeSHA,
+		AfterSHA:          pushInfo.AfterSHA,
+		CheckoutSHA:       pushInfo.CheckoutSHA,
+		Message:           pushInfo.PushMessage,
+		TotalCommitsCount: pushInfo.TotalCommitsCount,
+		PusherID:          pushInfo.PushUserID,
+		PusherName:        pushInfo.PushUserName,
+		PusherUsername:    pushInfo.PushUserUsername,
+		PusherEmail:       pushInfo.PushUserEmail,
+	}
+}
+
 // PushInfo 推送级别信息（所有提交共享）
 type GitHubPushInfo struct {
 	ProjectName              string
@@ -292,6 +315,8 @@ func (p *GitHubPlatform) parseCommit(
 		Branch:                   pushInfo.Branch,
 		RefProtected:             pushInfo.RefProtected,
 		ProjectID:                pushInfo.ProjectID,
+		Platform:                 p.GetPlatformName(),
+		Instance:                 utils.InstanceHost(pushInfo.ProjectWebURL, pushInfo.RepositoryHomepage, url),
 		URL:                      url,
 		ProjectName:              pushInfo.ProjectName,
 		ProjectPath:              pushInfo.ProjectPath,
@@ -309,15 +334,6 @@ func (p *GitHubPlatform) parseCommit(
 		RepositoryGitSSHURL:      pushInfo.RepositoryGitSSHURL,
 		RepositoryGitHTTPURL:     pushInfo.RepositoryGitHTTPURL,
 		RepositoryVisibilityLevel: pushInfo.RepositoryVisibilityLevel,
-		BeforeSHA:                pushInfo.BeforeSHA,
-		AfterSHA:                 pushInfo.AfterSHA,
-		CheckoutSHA:              pushInfo.CheckoutSHA,
-		PushMessage:              pushInfo.PushMessage,
-		TotalCommitsCount:        pushInfo.TotalCommitsCount,
-		PushUserID:               pushInfo.PushUserID,
-		PushUserName:             pushInfo.PushUserName,
-		PushUserUsername:         pushInfo.PushUserUsername,
-		PushUserEmail:            pushInfo.PushUserEmail,
 		AddedFiles:               addedFiles,
 		ModifiedFiles:            modifiedFiles,
 		RemovedFiles:             removedFiles,
@@ -15,6 +15,10 @@ type Platform interface {
 	// 返回提交记录列表和错误
 	ParsePushEvent(payload map[string]interface{}) ([]*model.CommitRecord, error)
 
+	// ParsePush 解析推送事件本身（推送者、引用和前后 SHA）
+	// 与提交无关，不包含新提交的推送（如删除分支、强制回退）同样返回记录
+	ParsePush(payload map[string]interface{}) *model.PushRecord
+
 	// ParseTagPushEvent 解析 Tag Push 事件
 	// Tag Push 事件与 Push 事件结构类似，但 ref 是 "refs/tags/" 开头
 	// 返回提交记录列表和错误
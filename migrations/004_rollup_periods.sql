@@ -0,0 +1,47 @@
+-- 数据库迁移文件：聚合表支持日/周/月多周期
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 004_rollup_periods_mysql.sql
+-- 迁移完成后执行 go run ./cmd/rollup -all 回填历史数据
+
+-- 1. member_contributions 增加周期类型和文件数
+ALTER TABLE member_contributions
+ADD COLUMN IF NOT EXISTS period_type VARCHAR(10) NOT NULL DEFAULT 'day',
+ADD COLUMN IF NOT EXISTS file_count INTEGER DEFAULT 0;
+
+-- 2. member_language_stats 增加周期类型和提交数
+ALTER TABLE member_language_stats
+ADD COLUMN IF NOT EXISTS period_type VARCHAR(10) NOT NULL DEFAULT 'day',
+ADD COLUMN IF NOT EXISTS commit_count INTEGER DEFAULT 0;
+
+-- 3. 唯一约束加入周期类型（旧约束名由 PostgreSQL 自动生成，按类型查找后删除）
+DO $$
+DECLARE
+    r RECORD;
+BEGIN
+    FOR r IN
+        SELECT conrelid::regclass AS tbl, conname
+        FROM pg_constraint
+        WHERE contype = 'u'
+          AND conrelid IN ('member_contributions'::regclass, 'member_language_stats'::regclass)
+    LOOP
+        EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', r.tbl, r.conname);
+    END LOOP;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_member_contributions_unique
+ON member_contributions(member_email, period_type, start_date, COALESCE(project_id, 0));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_member_language_stats_unique
+ON member_language_stats(member_email, language, period_type, period_start, COALESCE(project_id, 0));
+
+-- 4. 周期索引
+DROP INDEX IF EXISTS idx_member_contributions_period;
+CREATE INDEX IF NOT EXISTS idx_member_contributions_period ON member_contributions(period_type, start_date, end_date);
+DROP INDEX IF EXISTS idx_member_language_stats_period;
+CREATE INDEX IF NOT EXISTS idx_member_language_stats_period ON member_language_stats(period_type, period_start, period_end);
+
+-- 添加注释
+COMMENT ON COLUMN member_contributions.period_type IS '聚合周期：day/week/month';
+COMMENT ON COLUMN member_contributions.end_date IS '周期结束日期（不包含）';
+COMMENT ON COLUMN member_language_stats.period_type IS '聚合周期：day/week/month';
+COMMENT ON COLUMN member_language_stats.period_end IS '周期结束日期（不包含）';
@@ -0,0 +1,28 @@
+-- MySQL 数据库迁移文件：聚合表支持日/周/月多周期
+-- 创建时间: 2026-10-19
+-- 迁移完成后执行 go run ./cmd/rollup -all 回填历史数据
+
+-- 1. member_contributions 增加周期类型和文件数
+ALTER TABLE member_contributions
+ADD COLUMN period_type VARCHAR(10) NOT NULL DEFAULT 'day' COMMENT '聚合周期：day/week/month',
+ADD COLUMN file_count INT DEFAULT 0;
+
+-- 2. member_language_stats 增加周期类型和提交数
+ALTER TABLE member_language_stats
+ADD COLUMN period_type VARCHAR(10) NOT NULL DEFAULT 'day' COMMENT '聚合周期：day/week/month',
+ADD COLUMN commit_count INT DEFAULT 0;
+
+-- 3. 唯一约束加入周期类型
+ALTER TABLE member_contributions DROP INDEX uk_member_contributions;
+CREATE UNIQUE INDEX idx_member_contributions_unique
+ON member_contributions(member_email, period_type, start_date, project_id);
+
+ALTER TABLE member_language_stats DROP INDEX uk_member_language_stats;
+CREATE UNIQUE INDEX idx_member_language_stats_unique
+ON member_language_stats(member_email, language, period_type, period_start, project_id);
+
+-- 4. 周期索引
+DROP INDEX idx_member_contributions_period ON member_contributions;
+CREATE INDEX idx_member_contributions_period ON member_contributions(period_type, start_date, end_date);
+DROP INDEX idx_member_language_stats_period ON member_language_stats;
+CREATE INDEX idx_member_language_stats_period ON member_language_stats(period_type, period_start, period_end);
@@ -0,0 +1,7 @@
+-- 数据库迁移文件：项目级统计索引
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 005_project_stats_indexes_mysql.sql
+
+-- 项目统计按 project_path 过滤，命名空间统计按 project_path 前缀过滤
+CREATE INDEX IF NOT EXISTS idx_commits_project_path ON commits(project_path varchar_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_commits_project_timestamp ON commits(project_id, timestamp);
@@ -0,0 +1,6 @@
+-- MySQL 数据库迁移文件：项目级统计索引
+-- 创建时间: 2026-10-19
+
+-- 项目统计按 project_path 过滤，命名空间统计按 project_path 前缀过滤
+CREATE INDEX idx_commits_project_path ON commits(project_path);
+CREATE INDEX idx_commits_project_timestamp ON commits(project_id, timestamp);
@@ -0,0 +1,29 @@
+-- 数据库迁移文件：创建团队表
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 006_create_teams_mysql.sql
+
+-- 1. 创建 teams 表
+CREATE TABLE IF NOT EXISTS teams (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 2. 创建 team_members 表
+CREATE TABLE IF NOT EXISTS team_members (
+    id BIGSERIAL PRIMARY KEY,
+    team_id BIGINT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    member_email VARCHAR(255) NOT NULL,
+    member_name VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(team_id, member_email)
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_team_members_member_email ON team_members(member_email);
+
+-- 添加注释
+COMMENT ON TABLE teams IS '团队表，用于按团队过滤统计';
+COMMENT ON TABLE team_members IS '团队成员表，成员以提交邮箱标识';
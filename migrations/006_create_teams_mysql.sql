@@ -0,0 +1,24 @@
+-- MySQL 数据库迁移文件：创建团队表
+-- 创建时间: 2026-10-19
+
+-- 1. 创建 teams 表
+CREATE TABLE IF NOT EXISTS teams (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_teams_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='团队表，用于按团队过滤统计';
+
+-- 2. 创建 team_members 表
+CREATE TABLE IF NOT EXISTS team_members (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    team_id BIGINT UNSIGNED NOT NULL,
+    member_email VARCHAR(255) NOT NULL,
+    member_name VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY idx_team_members_team_email (team_id, member_email),
+    INDEX idx_team_members_member_email (member_email),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='团队成员表，成员以提交邮箱标识';
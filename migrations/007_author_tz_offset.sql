@@ -0,0 +1,10 @@
+-- 数据库迁移文件：记录作者提交时的时区偏移
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 007_author_tz_offset_mysql.sql
+-- 本迁移之前入库的提交 timestamp 列保存的是作者本地的墙上时间而非 UTC，
+-- 重新导入不会更新已存在的提交；服务启动时 database.Migrate 会按 DB_TIMEZONE 将这些提交（author_tz_offset 为 NULL）
+-- 的时间换算为 UTC（只执行一次，记录在 data_migrations 表），换算后需执行 go run ./cmd/rollup -all 重建聚合表
+
+-- 提交时间统一以 UTC 存储，作者本地时区的偏移（分钟）单独保存
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS author_tz_offset INTEGER;
+COMMENT ON COLUMN commits.author_tz_offset IS '作者提交时的 UTC 偏移（分钟）';
@@ -0,0 +1,5 @@
+-- MySQL 数据库迁移文件：记录作者提交时的时区偏移
+-- 创建时间: 2026-10-19
+
+-- 提交时间统一以 UTC 存储，作者本地时区的偏移（分钟）单独保存
+ALTER TABLE commits ADD COLUMN author_tz_offset INT NULL COMMENT '作者提交时的 UTC 偏移（分钟）';
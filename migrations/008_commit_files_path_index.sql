@@ -0,0 +1,6 @@
+-- 数据库迁移文件：文件热点分析索引
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 008_commit_files_path_index_mysql.sql
+
+-- 热点与归属分析按目录前缀过滤 commit_files.file_path
+CREATE INDEX IF NOT EXISTS idx_commit_files_file_path ON commit_files(file_path text_pattern_ops);
@@ -0,0 +1,5 @@
+-- MySQL 数据库迁移文件：文件热点分析索引
+-- 创建时间: 2026-10-19
+
+-- 热点与归属分析按目录前缀过滤 commit_files.file_path（TEXT 列需指定前缀长度）
+CREATE INDEX idx_commit_files_file_path ON commit_files(file_path(255));
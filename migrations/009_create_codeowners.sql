@@ -0,0 +1,23 @@
+-- 数据库迁移文件：创建 CODEOWNERS 快照表
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 009_create_codeowners_mysql.sql
+
+CREATE TABLE IF NOT EXISTS codeowners_files (
+    id BIGSERIAL PRIMARY KEY,
+    project_id INTEGER,
+    project_path VARCHAR(500) NOT NULL UNIQUE,
+    source VARCHAR(20) NOT NULL,
+    file_path VARCHAR(255) NOT NULL,
+    ref VARCHAR(255),
+    content TEXT NOT NULL,
+    fetched_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_codeowners_files_project_id ON codeowners_files(project_id);
+
+-- 添加注释
+COMMENT ON TABLE codeowners_files IS '项目 CODEOWNERS 文件快照，每个项目保留最新一份';
+COMMENT ON COLUMN codeowners_files.source IS '来源: api（平台 API）/ local（本地镜像）';
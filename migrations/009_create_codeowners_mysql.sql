@@ -0,0 +1,17 @@
+-- MySQL 数据库迁移文件：创建 CODEOWNERS 快照表
+-- 创建时间: 2026-10-19
+
+CREATE TABLE IF NOT EXISTS codeowners_files (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    project_id INT,
+    project_path VARCHAR(500) NOT NULL,
+    source VARCHAR(20) NOT NULL COMMENT '来源: api（平台 API）/ local（本地镜像）',
+    file_path VARCHAR(255) NOT NULL,
+    ref VARCHAR(255),
+    content TEXT NOT NULL,
+    fetched_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY idx_codeowners_files_project_path (project_path),
+    INDEX idx_codeowners_files_project_id (project_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='项目 CODEOWNERS 文件快照，每个项目保留最新一份';
@@ -0,0 +1,15 @@
+-- 数据库迁移文件：Conventional Commits 解析字段
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 010_conventional_commits_mysql.sql
+-- 历史数据需执行 go run ./cmd/reclassify -messages 回填
+
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS commit_type VARCHAR(50);
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS commit_scope VARCHAR(100);
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS is_breaking BOOLEAN DEFAULT FALSE;
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS trailers TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_commits_commit_type ON commits(commit_type);
+
+COMMENT ON COLUMN commits.commit_type IS 'Conventional Commits 类型，为空表示不符合规范';
+COMMENT ON COLUMN commits.is_breaking IS '是否为破坏性变更（标题带 ! 或包含 BREAKING CHANGE 脚注）';
+COMMENT ON COLUMN commits.trailers IS '提交说明末尾的 trailer（JSON）';
@@ -0,0 +1,11 @@
+-- MySQL 数据库迁移文件：Conventional Commits 解析字段
+-- 创建时间: 2026-10-19
+-- 历史数据需执行 go run ./cmd/reclassify -messages 回填
+
+ALTER TABLE commits
+    ADD COLUMN commit_type VARCHAR(50) NULL COMMENT 'Conventional Commits 类型，为空表示不符合规范',
+    ADD COLUMN commit_scope VARCHAR(100) NULL,
+    ADD COLUMN is_breaking BOOLEAN DEFAULT FALSE COMMENT '是否为破坏性变更（标题带 ! 或包含 BREAKING CHANGE 脚注）',
+    ADD COLUMN trailers TEXT NULL COMMENT '提交说明末尾的 trailer（JSON）';
+
+CREATE INDEX idx_commits_commit_type ON commits(commit_type);
@@ -0,0 +1,26 @@
+-- 数据库迁移文件：创建提交引用表
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 011_create_commit_references_mysql.sql
+-- 历史数据需执行 go run ./cmd/reclassify -messages 回填
+
+CREATE TABLE IF NOT EXISTS commit_references (
+    id BIGSERIAL PRIMARY KEY,
+    commit_id BIGINT NOT NULL REFERENCES commits(id) ON DELETE CASCADE,
+    ref_type VARCHAR(20) NOT NULL,
+    ref_key VARCHAR(500) NOT NULL,
+    ref_project VARCHAR(500),
+    ref_number VARCHAR(50),
+    is_closing BOOLEAN DEFAULT FALSE,
+    source VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_commit_references_commit_id ON commit_references(commit_id);
+CREATE INDEX IF NOT EXISTS idx_commit_references_ref_key ON commit_references(ref_key);
+
+-- 添加注释
+COMMENT ON TABLE commit_references IS '提交引用的 issue / 合并请求 / 外部工单';
+COMMENT ON COLUMN commit_references.ref_type IS '引用类型: issue / merge_request / external';
+COMMENT ON COLUMN commit_references.ref_key IS '规范化后的键，如 group/project#123、group/project!45、ABC-123';
+COMMENT ON COLUMN commit_references.source IS '来源: message（提交说明）/ branch（分支名）';
@@ -0,0 +1,18 @@
+-- MySQL 数据库迁移文件：创建提交引用表
+-- 创建时间: 2026-10-19
+-- 历史数据需执行 go run ./cmd/reclassify -messages 回填
+
+CREATE TABLE IF NOT EXISTS commit_references (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    commit_id BIGINT UNSIGNED NOT NULL,
+    ref_type VARCHAR(20) NOT NULL COMMENT '引用类型: issue / merge_request / external',
+    ref_key VARCHAR(500) NOT NULL COMMENT '规范化后的键，如 group/project#123、group/project!45、ABC-123',
+    ref_project VARCHAR(500),
+    ref_number VARCHAR(50),
+    is_closing BOOLEAN DEFAULT FALSE,
+    source VARCHAR(20) NOT NULL COMMENT '来源: message（提交说明）/ branch（分支名）',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_commit_references_commit_id (commit_id),
+    INDEX idx_commit_references_ref_key (ref_key(255)),
+    FOREIGN KEY (commit_id) REFERENCES commits(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='提交引用的 issue / 合并请求 / 外部工单';
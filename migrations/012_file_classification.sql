@@ -0,0 +1,32 @@
+-- 数据库迁移文件：生成代码 / 第三方代码 / 锁文件分类
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 012_file_classification_mysql.sql
+-- 历史数据需执行 go run ./cmd/reclassify -files 重新分类并重建聚合表
+
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS excluded_added_lines INTEGER DEFAULT 0;
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS excluded_removed_lines INTEGER DEFAULT 0;
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS excluded_changed_files INTEGER DEFAULT 0;
+
+ALTER TABLE commit_files ADD COLUMN IF NOT EXISTS classification VARCHAR(20) DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_commit_files_classification ON commit_files(classification);
+
+CREATE TABLE IF NOT EXISTS project_file_rules (
+    id BIGSERIAL PRIMARY KEY,
+    project_path VARCHAR(500) NOT NULL,
+    pattern VARCHAR(500) NOT NULL,
+    class VARCHAR(20),
+    position INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_project_file_rules_project_path ON project_file_rules(project_path);
+
+-- 添加注释
+COMMENT ON COLUMN commits.excluded_added_lines IS '生成代码、第三方代码和锁文件的新增行数，不计入 total_added_lines';
+COMMENT ON COLUMN commits.excluded_removed_lines IS '生成代码、第三方代码和锁文件的删除行数，不计入 total_removed_lines';
+COMMENT ON COLUMN commits.excluded_changed_files IS '被排除的变更文件数，不计入 total_changed_files';
+COMMENT ON COLUMN commit_files.classification IS '文件分类: generated / vendored / lockfile，空字符串表示普通文件';
+COMMENT ON TABLE project_file_rules IS '项目级文件分类规则，优先于内置规则';
+COMMENT ON COLUMN project_file_rules.class IS 'generated / vendored / lockfile，空字符串表示强制视为普通文件';
+COMMENT ON COLUMN project_file_rules.position IS '规则顺序，靠后的优先';
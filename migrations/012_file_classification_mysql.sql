@@ -0,0 +1,23 @@
+-- MySQL 数据库迁移文件：生成代码 / 第三方代码 / 锁文件分类
+-- 创建时间: 2026-10-19
+-- 历史数据需执行 go run ./cmd/reclassify -files 重新分类并重建聚合表
+
+ALTER TABLE commits
+    ADD COLUMN excluded_added_lines INT DEFAULT 0 COMMENT '生成代码、第三方代码和锁文件的新增行数，不计入 total_added_lines',
+    ADD COLUMN excluded_removed_lines INT DEFAULT 0 COMMENT '生成代码、第三方代码和锁文件的删除行数，不计入 total_removed_lines',
+    ADD COLUMN excluded_changed_files INT DEFAULT 0 COMMENT '被排除的变更文件数，不计入 total_changed_files';
+
+ALTER TABLE commit_files
+    ADD COLUMN classification VARCHAR(20) DEFAULT '' COMMENT '文件分类: generated / vendored / lockfile，空字符串表示普通文件';
+
+CREATE INDEX idx_commit_files_classification ON commit_files(classification);
+
+CREATE TABLE IF NOT EXISTS project_file_rules (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    project_path VARCHAR(500) NOT NULL,
+    pattern VARCHAR(500) NOT NULL,
+    class VARCHAR(20) COMMENT 'generated / vendored / lockfile，空字符串表示强制视为普通文件',
+    position INT DEFAULT 0 COMMENT '规则顺序，靠后的优先',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_project_file_rules_project_path (project_path(255))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='项目级文件分类规则，优先于内置规则';
@@ -0,0 +1,9 @@
+-- 数据库迁移文件：变更文件类别（测试 / 生产 / 文档 / 配置 / 构建）
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 013_file_categories_mysql.sql
+-- 历史数据需执行 go run ./cmd/reclassify -categories 回填
+
+ALTER TABLE commit_files ADD COLUMN IF NOT EXISTS category VARCHAR(20) DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_commit_files_category ON commit_files(category);
+
+COMMENT ON COLUMN commit_files.category IS '文件类别: production / test / docs / config / build，空字符串表示尚未判断';
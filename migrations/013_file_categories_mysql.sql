@@ -0,0 +1,8 @@
+-- MySQL 数据库迁移文件：变更文件类别（测试 / 生产 / 文档 / 配置 / 构建）
+-- 创建时间: 2026-10-19
+-- 历史数据需执行 go run ./cmd/reclassify -categories 回填
+
+ALTER TABLE commit_files
+    ADD COLUMN category VARCHAR(20) DEFAULT '' COMMENT '文件类别: production / test / docs / config / build，空字符串表示尚未判断';
+
+CREATE INDEX idx_commit_files_category ON commit_files(category);
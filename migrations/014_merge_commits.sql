@@ -0,0 +1,12 @@
+-- 数据库迁移文件：合并提交标记
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 014_merge_commits_mysql.sql
+-- 历史数据需执行 go run ./cmd/reclassify -messages 按提交说明回填并重建聚合表
+
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS parent_shas TEXT;
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS is_merge BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_commits_is_merge ON commits(is_merge);
+
+COMMENT ON COLUMN commits.parent_shas IS '父提交 SHA（空格分隔），webhook 推送的提交为空';
+COMMENT ON COLUMN commits.is_merge IS '是否为合并提交，合并提交默认不计入行数统计';
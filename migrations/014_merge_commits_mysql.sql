@@ -0,0 +1,9 @@
+-- MySQL 数据库迁移文件：合并提交标记
+-- 创建时间: 2026-10-19
+-- 历史数据需执行 go run ./cmd/reclassify -messages 按提交说明回填并重建聚合表
+
+ALTER TABLE commits
+    ADD COLUMN parent_shas TEXT NULL COMMENT '父提交 SHA（空格分隔），webhook 推送的提交为空',
+    ADD COLUMN is_merge BOOLEAN DEFAULT FALSE COMMENT '是否为合并提交，合并提交默认不计入行数统计';
+
+CREATE INDEX idx_commits_is_merge ON commits(is_merge);
@@ -0,0 +1,22 @@
+-- 数据库迁移文件：等价提交分组（cherry-pick、rebase、fork 推送的同一逻辑变更只统计一次）
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 015_commit_equivalence_mysql.sql
+-- 历史数据需执行 go run ./cmd/reclassify -messages -duplicates 回填 cherry-pick 来源、重新分组并重建聚合表
+-- 补丁标识只在导入时根据 diff 计算，历史提交需重新导入才能按补丁内容识别
+
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS patch_id VARCHAR(40);
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS cherry_picked_from VARCHAR(64);
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS change_key VARCHAR(128);
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS duplicate_of BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_commits_patch_id ON commits(patch_id);
+CREATE INDEX IF NOT EXISTS idx_commits_cherry_picked_from ON commits(cherry_picked_from);
+CREATE INDEX IF NOT EXISTS idx_commits_change_key ON commits(change_key);
+CREATE INDEX IF NOT EXISTS idx_commits_duplicate_of ON commits(duplicate_of);
+
+UPDATE commits SET change_key = 'commit:' || commit_id WHERE change_key IS NULL OR change_key = '';
+
+COMMENT ON COLUMN commits.patch_id IS '补丁标识（类似 git patch-id，忽略行号和空白），导入时根据 diff 计算';
+COMMENT ON COLUMN commits.cherry_picked_from IS 'cherry-pick 来源提交 SHA（解析自 cherry-pick -x 说明）';
+COMMENT ON COLUMN commits.change_key IS '逻辑变更键，等价提交共享同一个键';
+COMMENT ON COLUMN commits.duplicate_of IS '同组最早入库的提交 ID，非空表示重复提交，默认不计入统计';
@@ -0,0 +1,17 @@
+-- MySQL 数据库迁移文件：等价提交分组（cherry-pick、rebase、fork 推送的同一逻辑变更只统计一次）
+-- 创建时间: 2026-10-19
+-- 历史数据需执行 go run ./cmd/reclassify -messages -duplicates 回填 cherry-pick 来源、重新分组并重建聚合表
+-- 补丁标识只在导入时根据 diff 计算，历史提交需重新导入才能按补丁内容识别
+
+ALTER TABLE commits
+    ADD COLUMN patch_id VARCHAR(40) NULL COMMENT '补丁标识（类似 git patch-id，忽略行号和空白），导入时根据 diff 计算',
+    ADD COLUMN cherry_picked_from VARCHAR(64) NULL COMMENT 'cherry-pick 来源提交 SHA（解析自 cherry-pick -x 说明）',
+    ADD COLUMN change_key VARCHAR(128) NULL COMMENT '逻辑变更键，等价提交共享同一个键',
+    ADD COLUMN duplicate_of BIGINT NULL COMMENT '同组最早入库的提交 ID，非空表示重复提交，默认不计入统计';
+
+CREATE INDEX idx_commits_patch_id ON commits(patch_id);
+CREATE INDEX idx_commits_cherry_picked_from ON commits(cherry_picked_from);
+CREATE INDEX idx_commits_change_key ON commits(change_key);
+CREATE INDEX idx_commits_duplicate_of ON commits(duplicate_of);
+
+UPDATE commits SET change_key = CONCAT('commit:', commit_id) WHERE change_key IS NULL OR change_key = '';
@@ -0,0 +1,26 @@
+-- 数据库迁移文件：提交与分支的多对多关系
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 016_commit_branches_mysql.sql
+-- 历史提交按首次推送的分支回填，首次出现时间取入库时间
+
+CREATE TABLE IF NOT EXISTS commit_branches (
+    id BIGSERIAL PRIMARY KEY,
+    commit_id BIGINT NOT NULL REFERENCES commits(id) ON DELETE CASCADE,
+    branch VARCHAR(255) NOT NULL,
+    is_default BOOLEAN DEFAULT FALSE,
+    first_seen_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_commit_branches_commit_branch ON commit_branches(commit_id, branch);
+CREATE INDEX IF NOT EXISTS idx_commit_branches_branch ON commit_branches(branch);
+CREATE INDEX IF NOT EXISTS idx_commit_branches_is_default ON commit_branches(is_default);
+
+INSERT INTO commit_branches (commit_id, branch, is_default, first_seen_at)
+SELECT id, branch, COALESCE(branch = project_default_branch, FALSE), created_at
+FROM commits
+WHERE branch IS NOT NULL AND branch <> '' AND branch NOT LIKE 'refs/%'
+ON CONFLICT DO NOTHING;
+
+COMMENT ON TABLE commit_branches IS '提交出现过的分支，同一提交合并后会出现在多个分支';
+COMMENT ON COLUMN commit_branches.is_default IS '推送时是否为项目默认分支';
+COMMENT ON COLUMN commit_branches.first_seen_at IS '首次在该分支上出现的时间';
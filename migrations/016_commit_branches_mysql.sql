@@ -0,0 +1,20 @@
+-- MySQL 数据库迁移文件：提交与分支的多对多关系
+-- 创建时间: 2026-10-19
+-- 历史提交按首次推送的分支回填，首次出现时间取入库时间
+
+CREATE TABLE IF NOT EXISTS commit_branches (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    commit_id BIGINT UNSIGNED NOT NULL,
+    branch VARCHAR(255) NOT NULL,
+    is_default BOOLEAN DEFAULT FALSE COMMENT '推送时是否为项目默认分支',
+    first_seen_at TIMESTAMP NOT NULL COMMENT '首次在该分支上出现的时间',
+    UNIQUE INDEX idx_commit_branches_commit_branch (commit_id, branch),
+    INDEX idx_commit_branches_branch (branch),
+    INDEX idx_commit_branches_is_default (is_default),
+    FOREIGN KEY (commit_id) REFERENCES commits(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='提交出现过的分支，同一提交合并后会出现在多个分支';
+
+INSERT IGNORE INTO commit_branches (commit_id, branch, is_default, first_seen_at)
+SELECT id, branch, COALESCE(branch = project_default_branch, FALSE), created_at
+FROM commits
+WHERE branch IS NOT NULL AND branch <> '' AND branch NOT LIKE 'refs/%';
@@ -0,0 +1,59 @@
+-- 数据库迁移文件：提交与聚合数据加入平台和实例标识
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 017_platform_identity_mysql.sql
+-- 不同平台（或同一平台的不同实例）的项目 ID 可能相同，唯一键改为 (commit_id, project_id, platform, instance)
+-- 迁移后需执行 go run ./cmd/rollup -all 重建聚合表
+
+-- 1. 提交表
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS platform VARCHAR(20) NOT NULL DEFAULT 'gitlab';
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS instance VARCHAR(255) NOT NULL DEFAULT '';
+
+-- 按项目地址回填实例，github.com / gitee.com 上的项目回填对应平台
+UPDATE commits
+SET instance = LOWER(COALESCE(substring(COALESCE(NULLIF(project_web_url, ''), url) from '^[A-Za-z+]+://([^/]+)'), ''))
+WHERE instance = '';
+UPDATE commits SET platform = 'github' WHERE instance = 'github.com';
+UPDATE commits SET platform = 'gitee' WHERE instance = 'gitee.com';
+
+-- 旧版导入未填写 project_id，按同一平台实例下同路径项目回填（已被 webhook 记录的提交保持不变）
+UPDATE commits c
+SET project_id = p.project_id
+FROM (
+    SELECT platform, instance, project_path, MAX(project_id) AS project_id
+    FROM commits
+    WHERE project_id IS NOT NULL
+    GROUP BY platform, instance, project_path
+) p
+WHERE c.project_id IS NULL
+  AND c.platform = p.platform
+  AND c.instance = p.instance
+  AND c.project_path = p.project_path
+  AND NOT EXISTS (
+      SELECT 1 FROM commits d
+      WHERE d.commit_id = c.commit_id
+        AND d.project_id = p.project_id
+        AND d.platform = c.platform
+        AND d.instance = c.instance
+  );
+
+DROP INDEX IF EXISTS idx_commits_commit_project;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_commits_identity
+ON commits(commit_id, COALESCE(project_id, 0), platform, instance);
+CREATE INDEX IF NOT EXISTS idx_commits_platform ON commits(platform);
+
+COMMENT ON COLUMN commits.platform IS '平台：gitlab / github / gitee';
+COMMENT ON COLUMN commits.instance IS '平台实例主机名，如 gitlab.example.com';
+
+-- 2. 聚合表
+ALTER TABLE member_contributions ADD COLUMN IF NOT EXISTS platform VARCHAR(20) NOT NULL DEFAULT 'gitlab';
+ALTER TABLE member_contributions ADD COLUMN IF NOT EXISTS instance VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE member_language_stats ADD COLUMN IF NOT EXISTS platform VARCHAR(20) NOT NULL DEFAULT 'gitlab';
+ALTER TABLE member_language_stats ADD COLUMN IF NOT EXISTS instance VARCHAR(255) NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS idx_member_contributions_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_member_contributions_unique
+ON member_contributions(member_email, period_type, start_date, COALESCE(project_id, 0), platform, instance);
+
+DROP INDEX IF EXISTS idx_member_language_stats_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_member_language_stats_unique
+ON member_language_stats(member_email, language, period_type, period_start, COALESCE(project_id, 0), platform, instance);
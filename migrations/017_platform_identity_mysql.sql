@@ -0,0 +1,47 @@
+-- MySQL 数据库迁移文件：提交与聚合数据加入平台和实例标识
+-- 创建时间: 2026-10-19
+-- 不同平台（或同一平台的不同实例）的项目 ID 可能相同，唯一键改为 (commit_id, project_id, platform, instance)
+-- 迁移后需执行 go run ./cmd/rollup -all 重建聚合表
+
+-- 1. 提交表
+ALTER TABLE commits
+    ADD COLUMN platform VARCHAR(20) NOT NULL DEFAULT 'gitlab' COMMENT '平台：gitlab / github / gitee',
+    ADD COLUMN instance VARCHAR(255) NOT NULL DEFAULT '' COMMENT '平台实例主机名，如 gitlab.example.com';
+
+-- 按项目地址回填实例，github.com / gitee.com 上的项目回填对应平台
+UPDATE commits
+SET instance = LOWER(SUBSTRING_INDEX(SUBSTRING_INDEX(COALESCE(NULLIF(project_web_url, ''), url), '://', -1), '/', 1))
+WHERE instance = '' AND COALESCE(NULLIF(project_web_url, ''), url) LIKE '%://%';
+UPDATE commits SET platform = 'github' WHERE instance = 'github.com';
+UPDATE commits SET platform = 'gitee' WHERE instance = 'gitee.com';
+
+-- 旧版导入未填写 project_id，按同一平台实例下同路径项目回填（已被 webhook 记录的提交因唯一键冲突保持不变）
+UPDATE IGNORE commits c
+JOIN (
+    SELECT platform, instance, project_path, MAX(project_id) AS project_id
+    FROM commits
+    WHERE project_id IS NOT NULL
+    GROUP BY platform, instance, project_path
+) p ON c.platform = p.platform AND c.instance = p.instance AND c.project_path = p.project_path
+SET c.project_id = p.project_id
+WHERE c.project_id IS NULL;
+
+DROP INDEX idx_commits_commit_project ON commits;
+CREATE UNIQUE INDEX idx_commits_identity ON commits(commit_id, project_id, platform, instance);
+CREATE INDEX idx_commits_platform ON commits(platform);
+
+-- 2. 聚合表
+ALTER TABLE member_contributions
+    ADD COLUMN platform VARCHAR(20) NOT NULL DEFAULT 'gitlab',
+    ADD COLUMN instance VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE member_language_stats
+    ADD COLUMN platform VARCHAR(20) NOT NULL DEFAULT 'gitlab',
+    ADD COLUMN instance VARCHAR(255) NOT NULL DEFAULT '';
+
+DROP INDEX idx_member_contributions_unique ON member_contributions;
+CREATE UNIQUE INDEX idx_member_contributions_unique
+ON member_contributions(member_email, period_type, start_date, project_id, platform, instance);
+
+DROP INDEX idx_member_language_stats_unique ON member_language_stats;
+CREATE UNIQUE INDEX idx_member_language_stats_unique
+ON member_language_stats(member_email, language, period_type, period_start, project_id, platform, instance);
@@ -0,0 +1,111 @@
+-- 数据库迁移文件：创建项目表和项目路径历史表，提交通过 project_ref_id 关联项目
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 018_create_projects_mysql.sql
+-- 项目元数据从 commits 表迁出，按每个项目最新一条提交回填；项目重命名后历史提交仍关联同一项目
+
+-- 1. 项目表
+CREATE TABLE IF NOT EXISTS projects (
+    id BIGSERIAL PRIMARY KEY,
+    platform VARCHAR(20) NOT NULL DEFAULT 'gitlab',
+    instance VARCHAR(255) NOT NULL DEFAULT '',
+    external_id INTEGER NOT NULL,
+    path VARCHAR(500) NOT NULL,
+    name VARCHAR(255),
+    namespace VARCHAR(255),
+    description TEXT,
+    web_url TEXT,
+    visibility_level INTEGER,
+    default_branch VARCHAR(255),
+    git_ssh_url TEXT,
+    git_http_url TEXT,
+    repository_name VARCHAR(255),
+    repository_url TEXT,
+    repository_description TEXT,
+    repository_homepage TEXT,
+    repository_git_ssh_url TEXT,
+    repository_git_http_url TEXT,
+    repository_visibility_level INTEGER,
+    last_event_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_identity ON projects(platform, instance, external_id);
+CREATE INDEX IF NOT EXISTS idx_projects_path ON projects(path);
+CREATE INDEX IF NOT EXISTS idx_projects_namespace ON projects(namespace);
+
+COMMENT ON TABLE projects IS '项目表（每个平台实例上的项目一行）';
+COMMENT ON COLUMN projects.external_id IS '平台上的项目 ID';
+COMMENT ON COLUMN projects.path IS '当前项目路径';
+COMMENT ON COLUMN projects.last_event_at IS '最近一次推送或导入的时间';
+
+-- 2. 项目路径历史表
+CREATE TABLE IF NOT EXISTS project_paths (
+    id BIGSERIAL PRIMARY KEY,
+    project_ref_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    path VARCHAR(500) NOT NULL,
+    namespace VARCHAR(255),
+    first_seen_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_paths_project_path ON project_paths(project_ref_id, path);
+CREATE INDEX IF NOT EXISTS idx_project_paths_path ON project_paths(path);
+CREATE INDEX IF NOT EXISTS idx_project_paths_namespace ON project_paths(namespace);
+
+COMMENT ON TABLE project_paths IS '项目使用过的路径（含当前路径）';
+
+-- 3. 按每个项目最新的提交回填项目表
+INSERT INTO projects (
+    platform, instance, external_id, path, name, namespace, description, web_url,
+    visibility_level, default_branch, git_ssh_url, git_http_url,
+    repository_name, repository_url, repository_description, repository_homepage,
+    repository_git_ssh_url, repository_git_http_url, repository_visibility_level, last_event_at
+)
+SELECT DISTINCT ON (platform, instance, project_id)
+    platform, instance, project_id, project_path, project_name, project_namespace, project_description, project_web_url,
+    project_visibility_level, project_default_branch, project_git_ssh_url, project_git_http_url,
+    repository_name, repository_url, repository_description, repository_homepage,
+    repository_git_ssh_url, repository_git_http_url, repository_visibility_level, created_at
+FROM commits
+WHERE project_id IS NOT NULL
+ORDER BY platform, instance, project_id, id DESC
+ON CONFLICT (platform, instance, external_id) DO NOTHING;
+
+-- 4. 关联提交
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS project_ref_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_commits_project_ref_id ON commits(project_ref_id);
+COMMENT ON COLUMN commits.project_ref_id IS '所属项目（projects.id），项目重命名后不变';
+
+UPDATE commits c
+SET project_ref_id = p.id
+FROM projects p
+WHERE c.project_ref_id IS NULL
+  AND c.project_id = p.external_id
+  AND c.platform = p.platform
+  AND c.instance = p.instance;
+
+-- 5. 回填路径历史
+INSERT INTO project_paths (project_ref_id, path, namespace, first_seen_at, last_seen_at)
+SELECT project_ref_id, project_path, MAX(project_namespace), MIN(created_at), MAX(created_at)
+FROM commits
+WHERE project_ref_id IS NOT NULL
+GROUP BY project_ref_id, project_path
+ON CONFLICT (project_ref_id, path) DO NOTHING;
+
+-- 6. 删除已迁到项目表的冗余字段（提交保留 project_name / project_path / project_namespace 作为提交时的快照）
+DROP INDEX IF EXISTS idx_commits_project_visibility;
+ALTER TABLE commits
+DROP COLUMN IF EXISTS project_description,
+DROP COLUMN IF EXISTS project_web_url,
+DROP COLUMN IF EXISTS project_visibility_level,
+DROP COLUMN IF EXISTS project_default_branch,
+DROP COLUMN IF EXISTS project_git_ssh_url,
+DROP COLUMN IF EXISTS project_git_http_url,
+DROP COLUMN IF EXISTS repository_name,
+DROP COLUMN IF EXISTS repository_url,
+DROP COLUMN IF EXISTS repository_description,
+DROP COLUMN IF EXISTS repository_homepage,
+DROP COLUMN IF EXISTS repository_git_ssh_url,
+DROP COLUMN IF EXISTS repository_git_http_url,
+DROP COLUMN IF EXISTS repository_visibility_level;
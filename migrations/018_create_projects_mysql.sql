@@ -0,0 +1,100 @@
+-- MySQL 数据库迁移文件：创建项目表和项目路径历史表，提交通过 project_ref_id 关联项目
+-- 创建时间: 2026-10-19
+-- 项目元数据从 commits 表迁出，按每个项目最新一条提交回填；项目重命名后历史提交仍关联同一项目
+
+-- 1. 项目表
+CREATE TABLE IF NOT EXISTS projects (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    platform VARCHAR(20) NOT NULL DEFAULT 'gitlab',
+    instance VARCHAR(255) NOT NULL DEFAULT '',
+    external_id INT NOT NULL COMMENT '平台上的项目 ID',
+    path VARCHAR(500) NOT NULL COMMENT '当前项目路径',
+    name VARCHAR(255),
+    namespace VARCHAR(255),
+    description TEXT,
+    web_url TEXT,
+    visibility_level INT,
+    default_branch VARCHAR(255),
+    git_ssh_url TEXT,
+    git_http_url TEXT,
+    repository_name VARCHAR(255),
+    repository_url TEXT,
+    repository_description TEXT,
+    repository_homepage TEXT,
+    repository_git_ssh_url TEXT,
+    repository_git_http_url TEXT,
+    repository_visibility_level INT,
+    last_event_at TIMESTAMP NOT NULL COMMENT '最近一次推送或导入的时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_projects_identity (platform, instance, external_id),
+    INDEX idx_projects_path (path),
+    INDEX idx_projects_namespace (namespace)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='项目表（每个平台实例上的项目一行）';
+
+-- 2. 项目路径历史表
+CREATE TABLE IF NOT EXISTS project_paths (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    project_ref_id BIGINT UNSIGNED NOT NULL,
+    path VARCHAR(500) NOT NULL,
+    namespace VARCHAR(255),
+    first_seen_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    UNIQUE INDEX idx_project_paths_project_path (project_ref_id, path),
+    INDEX idx_project_paths_path (path),
+    INDEX idx_project_paths_namespace (namespace),
+    CONSTRAINT fk_project_paths_project FOREIGN KEY (project_ref_id) REFERENCES projects(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='项目使用过的路径（含当前路径）';
+
+-- 3. 按每个项目最新的提交回填项目表
+INSERT IGNORE INTO projects (
+    platform, instance, external_id, path, name, namespace, description, web_url,
+    visibility_level, default_branch, git_ssh_url, git_http_url,
+    repository_name, repository_url, repository_description, repository_homepage,
+    repository_git_ssh_url, repository_git_http_url, repository_visibility_level, last_event_at
+)
+SELECT
+    c.platform, c.instance, c.project_id, c.project_path, c.project_name, c.project_namespace, c.project_description, c.project_web_url,
+    c.project_visibility_level, c.project_default_branch, c.project_git_ssh_url, c.project_git_http_url,
+    c.repository_name, c.repository_url, c.repository_description, c.repository_homepage,
+    c.repository_git_ssh_url, c.repository_git_http_url, c.repository_visibility_level, c.created_at
+FROM commits c
+JOIN (
+    SELECT MAX(id) AS id
+    FROM commits
+    WHERE project_id IS NOT NULL
+    GROUP BY platform, instance, project_id
+) latest ON latest.id = c.id;
+
+-- 4. 关联提交
+ALTER TABLE commits
+    ADD COLUMN project_ref_id BIGINT UNSIGNED COMMENT '所属项目（projects.id），项目重命名后不变',
+    ADD INDEX idx_commits_project_ref_id (project_ref_id);
+
+UPDATE commits c
+JOIN projects p ON c.project_id = p.external_id AND c.platform = p.platform AND c.instance = p.instance
+SET c.project_ref_id = p.id
+WHERE c.project_ref_id IS NULL;
+
+-- 5. 回填路径历史
+INSERT IGNORE INTO project_paths (project_ref_id, path, namespace, first_seen_at, last_seen_at)
+SELECT project_ref_id, project_path, MAX(project_namespace), MIN(created_at), MAX(created_at)
+FROM commits
+WHERE project_ref_id IS NOT NULL
+GROUP BY project_ref_id, project_path;
+
+-- 6. 删除已迁到项目表的冗余字段（提交保留 project_name / project_path / project_namespace 作为提交时的快照）
+ALTER TABLE commits
+    DROP COLUMN project_description,
+    DROP COLUMN project_web_url,
+    DROP COLUMN project_visibility_level,
+    DROP COLUMN project_default_branch,
+    DROP COLUMN project_git_ssh_url,
+    DROP COLUMN project_git_http_url,
+    DROP COLUMN repository_name,
+    DROP COLUMN repository_url,
+    DROP COLUMN repository_description,
+    DROP COLUMN repository_homepage,
+    DROP COLUMN repository_git_ssh_url,
+    DROP COLUMN repository_git_http_url,
+    DROP COLUMN repository_visibility_level;
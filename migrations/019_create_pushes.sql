@@ -0,0 +1,91 @@
+-- 数据库迁移文件：创建推送事件表和推送-提交关联表，推送信息不再冗余存储在每条提交上
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 019_create_pushes_mysql.sql
+-- 历史推送按提交上的 (项目, 分支, before_sha, after_sha) 回填；迁移前没有提交的推送无法恢复
+
+-- 1. 推送事件表
+CREATE TABLE IF NOT EXISTS pushes (
+    id BIGSERIAL PRIMARY KEY,
+    platform VARCHAR(20) NOT NULL DEFAULT 'gitlab',
+    instance VARCHAR(255) NOT NULL DEFAULT '',
+    project_id INTEGER,
+    project_path VARCHAR(500) NOT NULL,
+    ref VARCHAR(255) NOT NULL,
+    ref_protected BOOLEAN DEFAULT FALSE,
+    before_sha VARCHAR(64) NOT NULL DEFAULT '',
+    after_sha VARCHAR(64) NOT NULL DEFAULT '',
+    checkout_sha VARCHAR(64),
+    message TEXT,
+    total_commits_count INTEGER DEFAULT 0,
+    pusher_id INTEGER,
+    pusher_name VARCHAR(255),
+    pusher_username VARCHAR(255),
+    pusher_email VARCHAR(255),
+    pushed_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pushes_identity ON pushes(platform, instance, project_id, ref, before_sha, after_sha);
+CREATE INDEX IF NOT EXISTS idx_pushes_project_path ON pushes(project_path);
+CREATE INDEX IF NOT EXISTS idx_pushes_ref_protected ON pushes(ref_protected);
+CREATE INDEX IF NOT EXISTS idx_pushes_pusher_id ON pushes(pusher_id);
+CREATE INDEX IF NOT EXISTS idx_pushes_pusher_username ON pushes(pusher_username);
+CREATE INDEX IF NOT EXISTS idx_pushes_pusher_email ON pushes(pusher_email);
+CREATE INDEX IF NOT EXISTS idx_pushes_pushed_at ON pushes(pushed_at);
+
+COMMENT ON TABLE pushes IS '推送事件表（每次推送一行）';
+COMMENT ON COLUMN pushes.ref IS '分支名，标签推送时为完整引用';
+COMMENT ON COLUMN pushes.total_commits_count IS '平台报告的本次推送提交总数';
+COMMENT ON COLUMN pushes.pusher_email IS '推送者邮箱（可能与提交作者不同）';
+
+-- 2. 推送-提交关联表
+CREATE TABLE IF NOT EXISTS push_commits (
+    id BIGSERIAL PRIMARY KEY,
+    push_id BIGINT NOT NULL,
+    commit_id BIGINT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_push_commits_push_commit ON push_commits(push_id, commit_id);
+CREATE INDEX IF NOT EXISTS idx_push_commits_commit_id ON push_commits(commit_id);
+
+COMMENT ON TABLE push_commits IS '推送与提交的关联（同一提交可能出现在多次推送中）';
+
+-- 3. 按提交上的推送信息回填
+INSERT INTO pushes (
+    platform, instance, project_id, project_path, ref, ref_protected, before_sha, after_sha, checkout_sha,
+    message, total_commits_count, pusher_id, pusher_name, pusher_username, pusher_email, pushed_at
+)
+SELECT
+    platform, instance, project_id, MAX(project_path), COALESCE(branch, ''), BOOL_OR(COALESCE(ref_protected, FALSE)),
+    before_sha, after_sha, MAX(checkout_sha),
+    MAX(push_message), MAX(total_commits_count), MAX(push_user_id), MAX(push_user_name), MAX(push_user_username), MAX(push_user_email),
+    MIN(created_at)
+FROM commits
+WHERE COALESCE(after_sha, '') <> ''
+GROUP BY platform, instance, project_id, COALESCE(branch, ''), before_sha, after_sha
+ON CONFLICT DO NOTHING;
+
+INSERT INTO push_commits (push_id, commit_id)
+SELECT p.id, c.id
+FROM commits c
+JOIN pushes p ON p.platform = c.platform
+    AND p.instance = c.instance
+    AND p.project_id IS NOT DISTINCT FROM c.project_id
+    AND p.ref = COALESCE(c.branch, '')
+    AND p.before_sha = c.before_sha
+    AND p.after_sha = c.after_sha
+ON CONFLICT DO NOTHING;
+
+-- 4. 删除提交表上的推送字段（ref_protected 保留为提交首次推送时的分支保护状态）
+DROP INDEX IF EXISTS idx_commits_push_user_id;
+DROP INDEX IF EXISTS idx_commits_push_user_username;
+ALTER TABLE commits
+DROP COLUMN IF EXISTS push_user_id,
+DROP COLUMN IF EXISTS push_user_name,
+DROP COLUMN IF EXISTS push_user_username,
+DROP COLUMN IF EXISTS push_user_email,
+DROP COLUMN IF EXISTS before_sha,
+DROP COLUMN IF EXISTS after_sha,
+DROP COLUMN IF EXISTS checkout_sha,
+DROP COLUMN IF EXISTS push_message,
+DROP COLUMN IF EXISTS total_commits_count;
@@ -0,0 +1,79 @@
+-- MySQL 数据库迁移文件：创建推送事件表和推送-提交关联表，推送信息不再冗余存储在每条提交上
+-- 创建时间: 2026-10-19
+-- 历史推送按提交上的 (项目, 分支, before_sha, after_sha) 回填；迁移前没有提交的推送无法恢复
+
+-- 1. 推送事件表
+CREATE TABLE IF NOT EXISTS pushes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    platform VARCHAR(20) NOT NULL DEFAULT 'gitlab',
+    instance VARCHAR(255) NOT NULL DEFAULT '',
+    project_id INT,
+    project_path VARCHAR(500) NOT NULL,
+    ref VARCHAR(255) NOT NULL COMMENT '分支名，标签推送时为完整引用',
+    ref_protected BOOLEAN DEFAULT FALSE,
+    before_sha VARCHAR(64) NOT NULL DEFAULT '',
+    after_sha VARCHAR(64) NOT NULL DEFAULT '',
+    checkout_sha VARCHAR(64),
+    message TEXT,
+    total_commits_count INT DEFAULT 0 COMMENT '平台报告的本次推送提交总数',
+    pusher_id INT,
+    pusher_name VARCHAR(255),
+    pusher_username VARCHAR(255),
+    pusher_email VARCHAR(255) COMMENT '推送者邮箱（可能与提交作者不同）',
+    pushed_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_pushes_identity (platform, instance, project_id, ref, before_sha, after_sha),
+    INDEX idx_pushes_project_path (project_path),
+    INDEX idx_pushes_ref_protected (ref_protected),
+    INDEX idx_pushes_pusher_id (pusher_id),
+    INDEX idx_pushes_pusher_username (pusher_username),
+    INDEX idx_pushes_pusher_email (pusher_email),
+    INDEX idx_pushes_pushed_at (pushed_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='推送事件表（每次推送一行）';
+
+-- 2. 推送-提交关联表
+CREATE TABLE IF NOT EXISTS push_commits (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    push_id BIGINT UNSIGNED NOT NULL,
+    commit_id BIGINT UNSIGNED NOT NULL,
+    UNIQUE INDEX idx_push_commits_push_commit (push_id, commit_id),
+    INDEX idx_push_commits_commit_id (commit_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='推送与提交的关联（同一提交可能出现在多次推送中）';
+
+-- 3. 按提交上的推送信息回填
+INSERT IGNORE INTO pushes (
+    platform, instance, project_id, project_path, ref, ref_protected, before_sha, after_sha, checkout_sha,
+    message, total_commits_count, pusher_id, pusher_name, pusher_username, pusher_email, pushed_at
+)
+SELECT
+    platform, instance, project_id, MAX(project_path), COALESCE(branch, ''), MAX(COALESCE(ref_protected, FALSE)),
+    before_sha, after_sha, MAX(checkout_sha),
+    MAX(push_message), MAX(total_commits_count), MAX(push_user_id), MAX(push_user_name), MAX(push_user_username), MAX(push_user_email),
+    MIN(created_at)
+FROM commits
+WHERE COALESCE(after_sha, '') <> ''
+GROUP BY platform, instance, project_id, COALESCE(branch, ''), before_sha, after_sha;
+
+INSERT IGNORE INTO push_commits (push_id, commit_id)
+SELECT p.id, c.id
+FROM commits c
+JOIN pushes p ON p.platform = c.platform
+    AND p.instance = c.instance
+    AND p.project_id <=> c.project_id
+    AND p.ref = COALESCE(c.branch, '')
+    AND p.before_sha = c.before_sha
+    AND p.after_sha = c.after_sha;
+
+-- 4. 删除提交表上的推送字段（ref_protected 保留为提交首次推送时的分支保护状态）
+ALTER TABLE commits
+    DROP INDEX idx_commits_push_user_id,
+    DROP INDEX idx_commits_push_user_username,
+    DROP COLUMN push_user_id,
+    DROP COLUMN push_user_name,
+    DROP COLUMN push_user_username,
+    DROP COLUMN push_user_email,
+    DROP COLUMN before_sha,
+    DROP COLUMN after_sha,
+    DROP COLUMN checkout_sha,
+    DROP COLUMN push_message,
+    DROP COLUMN total_commits_count;
@@ -0,0 +1,29 @@
+-- 数据库迁移文件：创建提交作者表，记录提交作者及 Co-authored-by 声明的共同作者
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 020_commit_authors_mysql.sql
+-- 历史提交只回填提交作者本人；迁移后执行 go run ./cmd/reclassify -messages 从提交说明中解析共同作者，
+-- 再执行 go run ./cmd/rollup -all 重建汇总表
+
+-- 1. 提交作者表
+CREATE TABLE IF NOT EXISTS commit_authors (
+    id BIGSERIAL PRIMARY KEY,
+    commit_id BIGINT NOT NULL REFERENCES commits(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    is_primary BOOLEAN DEFAULT FALSE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_commit_authors_commit_email ON commit_authors (commit_id, email);
+CREATE INDEX IF NOT EXISTS idx_commit_authors_email ON commit_authors (email);
+
+COMMENT ON TABLE commit_authors IS '提交作者（提交作者本人及共同作者，每人一行）';
+COMMENT ON COLUMN commit_authors.is_primary IS '是否为提交作者（split 模式下余数行计入提交作者）';
+
+-- 2. 提交上的作者人数
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS author_count INTEGER NOT NULL DEFAULT 1;
+COMMENT ON COLUMN commits.author_count IS '作者人数（含共同作者），split 模式下用于均分行数';
+
+-- 3. 回填提交作者本人
+INSERT INTO commit_authors (commit_id, email, name, is_primary)
+SELECT id, author_email, author, TRUE FROM commits
+ON CONFLICT (commit_id, email) DO NOTHING;
@@ -0,0 +1,23 @@
+-- MySQL 数据库迁移文件：创建提交作者表，记录提交作者及 Co-authored-by 声明的共同作者
+-- 创建时间: 2026-10-19
+-- 历史提交只回填提交作者本人；迁移后执行 go run ./cmd/reclassify -messages 从提交说明中解析共同作者，
+-- 再执行 go run ./cmd/rollup -all 重建汇总表
+
+-- 1. 提交作者表
+CREATE TABLE IF NOT EXISTS commit_authors (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    commit_id BIGINT UNSIGNED NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    is_primary BOOLEAN DEFAULT FALSE COMMENT '是否为提交作者（split 模式下余数行计入提交作者）',
+    UNIQUE INDEX idx_commit_authors_commit_email (commit_id, email),
+    INDEX idx_commit_authors_email (email),
+    CONSTRAINT fk_commit_authors_commit FOREIGN KEY (commit_id) REFERENCES commits(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='提交作者（提交作者本人及共同作者，每人一行）';
+
+-- 2. 提交上的作者人数
+ALTER TABLE commits ADD COLUMN author_count INT NOT NULL DEFAULT 1 COMMENT '作者人数（含共同作者），split 模式下用于均分行数';
+
+-- 3. 回填提交作者本人
+INSERT IGNORE INTO commit_authors (commit_id, email, name, is_primary)
+SELECT id, author_email, author, TRUE FROM commits;
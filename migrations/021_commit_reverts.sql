@@ -0,0 +1,19 @@
+-- 数据库迁移文件：回滚提交识别与关联（Revert "..." / This reverts commit <sha>）
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 021_commit_reverts_mysql.sql
+-- 历史数据需执行 go run ./cmd/reclassify -messages -reverts 解析回滚声明并关联回滚提交和被回滚提交
+
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS is_revert BOOLEAN DEFAULT FALSE;
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS reverts_sha VARCHAR(64);
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS revert_of BIGINT;
+ALTER TABLE commits ADD COLUMN IF NOT EXISTS reverted_by BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_commits_is_revert ON commits(is_revert);
+CREATE INDEX IF NOT EXISTS idx_commits_reverts_sha ON commits(reverts_sha);
+CREATE INDEX IF NOT EXISTS idx_commits_revert_of ON commits(revert_of);
+CREATE INDEX IF NOT EXISTS idx_commits_reverted_by ON commits(reverted_by);
+
+COMMENT ON COLUMN commits.is_revert IS '是否为回滚提交（标题为 Revert "..." 或说明包含 This reverts commit）';
+COMMENT ON COLUMN commits.reverts_sha IS '提交说明中声明的被回滚提交 SHA';
+COMMENT ON COLUMN commits.revert_of IS '同一项目内被本提交回滚的提交 ID';
+COMMENT ON COLUMN commits.reverted_by IS '同一项目内回滚了本提交的提交 ID（多次回滚时为第一次）';
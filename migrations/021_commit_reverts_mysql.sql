@@ -0,0 +1,14 @@
+-- MySQL 数据库迁移文件：回滚提交识别与关联（Revert "..." / This reverts commit <sha>）
+-- 创建时间: 2026-10-19
+-- 历史数据需执行 go run ./cmd/reclassify -messages -reverts 解析回滚声明并关联回滚提交和被回滚提交
+
+ALTER TABLE commits
+    ADD COLUMN is_revert BOOLEAN DEFAULT FALSE COMMENT '是否为回滚提交（标题为 Revert "..." 或说明包含 This reverts commit）',
+    ADD COLUMN reverts_sha VARCHAR(64) NULL COMMENT '提交说明中声明的被回滚提交 SHA',
+    ADD COLUMN revert_of BIGINT NULL COMMENT '同一项目内被本提交回滚的提交 ID',
+    ADD COLUMN reverted_by BIGINT NULL COMMENT '同一项目内回滚了本提交的提交 ID（多次回滚时为第一次）';
+
+CREATE INDEX idx_commits_is_revert ON commits(is_revert);
+CREATE INDEX idx_commits_reverts_sha ON commits(reverts_sha);
+CREATE INDEX idx_commits_revert_of ON commits(revert_of);
+CREATE INDEX idx_commits_reverted_by ON commits(reverted_by);
@@ -0,0 +1,6 @@
+-- 数据库迁移文件：提交说明全文索引（/api/commits?q= 全文检索）
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 022_commit_message_fulltext_mysql.sql
+-- 使用 simple 配置（不做词干化，按空白和标点分词），查询条件 to_tsvector('simple', message) 需与索引表达式一致
+
+CREATE INDEX IF NOT EXISTS idx_commits_message_fts ON commits USING GIN (to_tsvector('simple', message));
@@ -0,0 +1,6 @@
+-- MySQL 数据库迁移文件：提交说明全文索引（/api/commits?q= 全文检索）
+-- 创建时间: 2026-10-19
+-- 使用 ngram 解析器以支持中文（默认 ngram_token_size=2），需要 MySQL 5.7.6 及以上版本
+-- 未创建该索引时 MATCH ... AGAINST 查询会报错；服务启动时 database.Migrate 也会在索引缺失时创建
+
+ALTER TABLE commits ADD FULLTEXT INDEX idx_commits_message_fulltext (message) WITH PARSER ngram;
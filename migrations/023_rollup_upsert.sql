@@ -0,0 +1,37 @@
+-- 数据库迁移文件：聚合表并发安全的增量写入与就绪状态
+-- 创建时间: 2026-10-19
+-- 注意: 这是 PostgreSQL 版本，MySQL 版本请使用 023_rollup_upsert_mysql.sql
+-- 增量写入改为 INSERT ... ON CONFLICT DO UPDATE，唯一索引需按列建立（不能是 COALESCE 表达式），
+-- 没有项目信息的聚合行改记为 project_id = 0
+-- 聚合表在完整重建前处于未就绪状态（统计查询扫描明细表），迁移完成后执行 go run ./cmd/rollup -all
+
+-- 1. 删除 project_id 为空的聚合行（重建时以 project_id = 0 写回）
+DELETE FROM member_contributions WHERE project_id IS NULL;
+DELETE FROM member_language_stats WHERE project_id IS NULL;
+
+ALTER TABLE member_contributions ALTER COLUMN project_id SET DEFAULT 0;
+ALTER TABLE member_contributions ALTER COLUMN project_id SET NOT NULL;
+ALTER TABLE member_language_stats ALTER COLUMN project_id SET DEFAULT 0;
+ALTER TABLE member_language_stats ALTER COLUMN project_id SET NOT NULL;
+
+-- 2. 唯一索引按列重建
+DROP INDEX IF EXISTS idx_member_contributions_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_member_contributions_unique
+ON member_contributions(member_email, period_type, start_date, project_id, platform, instance);
+
+DROP INDEX IF EXISTS idx_member_language_stats_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_member_language_stats_unique
+ON member_language_stats(member_email, language, period_type, period_start, project_id, platform, instance);
+
+-- 3. 聚合表状态
+CREATE TABLE IF NOT EXISTS rollup_state (
+    id SERIAL PRIMARY KEY,
+    ready BOOLEAN NOT NULL DEFAULT FALSE,
+    reason VARCHAR(255),
+    built_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+COMMENT ON TABLE rollup_state IS '聚合表状态（单行）：完整重建后就绪，批量修改明细后未就绪，未就绪时统计查询扫描明细表';
+COMMENT ON COLUMN rollup_state.reason IS '最近一次标记为未就绪的原因';
+COMMENT ON COLUMN rollup_state.built_at IS '最近一次完整重建的时间';
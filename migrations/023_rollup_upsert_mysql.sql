@@ -0,0 +1,20 @@
+-- MySQL 数据库迁移文件：聚合表并发安全的增量写入与就绪状态
+-- 创建时间: 2026-10-19
+-- 增量写入改为 INSERT ... ON DUPLICATE KEY UPDATE，NULL 不受唯一索引约束，没有项目信息的聚合行改记为 project_id = 0
+-- 聚合表在完整重建前处于未就绪状态（统计查询扫描明细表），迁移完成后执行 go run ./cmd/rollup -all
+
+-- 1. 删除 project_id 为空的聚合行（可能存在重复，重建时以 project_id = 0 写回）
+DELETE FROM member_contributions WHERE project_id IS NULL;
+DELETE FROM member_language_stats WHERE project_id IS NULL;
+
+ALTER TABLE member_contributions MODIFY COLUMN project_id INT NOT NULL DEFAULT 0;
+ALTER TABLE member_language_stats MODIFY COLUMN project_id INT NOT NULL DEFAULT 0;
+
+-- 2. 聚合表状态
+CREATE TABLE IF NOT EXISTS rollup_state (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    ready BOOLEAN NOT NULL DEFAULT FALSE,
+    reason VARCHAR(255) NULL COMMENT '最近一次标记为未就绪的原因',
+    built_at DATETIME NULL COMMENT '最近一次完整重建的时间',
+    updated_at DATETIME NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='聚合表状态（单行）：完整重建后就绪，批量修改明细后未就绪，未就绪时统计查询扫描明细表';
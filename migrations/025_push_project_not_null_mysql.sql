@@ -0,0 +1,44 @@
+-- MySQL 数据库迁移文件：推送记录的项目 ID 改为 NOT NULL DEFAULT 0，使推送唯一索引对项目未知的推送生效
+-- 创建时间: 2026-10-19
+-- project_id IS NULL 的推送不受 idx_pushes_identity 约束，webhook 重投可能已重复记录，合并到最早的一条后改记为 0
+
+-- 1. 重复推送的提交关联合并到最早的一条
+INSERT IGNORE INTO push_commits (push_id, commit_id)
+SELECT keep.id, pc.commit_id
+FROM push_commits pc
+JOIN pushes p ON p.id = pc.push_id
+JOIN pushes keep ON keep.platform = p.platform
+    AND keep.instance = p.instance
+    AND keep.ref = p.ref
+    AND keep.before_sha = p.before_sha
+    AND keep.after_sha = p.after_sha
+    AND COALESCE(keep.project_id, 0) = 0
+    AND keep.id < p.id
+WHERE COALESCE(p.project_id, 0) = 0;
+
+-- 2. 删除重复推送（保留 id 最小的一条）
+DELETE pc FROM push_commits pc
+JOIN pushes p ON p.id = pc.push_id
+JOIN pushes keep ON keep.platform = p.platform
+    AND keep.instance = p.instance
+    AND keep.ref = p.ref
+    AND keep.before_sha = p.before_sha
+    AND keep.after_sha = p.after_sha
+    AND COALESCE(keep.project_id, 0) = 0
+    AND keep.id < p.id
+WHERE COALESCE(p.project_id, 0) = 0;
+
+DELETE p FROM pushes p
+JOIN pushes keep ON keep.platform = p.platform
+    AND keep.instance = p.instance
+    AND keep.ref = p.ref
+    AND keep.before_sha = p.before_sha
+    AND keep.after_sha = p.after_sha
+    AND COALESCE(keep.project_id, 0) = 0
+    AND keep.id < p.id
+WHERE COALESCE(p.project_id, 0) = 0;
+
+-- 3. 项目未知时记为 0
+UPDATE pushes SET project_id = 0 WHERE project_id IS NULL;
+
+ALTER TABLE pushes MODIFY COLUMN project_id INT NOT NULL DEFAULT 0 COMMENT '项目 ID，未知时为 0';